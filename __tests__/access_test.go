@@ -0,0 +1,89 @@
+package gittag
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/afeiship/gittag"
+	"github.com/afeiship/gittag/gittagtest"
+)
+
+func TestReadOnly(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0", "v1.1.0"), gittagtest.WithRemote())
+	repo, err := gittag.Open(fixture.Dir, gittag.WithReadOnly())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tags, err := repo.FindMany("v*"); err != nil || len(tags) != 2 {
+		t.Errorf("FindMany = %v, %v", tags, err)
+	}
+	if _, err := repo.ListInfo("v*"); err != nil {
+		t.Errorf("ListInfo = %v", err)
+	}
+	if _, err := repo.Resolve("v1.0.0"); err != nil {
+		t.Errorf("Resolve = %v", err)
+	}
+
+	ctx := context.Background()
+	for name, err := range map[string]error{
+		"CreateTag":   repo.CreateTag("v2.0.0"),
+		"DeleteLocal": repo.DeleteLocal("v1.0.0"),
+		"FetchTags":   repo.FetchTags(),
+		"Freeze":      repo.Freeze("v1.*"),
+		"Git tag -d":  func() error { _, _, err := repo.Git(ctx, "tag", "-d", "v1.0.0"); return err }(),
+		"Git update-ref": func() error {
+			_, _, err := repo.Git(ctx, "update-ref", "-d", "refs/tags/v1.0.0")
+			return err
+		}(),
+	} {
+		if !errors.Is(err, gittag.ErrReadOnly) {
+			t.Errorf("%s = %v, want ErrReadOnly", name, err)
+		}
+	}
+	if got := fixture.Tags(); len(got) != 2 {
+		t.Errorf("tags changed in read-only mode: %v", got)
+	}
+	if _, _, err := repo.Git(ctx, "tag", "-l", "v*"); err != nil {
+		t.Errorf("Git tag -l = %v", err)
+	}
+}
+
+func TestRestrict(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0"), gittagtest.WithRemote())
+	repo := fixture.Open()
+	plugin := repo.Restrict(gittag.CapRead, gittag.CapCreate)
+
+	if err := plugin.CreateTag("v1.1.0"); err != nil {
+		t.Fatalf("CreateTag = %v", err)
+	}
+	if _, err := plugin.FindOne("v1.*"); err != nil {
+		t.Errorf("FindOne = %v", err)
+	}
+	ctx := context.Background()
+	for name, err := range map[string]error{
+		"DeleteLocal":  plugin.DeleteLocal("v1.0.0"),
+		"DeleteRemote": plugin.DeleteRemote("v1.0.0"),
+		"Freeze":       plugin.Freeze("v1.*"),
+		"Git update-ref": func() error {
+			_, _, err := plugin.Git(ctx, "update-ref", "-d", "refs/tags/v1.0.0")
+			return err
+		}(),
+	} {
+		if !errors.Is(err, gittag.ErrNotPermitted) || gittag.Kind(err) != gittag.KindNotPermitted {
+			t.Errorf("%s = %v, want ErrNotPermitted", name, err)
+		}
+	}
+
+	narrowed := plugin.Restrict(gittag.CapRead, gittag.CapDelete)
+	if narrowed.Capabilities() != gittag.CapRead {
+		t.Errorf("narrowed capabilities = %s, want read", narrowed.Capabilities())
+	}
+	if repo.Capabilities() != gittag.CapAll {
+		t.Errorf("original handle was restricted: %s", repo.Capabilities())
+	}
+	if err := repo.DeleteLocal("v1.0.0"); err != nil {
+		t.Errorf("unrestricted DeleteLocal = %v", err)
+	}
+}
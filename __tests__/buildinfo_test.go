@@ -0,0 +1,90 @@
+package gittag
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/afeiship/gittag"
+	"github.com/afeiship/gittag/buildinfo"
+	"github.com/afeiship/gittag/gittagtest"
+)
+
+func TestBuildInfo(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags("v1.2.0"))
+	version := filepath.Join(fixture.Dir, "VERSION")
+	if err := os.WriteFile(version, []byte("1.2.0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fixture.Git("add", "VERSION")
+	fixture.Commit("add VERSION")
+	repo := fixture.Open()
+	ctx := context.Background()
+
+	info, err := buildinfo.Read(ctx, repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	head := fixture.Git("rev-parse", "HEAD")
+	if !strings.HasPrefix(info.Version, "v1.2.0-1-g") || info.Commit != head || info.Dirty || info.Date.IsZero() {
+		t.Errorf("Read = %+v", info)
+	}
+	want := "-X main.Version=" + info.Version + " -X main.Commit=" + head + " -X main.Date=" +
+		info.Date.UTC().Format("2006-01-02T15:04:05Z07:00") + " -X main.Dirty=false"
+	if got := info.LDFlags("main"); got != want {
+		t.Errorf("LDFlags = %q, want %q", got, want)
+	}
+
+	path := filepath.Join(t.TempDir(), "version_gen.go")
+	if err := info.WriteGoFile(path, "version"); err != nil {
+		t.Fatal(err)
+	}
+	generated, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gittagtest.Golden(t, "testdata/buildinfo_gen.golden", string(generated))
+	if err := info.WriteGoFile(path, "not a package"); err == nil {
+		t.Error("WriteGoFile accepted an invalid package name")
+	}
+
+	if err := os.WriteFile(version, []byte("1.3.0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if info, err = buildinfo.Read(ctx, repo); err != nil || !info.Dirty || !strings.HasSuffix(info.Version, "-dirty") {
+		t.Errorf("Read with local changes = %+v, %v", info, err)
+	}
+}
+
+func TestBuildInfoWithoutTags(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithCommits(1))
+	repo := fixture.Open()
+	ctx := context.Background()
+
+	if _, err := repo.Describe("HEAD"); !errors.Is(err, gittag.ErrTagNotFound) {
+		t.Errorf("Describe without tags = %v, want ErrTagNotFound", err)
+	}
+	head := fixture.Git("rev-parse", "HEAD")
+	if info, err := buildinfo.Read(ctx, repo); err != nil || info.Version != "v0.0.0-"+head[:12] {
+		t.Errorf("Read without tags = %+v, %v", info, err)
+	}
+
+	// 除了没有可达的标签，describe 的其他失败不能被当作 v0.0.0
+	real, err := exec.LookPath("git")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bin := t.TempDir()
+	shim := "#!/bin/sh\ncase \" $* \" in *\" describe \"*) echo 'fatal: bad object HEAD' >&2; exit 128;; esac\nexec " + real + " \"$@\"\n"
+	if err := os.WriteFile(filepath.Join(bin, "git"), []byte(shim), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	if info, err := buildinfo.Read(ctx, fixture.Open()); err == nil {
+		t.Errorf("Read with a failing describe = %+v, nil; want error", info)
+	}
+}
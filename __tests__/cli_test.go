@@ -0,0 +1,89 @@
+package gittag
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/afeiship/gittag/gittagtest"
+)
+
+func TestCLI(t *testing.T) {
+	bin := filepath.Join(t.TempDir(), "gittag")
+	if output, err := exec.Command("go", "build", "-o", bin, "../cmd/gittag").CombinedOutput(); err != nil {
+		t.Fatalf("go build: %v\n%s", err, output)
+	}
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0", "v1.1.0"), gittagtest.WithRemote())
+	gittag := func(args ...string) (string, string, int) {
+		var stdout, stderr bytes.Buffer
+		cmd := exec.Command(bin, append([]string{"-C", fixture.Dir}, args...)...)
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		err := cmd.Run()
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return stdout.String(), stderr.String(), exitErr.ExitCode()
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		return stdout.String(), stderr.String(), 0
+	}
+
+	if _, stderr, code := gittag("create", "-m", "Release 1.2.0", "-push", "v1.2.0"); code != 0 {
+		t.Fatalf("create: exit %d\n%s", code, stderr)
+	}
+	if got := fixture.RemoteTags(); !reflect.DeepEqual(got, []string{"v1.0.0", "v1.1.0", "v1.2.0"}) {
+		t.Errorf("remote tags after create -push = %v", got)
+	}
+
+	stdout, _, _ := gittag("list", "-pattern", "v1.*", "-sort", "version-desc", "-json")
+	var tags []string
+	if err := json.Unmarshal([]byte(stdout), &tags); err != nil || !reflect.DeepEqual(tags, []string{"v1.2.0", "v1.1.0", "v1.0.0"}) {
+		t.Errorf("list -json = %q (%v)", stdout, err)
+	}
+	if stdout, _, code := gittag("list", "-pattern", "v9.*"); stdout != "" || code != 0 {
+		t.Errorf("list without matches = %q, exit %d", stdout, code)
+	}
+
+	_, stderr, code := gittag("create", "v1.0.0")
+	if code != 1 || !strings.Contains(stderr, "already exists") || strings.Count(stderr, "提示:") != 1 {
+		t.Errorf("create of an existing tag: exit %d\n%s", code, stderr)
+	}
+	if _, _, code := gittag("create"); code != 2 {
+		t.Errorf("create without a tag: exit %d, want 2", code)
+	}
+	if _, _, code := gittag("frobnicate"); code != 2 {
+		t.Errorf("unknown command: exit %d, want 2", code)
+	}
+
+	// 本地和远程按同样的规则匹配，* 可以匹配 "/"
+	fixture.Git("tag", "ci/build/1")
+	fixture.Git("push", "--quiet", "origin", "ci/build/1")
+	if _, stderr, code := gittag("delete", "-pattern", "ci*", "-remote"); code != 0 {
+		t.Fatalf("delete ci*: exit %d\n%s", code, stderr)
+	}
+	if local, remote := fixture.Tags(), fixture.RemoteTags(); slices.Contains(local, "ci/build/1") || slices.Contains(remote, "ci/build/1") {
+		t.Errorf("delete -pattern ci* -remote left local %v, remote %v", local, remote)
+	}
+
+	if stdout, _, code := gittag("-n", "delete", "-pattern", "v1.*", "-remote"); code != 0 || !strings.Contains(stdout, "[dry-run] git tag -d") {
+		t.Errorf("dry-run delete: exit %d\n%s", code, stdout)
+	}
+	if _, stderr, code := gittag("delete", "-pattern", "v1.*", "-remote"); code != 0 {
+		t.Fatalf("delete: exit %d\n%s", code, stderr)
+	}
+	if local, remote := fixture.Tags(), fixture.RemoteTags(); len(local) != 0 || len(remote) != 0 {
+		t.Errorf("tags after delete -remote: local %v, remote %v", local, remote)
+	}
+
+	if stdout, _, _ := gittag("doctor"); !strings.Contains(stdout, "[ok] git:") {
+		t.Errorf("doctor output:\n%s", stdout)
+	}
+}
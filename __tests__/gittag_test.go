@@ -1,10 +1,1149 @@
 package gittag
 
 import (
-	"github.com/afeiship/gittag"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/afeiship/gittag"
+	"github.com/afeiship/gittag/gittagtest"
 )
 
-func TestSayHi(f *testing.T) {
-	gittag.SayHi()
+func TestCreateLocal(t *testing.T) {
+	fixture := gittagtest.NewRepo(t)
+	repo := fixture.Open()
+
+	if err := repo.CreateLocal("v1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	if got := fixture.Git("tag", "-l", "--format=%(contents:subject)", "v1.0.0"); got != "chore(release): v1.0.0" {
+		t.Errorf("message = %q", got)
+	}
+}
+
+func TestFindMany(t *testing.T) {
+	repo := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0", "v1.1.0", "v2.0.0")).Open()
+
+	tags, err := repo.FindMany("v1.*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"v1.0.0", "v1.1.0"}; !reflect.DeepEqual(tags, want) {
+		t.Errorf("FindMany = %v, want %v", tags, want)
+	}
+	if _, err := repo.FindOne("v3.*"); err == nil {
+		t.Error("FindOne should fail when nothing matches")
+	}
+}
+
+func TestDeleteLocalAll(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0", "v1.1.0", "v2.0.0"))
+
+	if err := fixture.Open().DeleteLocalAll("v1.*"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fixture.Tags(), []string{"v2.0.0"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("tags = %v, want %v", got, want)
+	}
+}
+
+func TestFindManySort(t *testing.T) {
+	repo := gittagtest.NewRepo(t, gittagtest.WithTags("v1.10.0", "v1.9.0", "latest", "v1.10.0-rc.1", "v1.2.0")).Open()
+
+	cases := []struct {
+		order gittag.SortOrder
+		want  []string
+	}{
+		{gittag.SortByName, []string{"latest", "v1.10.0", "v1.10.0-rc.1", "v1.2.0", "v1.9.0"}},
+		{gittag.SortByVersion, []string{"v1.2.0", "v1.9.0", "v1.10.0-rc.1", "v1.10.0", "latest"}},
+		{gittag.SortByVersionDesc, []string{"v1.10.0", "v1.10.0-rc.1", "v1.9.0", "v1.2.0", "latest"}},
+		{gittag.SortByCreated, []string{"v1.10.0", "v1.9.0", "latest", "v1.10.0-rc.1", "v1.2.0"}},
+		{gittag.SortByCreatedDesc, []string{"v1.2.0", "v1.10.0-rc.1", "latest", "v1.9.0", "v1.10.0"}},
+	}
+	for _, c := range cases {
+		if got, err := repo.FindMany("*", gittag.WithSort(c.order)); err != nil || !reflect.DeepEqual(got, c.want) {
+			t.Errorf("FindMany(*, %d) = %v, %v; want %v", c.order, got, err, c.want)
+		}
+	}
+	if got, err := repo.FindOne("v*", gittag.WithSort(gittag.SortByCreatedDesc)); err != nil || got != "v1.2.0" {
+		t.Errorf("FindOne newest = %q, %v; want v1.2.0", got, err)
+	}
+}
+
+func TestFindManyMulti(t *testing.T) {
+	repo := gittagtest.NewRepo(t, gittagtest.WithTags("v1.9.0", "v1.10.0", "v2.0.0", "release/api/1.0", "v1.2.0")).Open()
+
+	got, err := repo.FindManyMulti([]string{"v1.*", "release/*", "v[12].0.0", "v3.*"}, gittag.WithSort(gittag.SortByVersion))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]string{
+		"v1.*":      {"v1.2.0", "v1.9.0", "v1.10.0"},
+		"release/*": {"release/api/1.0"},
+		"v[12].0.0": {"v2.0.0"},
+		"v3.*":      {},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindManyMulti = %v, want %v", got, want)
+	}
+	for pattern, tags := range got {
+		single, err := repo.FindMany(pattern, gittag.WithSort(gittag.SortByVersion))
+		if err == nil && !reflect.DeepEqual(single, tags) {
+			t.Errorf("FindMany(%q) = %v, FindManyMulti bucket = %v", pattern, single, tags)
+		}
+	}
+	if _, err := repo.FindManyMulti([]string{"v1.*", "-d"}); err == nil {
+		t.Error("FindManyMulti accepted an option-like pattern")
+	}
+
+	// 分页选项同样作用于每个模式的结果
+	got, err = repo.FindManyMulti([]string{"v1.*", "v*"}, gittag.WithLimit(2), gittag.WithAfter("v1.10.0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string][]string{"v1.*": {"v1.2.0", "v1.9.0"}, "v*": {"v1.2.0", "v1.9.0"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("paged FindManyMulti = %v, want %v", got, want)
+	}
+}
+
+func TestFindOnePastLastPage(t *testing.T) {
+	repo := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0", "v1.1.0")).Open()
+	if tag, err := repo.FindOne("v*", gittag.WithAfter("v9")); !errors.Is(err, gittag.ErrTagNotFound) {
+		t.Errorf("FindOne past the last tag = %q, %v; want ErrTagNotFound", tag, err)
+	}
+}
+
+func TestFindOneStrategies(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags("v1.10.0", "v1.9.0", "v1.10.0-rc.1", "v1"))
+	repo := fixture.Open()
+
+	cases := []struct {
+		pattern  string
+		strategy gittag.Strategy
+		want     string
+	}{
+		{"v1*", gittag.FirstMatch, "v1"},
+		{"v1*", gittag.HighestVersion, "v1.10.0"},
+		{"v1.9*", gittag.ExactOnly, ""},
+		{"v1.9.0", gittag.ExactOnly, "v1.9.0"},
+	}
+	for _, c := range cases {
+		got, err := repo.FindOne(c.pattern, gittag.WithStrategy(c.strategy))
+		if c.want == "" {
+			var notFound *gittag.NotFoundError
+			if !errors.As(err, &notFound) {
+				t.Errorf("FindOne(%q, %d) error = %v, want *NotFoundError", c.pattern, c.strategy, err)
+			}
+			continue
+		}
+		if err != nil || got != c.want {
+			t.Errorf("FindOne(%q, %d) = %q, %v; want %q", c.pattern, c.strategy, got, err, c.want)
+		}
+	}
+}
+
+func TestFindLatest(t *testing.T) {
+	repo := gittagtest.NewRepo(t, gittagtest.WithTags("v1.9.0", "v1.10.0", "v1.2.0", "latest")).Open()
+
+	if got, err := repo.FindLatest("v*"); err != nil || got != "v1.10.0" {
+		t.Errorf("FindLatest(v*) = %q, %v; want v1.10.0", got, err)
+	}
+	var notFound *gittag.NotFoundError
+	if _, err := repo.FindLatest("lat*"); !errors.As(err, &notFound) {
+		t.Errorf("FindLatest(lat*) error = %v, want *NotFoundError", err)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	repo := gittagtest.NewRepo(t, gittagtest.WithTags("v1.2.0-RC.1", "v1.2.0", "v2.0.0", "rc-tools")).Open()
+
+	got, err := repo.Search("rc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"rc-tools", "v1.2.0-RC.1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Search(rc) = %v, want %v", got, want)
+	}
+
+	got, err = repo.Search("v12")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"v1.2.0", "v1.2.0-RC.1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Search(v12) = %v, want %v", got, want)
+	}
+}
+
+func TestPreviousTag(t *testing.T) {
+	repo := gittagtest.NewRepo(t, gittagtest.WithTags(
+		"v1.9.0", "v1.10.0-beta.1", "v1.10.0-rc.1", "v1.10.0", "api/v1.9.5", "v2.0.0",
+	)).Open()
+
+	cases := []struct {
+		tag  string
+		opts []gittag.PreviousOption
+		want string
+	}{
+		{"v2.0.0", nil, "v1.10.0"},
+		{"v1.10.0", nil, "v1.9.0"},
+		{"v1.10.0", []gittag.PreviousOption{gittag.WithPrereleases()}, "v1.10.0-rc.1"},
+		{"v1.10.0", []gittag.PreviousOption{gittag.WithChannel("beta")}, "v1.10.0-beta.1"},
+		{"api/v2.0.0", nil, "api/v1.9.5"},
+	}
+	for _, c := range cases {
+		if got, err := repo.PreviousTag(c.tag, c.opts...); err != nil || got != c.want {
+			t.Errorf("PreviousTag(%q) = %q, %v; want %q", c.tag, got, err, c.want)
+		}
+	}
+	var notFound *gittag.NotFoundError
+	if _, err := repo.PreviousTag("v1.9.0"); !errors.As(err, &notFound) {
+		t.Errorf("PreviousTag(v1.9.0) error = %v, want *NotFoundError", err)
+	}
+}
+
+func TestFindByMessage(t *testing.T) {
+	fixture := gittagtest.NewRepo(t)
+	fixture.Git("tag", "-a", "v1.0.0", "-m", "Initial release")
+	fixture.Git("tag", "-a", "v1.1.0", "-m", "Release 1.1.0\n\n- Payments Migration to v2 API\n- PAY-123 fixes")
+	fixture.Git("tag", "payments-migration")
+	repo := fixture.Open()
+
+	names := func(infos []gittag.TagInfo, err error) []string {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, info := range infos {
+			names = append(names, info.Name)
+		}
+		return names
+	}
+	if got := names(repo.FindByMessage("payments migration")); !reflect.DeepEqual(got, []string{"v1.1.0"}) {
+		t.Errorf("FindByMessage = %v", got)
+	}
+	if got := names(repo.FindByMessageRegexp(regexp.MustCompile(`PAY-\d+`))); !reflect.DeepEqual(got, []string{"v1.1.0"}) {
+		t.Errorf("FindByMessageRegexp = %v", got)
+	}
+	if got := names(repo.FindByMessage("hotfix")); len(got) != 0 {
+		t.Errorf("FindByMessage(hotfix) = %v, want none", got)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	cases := map[string]string{
+		"1.2":               "v1.2.0",
+		"v01.2.3":           "v1.2.3",
+		"release-1.2.3":     "v1.2.3",
+		"Release/2":         "v2.0.0",
+		" V1.2.3-rc.01+b.7": "v1.2.3-rc.1+b.7",
+		"version-3.0.1":     "v3.0.1",
+		"RELEASE_4.1":       "v4.1.0",
+	}
+	for in, want := range cases {
+		if got, err := gittag.Normalize(in); err != nil || got != want {
+			t.Errorf("Normalize(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if got, err := gittag.Normalize("REL_1.2", gittag.WithVersionPrefixes("rel_"), gittag.WithCanonicalPrefix("")); err != nil || got != "1.2.0" {
+		t.Errorf("Normalize with options = %q, %v", got, err)
+	}
+	for _, in := range []string{"", "nightly", "api/v1.2.3", "1.2.3.4", "v1.2.3-"} {
+		if got, err := gittag.Normalize(in); err == nil {
+			t.Errorf("Normalize(%q) = %q, want error", in, got)
+		}
+	}
+}
+
+func TestSchemes(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags(
+		"RELEASE_2024_06_01", "RELEASE_2024_12_24", "RELEASE_2023_07_15",
+		"2024.06.01.9", "2024.06.01.10", "2024.05.30.11",
+		"build-9", "build-10",
+	))
+	repo := fixture.Open()
+
+	byNumber := gittag.RegexScheme(regexp.MustCompile(`build-(\d+)`), func(a, b []string) int {
+		x, _ := strconv.Atoi(a[1])
+		y, _ := strconv.Atoi(b[1])
+		return x - y
+	})
+	cases := []struct {
+		pattern string
+		scheme  gittag.Scheme
+		want    string
+	}{
+		{"RELEASE_*", gittag.CalVer("RELEASE_2006_01_02"), "RELEASE_2024_12_24"},
+		{"2024.*", gittag.DateBuild("2006.01.02", "."), "2024.06.01.10"},
+		{"build-*", byNumber, "build-10"},
+	}
+	for _, c := range cases {
+		got, err := repo.FindOne(c.pattern, gittag.WithStrategy(gittag.HighestVersion), gittag.WithScheme(c.scheme))
+		if err != nil || got != c.want {
+			t.Errorf("FindOne(%q) = %q, %v; want %q", c.pattern, got, err, c.want)
+		}
+	}
+
+	tags := []string{"v1.10.0", "nightly", "v1.9.0", "v1.10.0-rc.1"}
+	if got, want := gittag.SortVersions(tags, gittag.SemVer), []string{"v1.9.0", "v1.10.0-rc.1", "v1.10.0"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("SortVersions = %v, want %v", got, want)
+	}
+
+	// WithVersionScheme 是查找、EOL 统计的默认方案，Bump 只支持 SemVer
+	calver, err := gittag.Open(fixture.Dir, gittag.WithVersionScheme(gittag.CalVer("RELEASE_2006_01_02")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := calver.FindLatest("RELEASE_*"); err != nil || got != "RELEASE_2024_12_24" {
+		t.Errorf("FindLatest with CalVer = %q, %v", got, err)
+	}
+	want := []string{"RELEASE_2024_12_24", "RELEASE_2024_06_01", "RELEASE_2023_07_15"}
+	if got, err := calver.FindMany("RELEASE_*", gittag.WithSort(gittag.SortByVersionDesc)); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("FindMany with CalVer = %v, %v; want %v", got, err, want)
+	}
+	fixture.Git("tag", "RELEASE_2023_notes")
+	if err := calver.SetEOL("RELEASE_2023*", time.Now().AddDate(0, 0, -1)); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := calver.PastEOL("RELEASE_*"); err != nil || !reflect.DeepEqual(got, []string{"RELEASE_2023_07_15"}) {
+		t.Errorf("PastEOL with CalVer = %v, %v; want [RELEASE_2023_07_15]", got, err)
+	}
+	if _, err := calver.BumpPatch(); err == nil || !strings.Contains(err.Error(), "SemVer") {
+		t.Errorf("BumpPatch with CalVer = %v, want an error", err)
+	}
+}
+
+func TestIsLatest(t *testing.T) {
+	repo := gittagtest.NewRepo(t, gittagtest.WithTags(
+		"v1.4.0", "v1.4.2", "v1.4.10", "v1.4.11-rc.1", "v1.5.0", "api/v1.4.99", "v1.4.3-beta.1",
+	)).Open()
+
+	cases := map[string]bool{
+		"v1.4.10":       true,
+		"v1.4.2":        false,
+		"v1.5.0":        true,
+		"v1.4.11-rc.1":  true,
+		"v1.4.3-beta.1": false,
+		"api/v1.4.99":   true,
+	}
+	for tag, want := range cases {
+		if got, err := repo.IsLatest(tag); err != nil || got != want {
+			t.Errorf("IsLatest(%q) = %v, %v; want %v", tag, got, err, want)
+		}
+	}
+	var notFound *gittag.NotFoundError
+	if _, err := repo.IsLatest("v1.4.1"); !errors.As(err, &notFound) {
+		t.Errorf("IsLatest(v1.4.1) error = %v, want *NotFoundError", err)
+	}
+	for tag, want := range map[string]string{"v1.4.2": "v1.4", "api/v2.0.1-rc.1": "api/v2.0", "nightly": ""} {
+		if got := gittag.SeriesOf(tag); got != want {
+			t.Errorf("SeriesOf(%q) = %q, want %q", tag, got, want)
+		}
+	}
+}
+
+func TestAliases(t *testing.T) {
+	repo := gittagtest.NewRepo(t, gittagtest.WithTags("v2.3.0", "v2.4.0")).Open()
+
+	if err := repo.SetAlias("torino", "v2.3.0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.SetAlias("Milano", "v2.4.0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.SetAlias("torino", "v2.4.0"); err != nil {
+		t.Fatal(err)
+	}
+	var notFound *gittag.NotFoundError
+	if err := repo.SetAlias("roma", "v9.0.0"); !errors.As(err, &notFound) {
+		t.Errorf("SetAlias to a missing tag = %v, want *NotFoundError", err)
+	}
+	if err := repo.SetAlias("bad name", "v2.3.0"); err == nil {
+		t.Error("SetAlias accepted an invalid name")
+	}
+	if tag, err := repo.ResolveAlias("torino"); err != nil || tag != "v2.4.0" {
+		t.Errorf("ResolveAlias(torino) = %q, %v", tag, err)
+	}
+
+	if err := repo.RemoveAlias("torino"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.RemoveAlias("torino"); err != nil {
+		t.Errorf("RemoveAlias of a missing alias: %v", err)
+	}
+	aliases, err := repo.Aliases()
+	if want := map[string]string{"Milano": "v2.4.0"}; err != nil || !reflect.DeepEqual(aliases, want) {
+		t.Errorf("Aliases = %v, %v; want %v", aliases, err, want)
+	}
+	if _, err := repo.ResolveAlias("torino"); !errors.As(err, &notFound) {
+		t.Errorf("ResolveAlias(torino) after removal = %v, want *NotFoundError", err)
+	}
+}
+
+func TestDiffPatterns(t *testing.T) {
+	repo := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0", "v1.1.0", "release/1.1.0", "release/1.2.0")).Open()
+
+	diff, err := repo.DiffPatterns("v*", "release/*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &gittag.SetDiff{
+		OnlyA:  []string{"v1.0.0"},
+		OnlyB:  []string{"release/1.2.0"},
+		Common: []gittag.TagPair{{A: "v1.1.0", B: "release/1.1.0"}},
+	}
+	if !reflect.DeepEqual(diff, want) {
+		t.Errorf("DiffPatterns = %+v, want %+v", diff, want)
+	}
+}
+
+func TestCreateMessageVerbatim(t *testing.T) {
+	fixture := gittagtest.NewRepo(t)
+	repo := fixture.Open()
+
+	message := "# v1.0.0\n\n- 支持多行信息\n- keeps trailing newlines\n\n"
+	if err := repo.Create("v1.0.0", gittag.WithMessage(message)); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(t.TempDir(), "notes.md")
+	if err := os.WriteFile(file, []byte(strings.Repeat("line\n", 20000)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Create("v1.1.0", gittag.WithMessageFile(file)); err != nil {
+		t.Fatal(err)
+	}
+
+	infos, err := repo.ListInfo("v*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if infos[0].Message != message {
+		t.Errorf("message = %q, want %q", infos[0].Message, message)
+	}
+	if len(infos[1].Message) != 5*20000 {
+		t.Errorf("message file length = %d", len(infos[1].Message))
+	}
+}
+
+func TestCreateWithChangelog(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0"))
+	fixture.Commit("feat: first")
+	fixture.Commit("fix: second")
+	repo := fixture.Open()
+
+	if err := repo.CreateWithChangelog("v1.1.0"); err != nil {
+		t.Fatal(err)
+	}
+	got := fixture.Git("tag", "-l", "--format=%(contents)", "v1.1.0")
+	lines := strings.Split(got, "\n")
+	if len(lines) != 4 || lines[0] != "chore(release): v1.1.0" || !strings.HasPrefix(lines[2], "- fix: second (") || !strings.HasPrefix(lines[3], "- feat: first (") {
+		t.Errorf("unexpected message:\n%s", got)
+	}
+
+	// 变更日志截止到 WithTarget 指定的提交
+	if err := repo.CreateWithChangelog("v1.0.1", gittag.WithTarget("HEAD~1")); err != nil {
+		t.Fatal(err)
+	}
+	got = fixture.Git("tag", "-l", "--format=%(contents)", "v1.0.1")
+	if lines := strings.Split(got, "\n"); len(lines) != 3 || !strings.HasPrefix(lines[2], "- feat: first (") {
+		t.Errorf("unexpected message for a tag on HEAD~1:\n%s", got)
+	}
+}
+
+func TestChangelogForPath(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0"))
+	for _, dir := range []string{"services/payments", "services/ledger"} {
+		if err := os.MkdirAll(filepath.Join(fixture.Dir, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(fixture.Dir, dir, "main.go"), []byte("package main\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		fixture.Git("add", dir)
+		fixture.Commit("feat: add " + dir)
+	}
+	fixture.Commit("chore: unrelated")
+	repo, err := gittag.Open(filepath.Join(fixture.Dir, "services/ledger"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	notes, err := repo.ChangelogForPath("services/payments/", "v1.0.0", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(notes), "\n"); len(lines) != 1 || !strings.HasPrefix(lines[0], "- feat: add services/payments (") {
+		t.Errorf("ChangelogForPath = %q", notes)
+	}
+	all, err := repo.ChangelogForPath("", "v1.0.0", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(all, "\n"); n != 3 {
+		t.Errorf("ChangelogForPath(\"\") has %d entries, want 3", n)
+	}
+}
+
+func TestCreateReplaceCheck(t *testing.T) {
+	fixture := gittagtest.NewRepo(t)
+	goMod := "module example.com/app\n\nreplace (\n\texample.com/lib => ../lib\n\texample.com/fork v1.0.0 => example.com/fork v1.0.1\n)\n" +
+		"replace example.com/internal/tools => ./tools // dev only\n"
+	if err := os.WriteFile(filepath.Join(fixture.Dir, "go.mod"), []byte(goMod), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(fixture.Dir, "sdk"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(fixture.Dir, "sdk", "go.mod"), []byte("module example.com/app/sdk\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fixture.Git("add", ".")
+	fixture.Commit("add go.mod")
+	repo := fixture.Open()
+
+	err := repo.Create("v1.0.0", gittag.WithReplaceCheck("example.com/lib"))
+	var replaceErr *gittag.ReplaceError
+	if !errors.As(err, &replaceErr) || replaceErr.File != "go.mod" ||
+		!reflect.DeepEqual(replaceErr.Replaces, []string{"example.com/internal/tools => ./tools"}) {
+		t.Fatalf("Create = %v, want *ReplaceError for ./tools", err)
+	}
+	if tags := fixture.Tags(); len(tags) != 0 {
+		t.Errorf("tags created despite replace check: %v", tags)
+	}
+	if err := repo.Create("v1.0.0", gittag.WithReplaceCheck("example.com/lib", "example.com/internal/*")); err != nil {
+		t.Errorf("Create with allow-list: %v", err)
+	}
+	if err := repo.Create("sdk/v0.1.0", gittag.WithReplaceCheck()); err != nil {
+		t.Errorf("Create(sdk/v0.1.0) checked the root go.mod: %v", err)
+	}
+}
+
+func TestCreatePreTagCommand(t *testing.T) {
+	fixture := gittagtest.NewRepo(t)
+	repo := fixture.Open()
+
+	err := repo.Create("v1.0.0",
+		gittag.WithPreTagCommand("echo $GITTAG_TAG > pre.txt"),
+		gittag.WithPreTagCommand("echo tests failed; exit 3", "touch never.txt"))
+	var preTagErr *gittag.PreTagError
+	if !errors.As(err, &preTagErr) || preTagErr.Command != "echo tests failed; exit 3" || preTagErr.Output != "tests failed" {
+		t.Fatalf("Create = %v, want *PreTagError", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(fixture.Dir, "pre.txt")); err != nil || string(data) != "v1.0.0\n" {
+		t.Errorf("first command did not run: %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(fixture.Dir, "never.txt")); err == nil {
+		t.Error("commands after the failing one ran")
+	}
+	if tags := fixture.Tags(); len(tags) != 0 {
+		t.Errorf("tags created despite failing command: %v", tags)
+	}
+
+	if err := repo.Create("v1.0.0", gittag.WithPreTagCommand("true")); err != nil {
+		t.Fatal(err)
+	}
+
+	// 受限句柄不能通过 shell 命令绕过能力检查
+	restricted := repo.Restrict(gittag.CapRead, gittag.CapCreate)
+	if err := restricted.Create("v1.1.0", gittag.WithPreTagCommand("git tag -d v1.0.0")); !errors.Is(err, gittag.ErrNotPermitted) {
+		t.Errorf("restricted Create with a pre-tag command = %v, want ErrNotPermitted", err)
+	}
+	if tags := fixture.Tags(); !reflect.DeepEqual(tags, []string{"v1.0.0"}) {
+		t.Errorf("tags after the refused command = %v", tags)
+	}
+
+	// 命令在获取仓库锁之前执行，不阻塞其他操作
+	done := make(chan error, 1)
+	go func() {
+		done <- repo.Create("v1.2.0", gittag.WithPreTagCommand("touch started && sleep 1"))
+	}()
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if _, err := os.Stat(filepath.Join(fixture.Dir, "started")); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("pre-tag command did not start")
+		}
+	}
+	other, err := gittag.Open(fixture.Dir, gittag.WithLockTimeout(200*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := other.CreateLocal("v1.1.0"); err != nil {
+		t.Errorf("CreateLocal while a pre-tag command runs = %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCreateFromCI(t *testing.T) {
+	for _, key := range []string{"GITLAB_CI", "JENKINS_URL"} {
+		t.Setenv(key, "")
+	}
+	fixture := gittagtest.NewRepo(t)
+	checkedOut := fixture.Git("rev-parse", "HEAD")
+	fixture.Commit("pushed after the pipeline started")
+
+	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv("GITHUB_SERVER_URL", "https://github.com")
+	t.Setenv("GITHUB_REPOSITORY", "afeiship/go-git-tag")
+	t.Setenv("GITHUB_RUN_ID", "42")
+	t.Setenv("GITHUB_RUN_NUMBER", "7")
+	t.Setenv("GITHUB_ACTOR", "octocat")
+	t.Setenv("GITHUB_SHA", checkedOut)
+
+	if err := fixture.Open().CreateFromCI("v1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	if got := fixture.Git("rev-parse", "v1.0.0^{}"); got != checkedOut {
+		t.Errorf("tagged %s, want %s", got, checkedOut)
+	}
+	message := fixture.Git("tag", "-l", "--format=%(contents)", "v1.0.0")
+	for _, want := range []string{"Run: https://github.com/afeiship/go-git-tag/actions/runs/42", "Build: 7", "Actor: octocat"} {
+		if !strings.Contains(message, want) {
+			t.Errorf("message missing %q:\n%s", want, message)
+		}
+	}
+}
+
+func TestDetachedHeadSafety(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithCommits(2))
+	repo := fixture.Open()
+	start := fixture.Git("rev-parse", "HEAD")
+
+	if err := repo.RequireHeadEquals(start[:8]); err != nil {
+		t.Errorf("RequireHeadEquals(short sha) = %v", err)
+	}
+	fixture.Commit("moved")
+	if err := repo.RequireHeadEquals(start); err == nil {
+		t.Error("RequireHeadEquals should fail after HEAD moved")
+	}
+
+	fixture.Git("checkout", "--quiet", "--detach", "HEAD~1")
+	if err := repo.CreateLocal("v1.0.0"); err == nil || !strings.Contains(err.Error(), "WithAllowDetached") {
+		t.Errorf("CreateLocal on detached HEAD error = %v", err)
+	}
+	if err := repo.Create("v1.0.0", gittag.WithAllowDetached()); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Create("v1.0.1", gittag.WithTarget(start)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIsDetachedError(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithCommits(1))
+	real, err := exec.LookPath("git")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// symbolic-ref 以非 1 的退出码失败时不能当作分离 HEAD
+	bin := t.TempDir()
+	shim := "#!/bin/sh\ncase \" $* \" in *\" symbolic-ref \"*) echo 'fatal: cannot read HEAD' >&2; exit 128;; esac\nexec " + real + " \"$@\"\n"
+	if err := os.WriteFile(filepath.Join(bin, "git"), []byte(shim), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	repo := fixture.Open()
+	if detached, err := repo.IsDetached(); err == nil {
+		t.Errorf("IsDetached = %v, nil; want error", detached)
+	}
+	if err := repo.CreateLocal("v1.0.0"); err == nil || strings.Contains(err.Error(), "WithAllowDetached") {
+		t.Errorf("CreateLocal = %v, want the symbolic-ref error", err)
+	}
+}
+
+func TestTrace(t *testing.T) {
+	fixture := gittagtest.NewRepo(t)
+	var trace gittag.Trace
+	repo, err := gittag.Open(fixture.Dir, gittag.WithTrace(&trace))
+	if err != nil {
+		t.Fatal(err)
+	}
+	trace.Reset()
+
+	if err := repo.CreateLocal("v1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	repo.DeleteLocal("missing")
+
+	commands := trace.Commands()
+	if len(commands) == 0 {
+		t.Fatal("no commands traced")
+	}
+	last := commands[len(commands)-1]
+	if last.Name != "git" || last.ExitCode == 0 || last.Args[len(last.Args)-1] != "missing" {
+		t.Errorf("last command = %+v, want failed git tag -d", last)
+	}
+	if !strings.Contains(trace.String(), "tag -a --cleanup=verbatim -F - -- v1.0.0") {
+		t.Errorf("trace missing tag command:\n%s", trace.String())
+	}
+}
+
+func TestMessageTemplate(t *testing.T) {
+	fixture := gittagtest.NewRepo(t)
+	repo, err := gittag.Open(fixture.Dir, gittag.WithMessageTemplate("Release {{.Version}} ({{.Prefix}})"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.CreateLocal("api/v1.2.3"); err != nil {
+		t.Fatal(err)
+	}
+	if got := fixture.Git("tag", "-l", "--format=%(contents:subject)", "api/v1.2.3"); got != "Release 1.2.3 (api/v)" {
+		t.Errorf("message = %q", got)
+	}
+
+	if _, err := gittag.Open(fixture.Dir, gittag.WithMessageTemplate("{{.Missing}}")); err == nil {
+		t.Error("Open should reject a template with unknown fields")
+	}
+	if err := gittag.SetMessageTemplate("{{"); err == nil {
+		t.Error("SetMessageTemplate should reject an unparsable template")
+	}
+}
+
+func TestFreeze(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0", "v2.0.0"))
+	repo := fixture.Open()
+
+	if err := repo.Freeze("v1.*"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Freeze("v1.*"); err != nil {
+		t.Fatal(err)
+	}
+	if frozen, _ := repo.Frozen(); !reflect.DeepEqual(frozen, []string{"v1.*"}) {
+		t.Errorf("Frozen() = %v", frozen)
+	}
+	if err := repo.CreateLocal("v1.1.0"); !errors.Is(err, gittag.ErrSeriesFrozen) {
+		t.Errorf("CreateLocal(v1.1.0) = %v, want ErrSeriesFrozen", err)
+	}
+	if err := repo.DeleteLocalAll("v*"); !errors.Is(err, gittag.ErrSeriesFrozen) {
+		t.Errorf("DeleteLocalAll = %v, want ErrSeriesFrozen", err)
+	}
+	if err := repo.CreateLocal("v2.1.0"); err != nil {
+		t.Errorf("CreateLocal(v2.1.0) = %v", err)
+	}
+
+	if err := repo.Unfreeze("v1.*"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Unfreeze("v1.*"); err != nil {
+		t.Errorf("second Unfreeze = %v", err)
+	}
+	if err := repo.DeleteLocal("v1.0.0"); err != nil {
+		t.Errorf("DeleteLocal after Unfreeze = %v", err)
+	}
+}
+
+func TestSupportMatrix(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0", "v1.1.0", "v2.0.0", "v3.0.0"), gittagtest.WithRemote())
+	repo := fixture.Open()
+	today := time.Now()
+
+	if err := repo.SetEOL("v1.*", today.AddDate(0, 0, -1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.SetEOL("v2.*", today); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.SetEOL("v3.*", today.AddDate(1, 0, 0)); err != nil {
+		t.Fatal(err)
+	}
+
+	series, err := repo.SupportedSeries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"v2.*", "v3.*"}; !reflect.DeepEqual(series, want) {
+		t.Errorf("SupportedSeries() = %v, want %v", series, want)
+	}
+	expired, err := repo.PastEOL("v*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"v1.0.0", "v1.1.0"}; !reflect.DeepEqual(expired, want) {
+		t.Errorf("PastEOL() = %v, want %v", expired, want)
+	}
+
+	stats, err := repo.Stats("v*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (&gittag.TagStats{Total: 4, Supported: 2, PastEOL: []string{"v1.0.0", "v1.1.0"}}); !reflect.DeepEqual(stats, want) {
+		t.Errorf("Stats() = %+v, want %+v", stats, want)
+	}
+	fixture.Git("tag", "nightly")
+	if stats, err := repo.Stats("*"); err != nil || stats.Total != 5 || stats.Unmanaged != 1 {
+		t.Errorf("Stats(*) = %+v, %v; want 1 unmanaged of 5", stats, err)
+	}
+	fixture.Git("tag", "-d", "nightly")
+
+	// 按模式批量删除跳过仍受支持系列的标签，删除其余标签
+	if err := repo.DeleteRemoteByPattern("v*"); err != nil {
+		t.Errorf("DeleteRemoteByPattern = %v", err)
+	}
+	if got := fixture.RemoteTags(); !reflect.DeepEqual(got, []string{"v2.0.0", "v3.0.0"}) {
+		t.Errorf("remote tags after DeleteRemoteByPattern = %v, want [v2.0.0 v3.0.0]", got)
+	}
+	if err := repo.DeleteRemoteAll("v*"); err != nil {
+		t.Errorf("DeleteRemoteAll = %v", err)
+	}
+	if got := fixture.RemoteTags(); !reflect.DeepEqual(got, []string{"v2.0.0", "v3.0.0"}) {
+		t.Errorf("remote tags after DeleteRemoteAll = %v, want [v2.0.0 v3.0.0]", got)
+	}
+	if err := repo.DeleteAllTags(); err != nil {
+		t.Errorf("DeleteAllTags = %v", err)
+	}
+	if got := fixture.Tags(); !reflect.DeepEqual(got, []string{"v2.0.0", "v3.0.0"}) {
+		t.Errorf("tags after DeleteAllTags = %v, want [v2.0.0 v3.0.0]", got)
+	}
+	if err := repo.DeleteLocal("v3.0.0"); err != nil {
+		t.Errorf("DeleteLocal of a single supported tag = %v", err)
+	}
+	if got := fixture.Tags(); !reflect.DeepEqual(got, []string{"v2.0.0"}) {
+		t.Errorf("tags after deletes = %v, want [v2.0.0]", got)
+	}
+}
+
+func TestCreateLightweight(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0"))
+	repo := fixture.Open()
+
+	if err := repo.CreateLightweight("ci/build-1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Create("ci/build-0", gittag.WithLightweight(), gittag.WithTarget("v1.0.0~1")); err != nil {
+		t.Fatal(err)
+	}
+	if got := fixture.Git("tag", "-l", "--format=%(objecttype) %(objectname)", "ci/*"); got != "commit "+fixture.Git("rev-parse", "HEAD~1")+"\ncommit "+fixture.Git("rev-parse", "HEAD") {
+		t.Errorf("lightweight tags = %q", got)
+	}
+	if err := repo.Create("ci/build-2", gittag.WithLightweight(), gittag.WithMessage("note")); err == nil {
+		t.Error("lightweight tag with a message succeeded")
+	}
+}
+
+func TestCreateLocalAt(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithCommits(3))
+	fixture.Git("branch", "release/1.x", "HEAD~2")
+	fixture.Git("checkout", "--quiet", "--detach")
+	repo := fixture.Open()
+
+	first := fixture.Git("rev-parse", "HEAD~1")
+	cases := map[string]string{
+		"v1.0.0": "release/1.x",
+		"v1.1.0": first,
+	}
+	for tag, ref := range cases {
+		if err := repo.CreateLocalAt(tag, ref, "tagged "+ref); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := fixture.Git("rev-parse", tag+"^{commit}"), fixture.Git("rev-parse", ref); got != want {
+			t.Errorf("%s -> %s, want %s", tag, got, want)
+		}
+	}
+	if msg := fixture.Git("tag", "-l", "--format=%(contents)", "v1.1.0"); msg != "tagged "+first {
+		t.Errorf("message = %q", msg)
+	}
+	if err := repo.CreateLocalAt("v1.2.0", "no-such-branch"); err == nil {
+		t.Error("CreateLocalAt with an unknown ref succeeded")
+	}
+	if err := repo.CreateLocalAt("v1.2.0", ""); err == nil {
+		t.Error("CreateLocalAt with an empty ref succeeded")
+	}
+}
+
+func TestOpenIndependentCheckouts(t *testing.T) {
+	first := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0"))
+	second := gittagtest.NewRepo(t, gittagtest.WithTags("v2.0.0"))
+
+	// 相对路径在 Open 时解析为绝对路径，之后与进程的工作目录无关
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rel, err := filepath.Rel(wd, first.Dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := gittag.Open(rel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Dir() != first.Dir {
+		t.Errorf("Dir() = %q, want %q", a.Dir(), first.Dir)
+	}
+	b, err := gittag.Open(filepath.Join(second.Dir, "."))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := a.CreateLocal("v1.1.0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.DeleteLocal("v2.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	if got := first.Tags(); !reflect.DeepEqual(got, []string{"v1.0.0", "v1.1.0"}) {
+		t.Errorf("first tags = %v", got)
+	}
+	if got := second.Tags(); len(got) != 0 {
+		t.Errorf("second tags = %v, want none", got)
+	}
+
+	if _, err := gittag.Open(t.TempDir()); err == nil {
+		t.Error("Open on a directory outside any repository succeeded")
+	}
+}
+
+func TestOpenWithProfile(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithRemote())
+	mirror := gittagtest.NewRepo(t, gittagtest.WithRemote())
+	fixture.Git("config", "gittag-profile.mirror.remoteurl", mirror.RemoteDir)
+	fixture.Git("config", "gittag-profile.mirror.messagetemplate", "mirror {{.Tag}}")
+	fixture.Git("config", "--add", "gittag-profile.mirror.notify", "https://hooks.example.com/a")
+
+	repo, err := gittag.OpenWithProfile(fixture.Dir, "mirror")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.CreateTag("v1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	if got := mirror.RemoteTags(); !reflect.DeepEqual(got, []string{"v1.0.0"}) {
+		t.Errorf("mirror remote tags = %v", got)
+	}
+	if got := fixture.RemoteTags(); len(got) != 0 {
+		t.Errorf("origin should be untouched, has %v", got)
+	}
+	if got := fixture.Git("tag", "-l", "--format=%(contents:subject)", "v1.0.0"); got != "mirror v1.0.0" {
+		t.Errorf("message = %q", got)
+	}
+	if p := repo.Profile(); p == nil || !reflect.DeepEqual(p.Notify, []string{"https://hooks.example.com/a"}) {
+		t.Errorf("Profile() = %+v", p)
+	}
+
+	gittag.RegisterProfile(gittag.Profile{Name: "mirror", MessageTemplate: "registered {{.Tag}}"})
+	if repo, err = gittag.OpenWithProfile(fixture.Dir, "mirror"); err != nil || repo.Profile().MessageTemplate != "registered {{.Tag}}" {
+		t.Errorf("registered profile not preferred: %v", err)
+	}
+	if _, err := gittag.OpenWithProfile(fixture.Dir, "missing"); err == nil {
+		t.Error("OpenWithProfile should fail for an unknown profile")
+	}
+}
+
+func TestSetDefault(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0"))
+	other := gittagtest.NewRepo(t, gittagtest.WithTags("v2.0.0"))
+	t.Cleanup(func() { gittag.SetDefault(nil) })
+
+	gittag.SetDefault(fixture.Open())
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i%2 == 0 {
+				gittag.SetDefault(other.Open())
+			}
+			if _, err := gittag.FindMany("v*"); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	gittag.SetDefault(fixture.Open())
+	if tags, err := gittag.FindMany("v*"); err != nil || !reflect.DeepEqual(tags, []string{"v1.0.0"}) {
+		t.Errorf("FindMany = %v, %v", tags, err)
+	}
+	if gittag.Default().Dir() != fixture.Dir {
+		t.Errorf("Default().Dir() = %q, want %q", gittag.Default().Dir(), fixture.Dir)
+	}
+}
+
+func TestBisectTags(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0", "v1.1.0", "v1.2.0"))
+	if err := os.WriteFile(filepath.Join(fixture.Dir, "bug"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fixture.Git("add", "bug")
+	fixture.Commit("introduce bug")
+	fixture.Tag("v1.3.0")
+	fixture.Git("tag", "v1.4.0-rc.1")
+	for _, tag := range []string{"v1.4.0", "v1.5.0", "v1.6.0"} {
+		fixture.Commit("release " + tag)
+		fixture.Tag(tag)
+	}
+	repo := fixture.Open()
+
+	var tested []string
+	first, err := repo.BisectTags("v1.0.0", "v1.6.0", func(tag, dir string) (bool, error) {
+		tested = append(tested, tag)
+		_, err := os.Stat(filepath.Join(dir, "bug"))
+		return os.IsNotExist(err), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != "v1.3.0" || len(tested) > 3 {
+		t.Errorf("BisectTags = %s after testing %v, want v1.3.0 in at most 3 steps", first, tested)
+	}
+	if worktrees := fixture.Git("worktree", "list"); strings.Count(worktrees, "\n") != 0 {
+		t.Errorf("temporary worktrees left behind:\n%s", worktrees)
+	}
+	if _, err := repo.BisectTags("v1.6.0", "v1.0.0", nil); err == nil {
+		t.Error("BisectTags with good after bad succeeded")
+	}
+}
+
+func TestWithContext(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0"))
+	repo := fixture.Open()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	bound := repo.WithContext(ctx)
+	if err := bound.CreateLocal("v1.1.0"); !errors.Is(err, context.Canceled) {
+		t.Errorf("CreateLocal with cancelled context = %v, want context.Canceled", err)
+	}
+	if _, err := bound.FindMany("v*"); !errors.Is(err, context.Canceled) {
+		t.Errorf("FindMany with cancelled context = %v, want context.Canceled", err)
+	}
+	if tags := fixture.Tags(); !reflect.DeepEqual(tags, []string{"v1.0.0"}) {
+		t.Errorf("tags = %v, want only v1.0.0", tags)
+	}
+
+	// 原仓库不受影响
+	if err := repo.CreateLocal("v1.1.0"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestQueue(t *testing.T) {
+	first := gittagtest.NewRepo(t).Open()
+	second := gittagtest.NewRepo(t).Open()
+	q := gittag.NewQueue(4)
+
+	var mu sync.Mutex
+	active := map[string]int{}
+	var order []string
+	var overlap bool
+	enqueue := func(repo *gittag.Repository, tag string) string {
+		return q.Enqueue(repo, func(ctx context.Context, repo *gittag.Repository) error {
+			mu.Lock()
+			active[repo.Dir()]++
+			overlap = overlap || active[repo.Dir()] > 1
+			mu.Unlock()
+			time.Sleep(10 * time.Millisecond)
+			err := repo.CreateLocal(tag)
+			mu.Lock()
+			active[repo.Dir()]--
+			if repo.Dir() == first.Dir() {
+				order = append(order, tag)
+			}
+			mu.Unlock()
+			return err
+		})
+	}
+	var ids []string
+	for _, tag := range []string{"v1.0.0", "v1.1.0", "v1.2.0"} {
+		ids = append(ids, enqueue(first, tag), enqueue(second, tag))
+	}
+	failing := enqueue(first, "v1.0.0")
+	if status, ok := q.Status(ids[0]); !ok || status.State != gittag.OperationQueued {
+		t.Errorf("status before Run = %+v, %v", status, ok)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- q.Run(ctx) }()
+	for _, id := range ids {
+		if err := q.Wait(context.Background(), id); err != nil {
+			t.Errorf("operation %s: %v", id, err)
+		}
+	}
+	if err := q.Wait(context.Background(), failing); err == nil {
+		t.Error("creating a duplicate tag succeeded")
+	}
+	if status, _ := q.Status(failing); status.State != gittag.OperationFailed || status.Error == "" {
+		t.Errorf("failing status = %+v", status)
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Run = %v", err)
+	}
+
+	if overlap {
+		t.Error("operations on the same repository overlapped")
+	}
+	if !reflect.DeepEqual(order, []string{"v1.0.0", "v1.1.0", "v1.2.0", "v1.0.0"}) {
+		t.Errorf("operations ran in order %v", order)
+	}
+	if _, ok := q.Status("op-999"); ok {
+		t.Error("Status of unknown operation succeeded")
+	}
+}
+
+func TestNamingConvention(t *testing.T) {
+	fixture := gittagtest.NewRepo(t)
+	repo, err := gittag.Open(fixture.Dir, gittag.WithNamingConvention(gittag.SemverNaming))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name, want string
+		fixable    bool
+	}{
+		{"v1.2.3", "v1.2.3", true},
+		{"v2.0.0-rc.1", "v2.0.0-rc.1", true},
+		{"1.2", "v1.2.0", true},
+		{"1.2.3", "v1.2.3", true},
+		{"release-1.2.3", "v1.2.3", true},
+		{"latest", "", false},
+		{"nightly-build", "", false},
+	}
+	for _, c := range cases {
+		got, err := repo.FixName(c.name)
+		var namingErr *gittag.NamingError
+		switch {
+		case c.fixable && (err != nil || got != c.want):
+			t.Errorf("FixName(%q) = %q, %v; want %q", c.name, got, err, c.want)
+		case !c.fixable && !errors.As(err, &namingErr):
+			t.Errorf("FixName(%q) = %q, %v; want a *NamingError", c.name, got, err)
+		}
+	}
+
+	// 不符合约定的名称被拒绝，错误中附带修正建议
+	err = repo.CreateLocal("1.2")
+	var namingErr *gittag.NamingError
+	if !errors.As(err, &namingErr) || namingErr.Suggestion != "v1.2.0" {
+		t.Errorf("CreateLocal(1.2) = %v, want a *NamingError suggesting v1.2.0", err)
+	}
+	if err := repo.CreateLocal("latest"); !errors.As(err, &namingErr) || namingErr.Suggestion != "" {
+		t.Errorf("CreateLocal(latest) = %v, want a *NamingError without a suggestion", err)
+	}
+	if err := repo.CreateLocal("v1.2.0"); err != nil {
+		t.Errorf("CreateLocal(v1.2.0) = %v", err)
+	}
+	if got := fixture.Tags(); !reflect.DeepEqual(got, []string{"v1.2.0"}) {
+		t.Errorf("tags = %v, want [v1.2.0]", got)
+	}
+
+	// 没有命名约定时名称原样返回
+	if got, err := fixture.Open().FixName("latest"); err != nil || got != "latest" {
+		t.Errorf("FixName without a convention = %q, %v", got, err)
+	}
 }
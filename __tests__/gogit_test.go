@@ -0,0 +1,62 @@
+package gittag
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/afeiship/gittag"
+	"github.com/afeiship/gittag/gittagtest"
+	"github.com/afeiship/gittag/gogit"
+)
+
+func TestGoGitBackend(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0", "v1.1.0", "v2.0.0"), gittagtest.WithRemote())
+	backend, err := gogit.NewBackend(fixture.Dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 与 git 后端的查找结果一致
+	for _, pattern := range []string{"v1.*", "v*", "v?.0.0", "v[12].0.0"} {
+		want, _ := fixture.Open().FindMany(pattern)
+		if got, err := backend.FindMany(pattern); err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("FindMany(%q) = %v, %v, want %v", pattern, got, err, want)
+		}
+	}
+	var notFound *gittag.NotFoundError
+	if _, err := backend.FindOne("v3.*"); !errors.As(err, &notFound) {
+		t.Errorf("FindOne(v3.*) = %v, want *NotFoundError", err)
+	}
+
+	if err := backend.CreateTag("v2.1.0"); err != nil {
+		t.Fatal(err)
+	}
+	if got := fixture.Git("tag", "-l", "--format=%(objecttype) %(contents:subject)", "v2.1.0"); got != "tag chore(release): v2.1.0" {
+		t.Errorf("created tag = %q, want annotated tag with the default message", got)
+	}
+	if got := fixture.RemoteTags(); !reflect.DeepEqual(got, []string{"v1.0.0", "v1.1.0", "v2.0.0", "v2.1.0"}) {
+		t.Errorf("remote tags after CreateTag = %v", got)
+	}
+
+	// 与 git 后端一样返回 gittag 的错误类型
+	if err := backend.CreateLocal("v2.1.0"); !errors.Is(err, gittag.ErrTagExists) {
+		t.Errorf("CreateLocal(existing) = %v, want ErrTagExists", err)
+	}
+	if err := backend.DeleteLocal("v9.0.0"); !errors.As(err, &notFound) || notFound.Pattern != "v9.0.0" {
+		t.Errorf("DeleteLocal(missing) = %v, want *NotFoundError", err)
+	}
+	if _, err := backend.FindMany("-v*"); !errors.Is(err, gittag.ErrInvalidPattern) {
+		t.Errorf("FindMany(-v*) = %v, want ErrInvalidPattern", err)
+	}
+
+	if err := backend.DeleteTag("v1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	if got := fixture.Tags(); !reflect.DeepEqual(got, []string{"v1.1.0", "v2.0.0", "v2.1.0"}) {
+		t.Errorf("local tags after DeleteTag = %v", got)
+	}
+	if got := fixture.RemoteTags(); !reflect.DeepEqual(got, []string{"v1.1.0", "v2.0.0", "v2.1.0"}) {
+		t.Errorf("remote tags after DeleteTag = %v", got)
+	}
+}
@@ -0,0 +1,136 @@
+package gittag
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/afeiship/gittag"
+	"github.com/afeiship/gittag/gittagtest"
+	"github.com/afeiship/gittag/httpapi"
+)
+
+func TestTagWebhook(t *testing.T) {
+	hook := &httpapi.TagWebhook{GitHubSecret: "s3cret", GitLabToken: "t0ken"}
+	var events []httpapi.Event
+	hook.Handle(func(ctx context.Context, e httpapi.Event) error {
+		events = append(events, e)
+		return nil
+	})
+	zero := strings.Repeat("0", 40)
+	sha := strings.Repeat("a", 40)
+
+	send := func(body string, headers map[string]string) int {
+		req := httptest.NewRequest(http.MethodPost, "/hooks/tags", strings.NewReader(body))
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		hook.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	sign := func(body string) string {
+		mac := hmac.New(sha256.New, []byte("s3cret"))
+		mac.Write([]byte(body))
+		return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	}
+
+	github := `{"ref":"refs/tags/v1.0.0","before":"` + zero + `","after":"` + sha + `","repository":{"full_name":"afeiship/go-git-tag"},"sender":{"login":"octocat"}}`
+	if code := send(github, map[string]string{"X-GitHub-Event": "push", "X-Hub-Signature-256": sign(github)}); code != http.StatusOK {
+		t.Errorf("GitHub tag push = %d", code)
+	}
+	if code := send(github, map[string]string{"X-GitHub-Event": "push", "X-Hub-Signature-256": sign(github + " ")}); code != http.StatusUnauthorized {
+		t.Errorf("bad signature = %d, want 401", code)
+	}
+	branch := `{"ref":"refs/heads/main","before":"` + zero + `","after":"` + sha + `"}`
+	if code := send(branch, map[string]string{"X-GitHub-Event": "push", "X-Hub-Signature-256": sign(branch)}); code != http.StatusNoContent {
+		t.Errorf("branch push = %d, want 204", code)
+	}
+
+	gitlab := `{"object_kind":"tag_push","ref":"refs/tags/v0.9.0","before":"` + sha + `","after":"` + zero + `","project":{"path_with_namespace":"group/app"},"user_username":"jane"}`
+	if code := send(gitlab, map[string]string{"X-Gitlab-Event": "Tag Push Hook", "X-Gitlab-Token": "wrong"}); code != http.StatusUnauthorized {
+		t.Errorf("bad token = %d, want 401", code)
+	}
+	if code := send(gitlab, map[string]string{"X-Gitlab-Event": "Tag Push Hook", "X-Gitlab-Token": "t0ken"}); code != http.StatusOK {
+		t.Errorf("GitLab tag push = %d", code)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2", len(events))
+	}
+	if e := events[0]; e.Type != gittag.TagCreated || e.Name != "v1.0.0" || e.Object != sha || e.Repository != "afeiship/go-git-tag" || e.Sender != "octocat" {
+		t.Errorf("GitHub event = %+v", e)
+	}
+	if e := events[1]; e.Type != gittag.TagDeleted || e.Name != "v0.9.0" || e.OldObject != sha || e.Provider != httpapi.GitLab {
+		t.Errorf("GitLab event = %+v", e)
+	}
+}
+
+func TestTagListPagination(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0", "v1.1.0", "v1.2.0", "v1.10.0", "v1.9.0"))
+	list := &httpapi.TagList{Repository: fixture.Open(), MaxLimit: 3}
+
+	get := func(query string) (int, httpapi.TagPage) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		list.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/tags?"+query, nil))
+		var page httpapi.TagPage
+		if rec.Code == http.StatusOK {
+			if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return rec.Code, page
+	}
+
+	code, page := get("pattern=v1.*&limit=2")
+	if code != http.StatusOK || !reflect.DeepEqual(page, httpapi.TagPage{Tags: []string{"v1.0.0", "v1.1.0"}, Next: "v1.1.0"}) {
+		t.Fatalf("first page = %d %+v", code, page)
+	}
+	// 删除游标标签并新增排在前面的标签，按名称分页既不重复也不遗漏
+	fixture.Git("tag", "-d", "v1.1.0")
+	fixture.Git("tag", "v1.05.0")
+	code, page = get("pattern=v1.*&limit=2&after=" + page.Next)
+	if code != http.StatusOK || !reflect.DeepEqual(page, httpapi.TagPage{Tags: []string{"v1.10.0", "v1.2.0"}, Next: "v1.2.0"}) {
+		t.Fatalf("second page = %d %+v", code, page)
+	}
+	code, page = get("pattern=v1.*&limit=2&after=" + page.Next)
+	if code != http.StatusOK || !reflect.DeepEqual(page, httpapi.TagPage{Tags: []string{"v1.9.0"}}) {
+		t.Fatalf("last page = %d %+v", code, page)
+	}
+
+	if _, page = get("sort=version-desc&limit=10"); !reflect.DeepEqual(page.Tags, []string{"v1.10.0", "v1.9.0", "v1.2.0"}) || page.Next != "v1.2.0" {
+		t.Errorf("limit above MaxLimit = %+v", page)
+	}
+	if code, page = get("pattern=v2.*"); code != http.StatusOK || page.Tags == nil || len(page.Tags) != 0 {
+		t.Errorf("no matches = %d %+v, want an empty page", code, page)
+	}
+	for _, query := range []string{"limit=0", "sort=random", "sort=version&after=v9.9.9", "pattern=-v1"} {
+		if code, _ := get(query); code != http.StatusBadRequest {
+			t.Errorf("%s = %d, want 400", query, code)
+		}
+	}
+
+	// tag.sort 配置不影响按名称分页的顺序
+	fixture.Git("config", "tag.sort", "-version:refname")
+	if _, page = get("pattern=v1.*&limit=2"); !reflect.DeepEqual(page.Tags, []string{"v1.0.0", "v1.05.0"}) {
+		t.Errorf("first page with tag.sort set = %+v", page)
+	}
+
+	// git 执行失败是服务端错误
+	if err := os.RemoveAll(filepath.Join(fixture.Dir, ".git")); err != nil {
+		t.Fatal(err)
+	}
+	if code, _ := get("pattern=v1.*"); code != http.StatusInternalServerError {
+		t.Errorf("broken repository = %d, want 500", code)
+	}
+}
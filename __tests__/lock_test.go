@@ -0,0 +1,51 @@
+package gittag
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/afeiship/gittag"
+	"github.com/afeiship/gittag/gittagtest"
+)
+
+func TestMutationsWaitForRepositoryLock(t *testing.T) {
+	if _, err := exec.LookPath("flock"); err != nil {
+		t.Skip("flock(1) not available")
+	}
+	fixture := gittagtest.NewRepo(t)
+	repo, err := gittag.Open(fixture.Dir, gittag.WithLockTimeout(300*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	holder := exec.Command("flock", filepath.Join(fixture.Dir, ".git", "gittag.lock"), "sleep", "2")
+	if err := holder.Start(); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(200 * time.Millisecond)
+
+	err = repo.CreateLocal("v1.0.0")
+	if err == nil || !strings.Contains(err.Error(), "gittag.lock") {
+		t.Fatalf("CreateLocal error = %v, want lock timeout", err)
+	}
+	if tags := fixture.Tags(); len(tags) != 0 {
+		t.Errorf("tag created while lock was held: %v", tags)
+	}
+	// 冻结和解冻也修改仓库，同样需要等待锁
+	for name, op := range map[string]func(string) error{"Freeze": repo.Freeze, "Unfreeze": repo.Unfreeze} {
+		if err := op("v0.*"); err == nil || !strings.Contains(err.Error(), "gittag.lock") {
+			t.Errorf("%s error = %v, want lock timeout", name, err)
+		}
+	}
+	if frozen, err := repo.Frozen(); err != nil || len(frozen) != 0 {
+		t.Errorf("series frozen while lock was held: %v, %v", frozen, err)
+	}
+
+	holder.Wait()
+	if err := repo.CreateLocal("v1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,304 @@
+package gittag
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/afeiship/gittag"
+	"github.com/afeiship/gittag/gittagtest"
+)
+
+func TestReleaseResume(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithCommits(2), gittagtest.WithRemote())
+	repo := fixture.Open()
+	ctx := context.Background()
+	head := fixture.Git("rev-parse", "HEAD")
+
+	var published, notified int
+	failPublish := gittag.WithPublish(func(ctx context.Context, s gittag.ReleaseState) error {
+		return errors.New("release API unavailable")
+	})
+	publish := gittag.WithPublish(func(ctx context.Context, s gittag.ReleaseState) error {
+		published++
+		return nil
+	})
+	notify := gittag.WithNotify(func(ctx context.Context, s gittag.ReleaseState) error {
+		notified++
+		return nil
+	})
+
+	state, err := repo.Release(ctx, "run-1", "v1.0.0", failPublish, notify)
+	if err == nil || state.Step != gittag.ReleasePushed || state.Commit != head {
+		t.Fatalf("Release = %+v, %v; want to stop after push", state, err)
+	}
+	if _, err := repo.Release(ctx, "run-1", "v1.0.0"); err == nil {
+		t.Error("Release with an existing id should fail")
+	}
+
+	fixture.Commit("moved on")
+	state, err = repo.ResumeRelease(ctx, "run-1", publish, notify)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !state.Done() || published != 1 || notified != 1 {
+		t.Errorf("ResumeRelease = %+v, published %d, notified %d", state, published, notified)
+	}
+	if got := fixture.RemoteGit("rev-parse", "v1.0.0^{commit}"); got != head {
+		t.Errorf("remote v1.0.0 = %s, want original HEAD %s", got, head)
+	}
+
+	if _, err := repo.ResumeRelease(ctx, "run-1", publish, notify); err != nil || published != 1 {
+		t.Errorf("resuming a finished release = %v, published %d", err, published)
+	}
+}
+
+func TestReleasePlanMarkdown(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0"))
+	fixture.Commit("feat: add FindLatest")
+	fixture.Commit("fix: handle empty patterns")
+	fixture.Git("tag", "v1.1.0-rc.1", "HEAD~1")
+
+	plan, err := fixture.Open().ReleasePlanMarkdown("v1.1.0",
+		gittag.WithCreateOptions(gittag.WithMessage("Release 1.1.0\n\nHighlights: FindLatest")),
+		gittag.WithPublish(func(ctx context.Context, s gittag.ReleaseState) error { return nil }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gittagtest.Golden(t, "testdata/release_plan.golden", plan)
+}
+
+func TestReleaseSyncsVersionFiles(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithRemote())
+	files := map[string]string{
+		"VERSION":      "v1.0.0\n",
+		"package.json": "{\n  \"name\": \"app\",\n  \"version\": \"1.0.0\"\n}\n",
+		"version.go":   "package app\n\nconst Version = \"1.0.0\"\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(fixture.Dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fixture.Git("add", ".")
+	fixture.Git("commit", "--quiet", "-m", "add version files")
+
+	state, err := fixture.Open().Release(context.Background(), "run-2", "v1.1.0", gittag.WithVersionFiles(
+		gittag.PlainVersionFile("VERSION"),
+		gittag.PackageJSONVersion("package.json"),
+		gittag.GoVersionConst("version.go", "Version"),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head := fixture.Git("rev-parse", "HEAD"); state.Commit != head || fixture.Git("rev-parse", "v1.1.0^{commit}") != head {
+		t.Errorf("tag not on the version commit: state %s, HEAD %s", state.Commit, head)
+	}
+	want := map[string]string{
+		"VERSION":      "v1.1.0",
+		"package.json": "{\n  \"name\": \"app\",\n  \"version\": \"1.1.0\"\n}",
+		"version.go":   "package app\n\nconst Version = \"1.1.0\"",
+	}
+	for name, content := range want {
+		if got := fixture.Git("show", "v1.1.0:"+name); got != content {
+			t.Errorf("%s at v1.1.0 = %q, want %q", name, got, content)
+		}
+	}
+}
+
+func TestReleaseOrder(t *testing.T) {
+	fixture := gittagtest.NewRepo(t)
+	mods := map[string]string{
+		"libs/log":          "module example.com/libs/log\n",
+		"libs/money":        "module example.com/libs/money\n\nrequire example.com/libs/log v1.0.0\n",
+		"services/payments": "module example.com/payments\n\nrequire (\n\texample.com/libs/money v1.2.0\n\texample.com/libs/log v1.0.0\n\tgolang.org/x/sys v0.4.0\n)\n",
+		"services/ledger":   "module example.com/ledger\n\nrequire example.com/libs/log v1.0.0\n",
+	}
+	for dir, content := range mods {
+		if err := os.MkdirAll(filepath.Join(fixture.Dir, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(fixture.Dir, dir, "go.mod"), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fixture.Git("add", ".")
+	fixture.Commit("add modules")
+	repo := fixture.Open()
+
+	batches, err := repo.ReleaseOrder([]string{"services/payments", "services/ledger", "libs/money", "libs/log/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"libs/log"}, {"libs/money", "services/ledger"}, {"services/payments"}}
+	if !reflect.DeepEqual(batches, want) {
+		t.Errorf("ReleaseOrder = %v, want %v", batches, want)
+	}
+
+	if err := os.WriteFile(filepath.Join(fixture.Dir, "libs/log/go.mod"), []byte("module example.com/libs/log\n\nrequire example.com/libs/money v1.2.0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fixture.Git("add", ".")
+	fixture.Commit("introduce cycle")
+	if _, err := repo.ReleaseOrder([]string{"libs/money", "libs/log"}); err == nil {
+		t.Error("ReleaseOrder with a cycle succeeded")
+	}
+}
+
+func TestWithStore(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithRemote())
+	store := gittag.NewMemoryStore()
+	repo, err := gittag.Open(fixture.Dir, gittag.WithStore(gittag.PrefixStore(store, "repos/app")), gittag.WithCache())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.Release(context.Background(), "run-1", "v1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.WithIdempotencyKey("delivery-1").CreateTag("v1.1.0"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.ListInfo("v*"); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"repos/app/cache/taginfo.json", "repos/app/idempotency.json", "repos/app/releases/run-1.json"}
+	if got := store.Keys(); !reflect.DeepEqual(got, want) {
+		t.Errorf("store keys = %v, want %v", got, want)
+	}
+	if _, err := os.Stat(filepath.Join(fixture.Dir, ".git", "gittag")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf(".git/gittag exists (%v), state should only be in the store", err)
+	}
+
+	// 状态只在 Store 中：同一个 Store 可以继续发布，换一个 Store 则看不到
+	if _, err := repo.ResumeRelease(context.Background(), "run-1"); err != nil {
+		t.Errorf("ResumeRelease with the same store = %v", err)
+	}
+	other, err := gittag.Open(fixture.Dir, gittag.WithStore(gittag.NewMemoryStore()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.ResumeRelease(context.Background(), "run-1"); err == nil {
+		t.Error("ResumeRelease with another store found the release")
+	}
+
+	if err := repo.ClearCache(); err != nil {
+		t.Fatal(err)
+	}
+	if got := store.Keys(); len(got) != 2 {
+		t.Errorf("store keys after ClearCache = %v", got)
+	}
+}
+
+func TestActor(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithCommits(1), gittagtest.WithRemote())
+	bot := gittag.Actor{Name: "Release Bot", Email: "bot@example.com", ID: "svc-release"}
+	repo, err := gittag.Open(fixture.Dir, gittag.WithActor(bot))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.CreateLocal("v1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	if got := fixture.Git("for-each-ref", "--format=%(taggername) %(taggeremail)", "refs/tags/v1.0.0"); got != "Release Bot <bot@example.com>" {
+		t.Errorf("tagger = %q", got)
+	}
+	if got, err := repo.TagActor("v1.0.0"); err != nil || got == nil || *got != bot {
+		t.Errorf("TagActor = %v, %v; want %v", got, err, bot)
+	}
+
+	// 上下文中的操作者优先于 WithActor
+	alice := gittag.Actor{Name: "Alice", Email: "alice@example.com", ID: "u-42"}
+	ctx := gittag.ContextWithActor(context.Background(), alice)
+	var notified *gittag.Actor
+	notify := gittag.WithNotify(func(ctx context.Context, s gittag.ReleaseState) error {
+		notified = s.Actor
+		return nil
+	})
+	state, err := repo.Release(ctx, "run-1", "v1.1.0", notify)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Actor == nil || *state.Actor != alice || notified == nil || *notified != alice {
+		t.Errorf("release actor = %v, notified %v; want %v", state.Actor, notified, alice)
+	}
+	if got, err := repo.TagActor("v1.1.0"); err != nil || got == nil || *got != alice {
+		t.Errorf("TagActor(v1.1.0) = %v, %v; want %v", got, err, alice)
+	}
+
+	fixture.Git("tag", "v0.9.0", "HEAD")
+	if got, err := repo.TagActor("v0.9.0"); err != nil || got != nil {
+		t.Errorf("TagActor of a tag created outside gittag = %v, %v", got, err)
+	}
+
+	// 轻量标签不记录操作者，避免 note 挂在提交上被其他标签共享
+	if err := repo.Create("v1.1.1-rc", gittag.WithLightweight()); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := repo.TagActor("v1.1.1-rc"); err != nil || got != nil {
+		t.Errorf("TagActor of a lightweight tag = %v, %v", got, err)
+	}
+	if notes := fixture.Git("notes", "--ref=refs/notes/gittag/actors", "list"); strings.Contains(notes, fixture.Git("rev-parse", "HEAD")) {
+		t.Errorf("lightweight tag left a note on the commit:\n%s", notes)
+	}
+
+	// 记录操作者失败不影响创建和推送
+	notesRef := filepath.Join(fixture.Dir, ".git", "refs", "notes", "gittag", "actors")
+	if err := os.Remove(notesRef); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(notesRef, "blocked"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Create("v1.2.0", gittag.WithPush()); err != nil {
+		t.Fatalf("Create with an unwritable notes ref = %v", err)
+	}
+	if !slices.Contains(fixture.RemoteTags(), "v1.2.0") {
+		t.Errorf("v1.2.0 was not pushed: %v", fixture.RemoteTags())
+	}
+
+	// 幂等记录中保存完成操作的操作者
+	if _, err := repo.WithIdempotencyKey("run-2").Release(ctx, "run-2", "v1.3.0"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(fixture.Dir, ".git", "gittag", "idempotency.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var journal map[string]struct{ Actor *gittag.Actor }
+	if err := json.Unmarshal(data, &journal); err != nil || journal["run-2"].Actor == nil || *journal["run-2"].Actor != alice {
+		t.Errorf("idempotency journal = %s (%v); want actor %v", data, err, alice)
+	}
+}
+
+func TestReleaseCancel(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithCommits(1), gittagtest.WithRemote())
+	// 远程在接收推送时挂起
+	hook := filepath.Join(fixture.RemoteDir, "hooks", "pre-receive")
+	if err := os.WriteFile(hook, []byte("#!/bin/sh\nsleep 30\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	repo := fixture.Open()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	state, err := repo.Release(ctx, "run-1", "v1.0.0")
+	if err == nil {
+		t.Fatal("Release with a hung push succeeded")
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("Release returned %s after ctx was cancelled", elapsed)
+	}
+	if state == nil || state.Step != gittag.ReleaseTagged || state.Error == "" {
+		t.Errorf("state after cancel = %+v; want a failed pushed step", state)
+	}
+}
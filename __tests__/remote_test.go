@@ -0,0 +1,740 @@
+package gittag
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/cgi"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/afeiship/gittag"
+	"github.com/afeiship/gittag/gittagtest"
+)
+
+func TestCreateTagPushesToOrigin(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0"), gittagtest.WithRemote())
+
+	if err := fixture.Open().CreateTag("v1.1.0", "feature release"); err != nil {
+		t.Fatal(err)
+	}
+	gittagtest.Golden(t, "testdata/create_tag.golden", fixture.State())
+}
+
+func TestDeleteTagRemovesFromOrigin(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0", "v1.1.0"), gittagtest.WithRemote())
+
+	if err := fixture.Open().DeleteTag("v1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	gittagtest.Golden(t, "testdata/delete_tag.golden", fixture.State())
+}
+
+func TestDeleteMissingTag(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0"), gittagtest.WithRemote())
+	fixture.RemoteGit("tag", "v9.0.0", "HEAD")
+	repo := fixture.Open()
+
+	var notFound *gittag.NotFoundError
+	if err := repo.DeleteLocal("v9.0.0"); !errors.Is(err, gittag.ErrTagNotFound) || !errors.As(err, &notFound) || notFound.Pattern != "v9.0.0" {
+		t.Errorf("DeleteLocal(missing) = %v, want *NotFoundError", err)
+	}
+	// 本地标签不存在时不删除远程标签
+	if err := repo.DeleteTag("v9.0.0"); !errors.Is(err, gittag.ErrTagNotFound) {
+		t.Errorf("DeleteTag(missing) = %v, want ErrTagNotFound", err)
+	}
+	if got := fixture.RemoteTags(); !reflect.DeepEqual(got, []string{"v1.0.0", "v9.0.0"}) {
+		t.Errorf("remote tags = %v, want [v1.0.0 v9.0.0]", got)
+	}
+}
+
+func TestDeleteRemoteByPattern(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0", "v1.1.0-rc.1"), gittagtest.WithRemote())
+	fixture.RemoteGit("tag", "v1.2.0-rc.1", "HEAD")
+	// 与 FindMany 一样，* 可以匹配 "/"
+	fixture.RemoteGit("tag", "release/v2.0.0-rc.1", "HEAD")
+	fixture.Git("tag", "-d", "v1.1.0-rc.1")
+
+	if err := fixture.Open().DeleteRemoteByPattern("*-rc.*"); err != nil {
+		t.Fatal(err)
+	}
+	if got := fixture.RemoteTags(); !reflect.DeepEqual(got, []string{"v1.0.0"}) {
+		t.Errorf("remote tags = %v, want [v1.0.0]", got)
+	}
+}
+
+func TestPushTagRef(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithCommits(2), gittagtest.WithRemote())
+	first := fixture.Git("rev-parse", "HEAD~1")
+	repo := fixture.Open()
+
+	if err := repo.PushTagRef("v1.0.0", first[:10]); err != nil {
+		t.Fatal(err)
+	}
+	if got := fixture.RemoteGit("rev-parse", "v1.0.0"); got != first {
+		t.Errorf("remote v1.0.0 = %s, want %s", got, first)
+	}
+	if len(fixture.Tags()) != 0 {
+		t.Errorf("local tags = %v, want none", fixture.Tags())
+	}
+	if err := repo.PushTagRef("v1.0.0", "HEAD"); err == nil {
+		t.Error("PushTagRef should not overwrite an existing remote tag")
+	}
+}
+
+func TestCloneTempSparse(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithRemote())
+	for _, name := range []string{"docs/index.md", "src/main.go", "README.md"} {
+		path := filepath.Join(fixture.Dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fixture.Git("add", ".")
+	fixture.Commit("add files")
+	fixture.Git("push", "--quiet", "origin", "HEAD")
+
+	ctx := context.Background()
+	if _, _, err := gittag.CloneTemp(ctx, fixture.RemoteDir, gittag.WithTagsOnly(), gittag.WithSparse("docs")); err == nil {
+		t.Error("WithSparse combined with WithTagsOnly succeeded")
+	}
+	repo, cleanup, err := gittag.CloneTemp(ctx, fixture.RemoteDir, gittag.WithSkipLFS(), gittag.WithSparse("docs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	for name, want := range map[string]bool{"docs/index.md": true, "README.md": true, "src/main.go": false} {
+		_, err := os.Stat(filepath.Join(repo.Dir(), name))
+		if got := err == nil; got != want {
+			t.Errorf("%s checked out = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestCheckoutTagSparse(t *testing.T) {
+	fixture := gittagtest.NewRepo(t)
+	for _, name := range []string{"deploy/chart.yaml", "src/main.go"} {
+		path := filepath.Join(fixture.Dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fixture.Git("add", ".")
+	fixture.Commit("add files")
+	fixture.Tag("v1.0.0")
+	fixture.Git("rm", "--quiet", "-r", "deploy")
+	fixture.Commit("drop deploy")
+	repo := fixture.Open()
+
+	dest := filepath.Join(t.TempDir(), "wt")
+	if err := repo.CheckoutTagSparse("v1.0.0", []string{"deploy"}, dest); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]bool{"deploy/chart.yaml": true, "src/main.go": false} {
+		_, err := os.Stat(filepath.Join(dest, name))
+		if got := err == nil; got != want {
+			t.Errorf("%s checked out = %v, want %v", name, got, want)
+		}
+	}
+	if status := fixture.Git("status", "--porcelain"); status != "" {
+		t.Errorf("main worktree changed: %q", status)
+	}
+	if err := repo.CheckoutTagSparse("v9.9.9", []string{"deploy"}, filepath.Join(t.TempDir(), "wt")); err == nil {
+		t.Error("CheckoutTagSparse(v9.9.9) succeeded")
+	}
+}
+
+func TestPushVerification(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0"), gittagtest.WithRemote())
+	repo, err := gittag.Open(fixture.Dir, gittag.WithPushVerification(300*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.CreateTag("v1.1.0"); err != nil {
+		t.Fatal(err)
+	}
+
+	// 模拟服务器端钩子在接收后把标签改写到其他提交
+	hook := "#!/bin/sh\nwhile read old new ref; do git update-ref \"$ref\" v1.0.0^{commit}; done\n"
+	if err := os.WriteFile(filepath.Join(fixture.RemoteDir, "hooks", "post-receive"), []byte(hook), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	fixture.Commit("next")
+	if err := repo.CreateTag("v1.2.0"); !errors.Is(err, gittag.ErrPushUnverified) {
+		t.Errorf("CreateTag with rewritten remote = %v, want ErrPushUnverified", err)
+	}
+}
+
+func TestPushToRemotes(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0"), gittagtest.WithRemote(), gittagtest.WithMirror("mirror"))
+	broken := filepath.Join(t.TempDir(), "missing.git")
+	repo := fixture.Open()
+	ctx := context.Background()
+
+	res, err := repo.PushToRemotes(ctx, "v1.0.0", []string{"origin", "mirror", broken}, gittag.WithConvergeRetries(1, time.Millisecond))
+	if !errors.Is(err, gittag.ErrQuorumNotReached) {
+		t.Errorf("RequireAll with a broken remote = %v, want ErrQuorumNotReached", err)
+	}
+	if want := []string{"mirror", "origin"}; !reflect.DeepEqual(res.Succeeded, want) || res.Failed[broken] == nil {
+		t.Errorf("result = %+v", res)
+	}
+	if tags := fixture.MirrorTags("mirror"); !reflect.DeepEqual(tags, []string{"v1.0.0"}) {
+		t.Errorf("mirror tags = %v", tags)
+	}
+
+	if _, err := repo.PushToRemotes(ctx, "v1.0.0", []string{"origin", "mirror", broken}, gittag.WithSuccessPolicy(gittag.RequireQuorum(2))); err != nil {
+		t.Errorf("RequireQuorum(2): %v", err)
+	}
+	if _, err := repo.PushToRemotes(ctx, "v1.0.0", []string{broken}, gittag.WithSuccessPolicy(gittag.BestEffort())); err != nil {
+		t.Errorf("BestEffort: %v", err)
+	}
+
+	// 重复的远程只推送一次，RequireAll 按去重后的数量计算
+	res, err = repo.PushToRemotes(ctx, "v1.0.0", []string{"origin", "mirror", "origin"})
+	if err != nil || !reflect.DeepEqual(res.Succeeded, []string{"mirror", "origin"}) {
+		t.Errorf("duplicate remotes = %+v, %v", res, err)
+	}
+
+	// 新标签同时推送到 origin 和镜像，失败的远程不影响其他远程
+	fixture.Commit("next")
+	if err := repo.CreateLocal("v1.1.0"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.PushToRemotes(ctx, "v1.1.0", []string{"origin", "mirror", broken}, gittag.WithSuccessPolicy(gittag.BestEffort())); err != nil {
+		t.Fatal(err)
+	}
+	gittagtest.Golden(t, "testdata/push_to_remotes.golden", fixture.State())
+}
+
+func TestSyncStatusGolden(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0", "v1.1.0"), gittagtest.WithRemote())
+	repo := fixture.Open()
+
+	fixture.Commit("unreleased")
+	fixture.Tag("v1.2.0")
+	fixture.RemoteGit("tag", "v0.9.0", "v1.0.0^{commit}")
+	fixture.Git("tag", "-f", "-a", "v1.1.0", "-m", "moved v1.1.0")
+
+	status, err := repo.SyncStatus("v*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gittagtest.Golden(t, "testdata/sync_status.golden", fixture.State()+"[status]\n"+status.String()+"\n")
+
+	if err := repo.CreateRemote("v1.2.0"); err != nil {
+		t.Fatal(err)
+	}
+	if status, err = repo.SyncStatus("v1.2*"); err != nil || !status.InSync() {
+		t.Errorf("SyncStatus after CreateRemote = %v, %v; want in sync", status, err)
+	}
+}
+
+func TestWithVerbose(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0"), gittagtest.WithRemote())
+	var out bytes.Buffer
+	repo, err := gittag.Open(fixture.Dir, gittag.WithVerbose(&out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.CreateTag("v1.1.0"); err != nil {
+		t.Fatal(err)
+	}
+
+	// push 命令行之后应当紧跟着它的输出
+	var pushed, inPush bool
+	for _, line := range strings.Split(out.String(), "\n") {
+		if strings.HasPrefix(line, "$ ") {
+			inPush = strings.HasPrefix(line, "$ git ") && strings.Contains(line, " push ")
+			continue
+		}
+		pushed = pushed || inPush && strings.Contains(line, "[new tag]")
+	}
+	if !pushed {
+		t.Errorf("verbose output has no push command followed by its output:\n%s", out.String())
+	}
+}
+
+func TestDoctor(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0"), gittagtest.WithRemote())
+	findings, err := fixture.Open().Doctor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	checks := map[string]gittag.Severity{}
+	for _, f := range findings {
+		checks[f.Check] = f.Severity
+		if f.Severity != gittag.SeverityOK {
+			t.Errorf("unexpected finding on a healthy repository: %s", f)
+		}
+	}
+	for _, check := range []string{"git", "repository", "identity", "remote", "credentials", "signing", "shallow", "head"} {
+		if _, ok := checks[check]; !ok {
+			t.Errorf("no %s finding in %v", check, findings)
+		}
+	}
+	if remote := fixture.RemoteTags(); !reflect.DeepEqual(remote, []string{"v1.0.0"}) {
+		t.Errorf("remote tags after Doctor = %v, want [v1.0.0]", remote)
+	}
+
+	fixture.Git("remote", "set-url", "origin", filepath.Join(t.TempDir(), "missing.git"))
+	fixture.Git("checkout", "--quiet", "--detach")
+	findings, err = fixture.Open().Doctor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	checks = map[string]gittag.Severity{}
+	for _, f := range findings {
+		checks[f.Check] = f.Severity
+	}
+	want := map[string]gittag.Severity{
+		"git":        gittag.SeverityOK,
+		"repository": gittag.SeverityOK,
+		"identity":   gittag.SeverityOK,
+		"remote":     gittag.SeverityError,
+		"signing":    gittag.SeverityOK,
+		"shallow":    gittag.SeverityOK,
+		"head":       gittag.SeverityWarning,
+	}
+	if !reflect.DeepEqual(checks, want) {
+		t.Errorf("Doctor on broken repository = %v, want %v", findings, want)
+	}
+}
+
+func TestPingRemote(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0"), gittagtest.WithRemote())
+	if err := fixture.Open().PingRemote("origin"); err != nil {
+		t.Fatal(err)
+	}
+
+	fixture.Git("remote", "set-url", "origin", filepath.Join(t.TempDir(), "missing.git"))
+	repo, err := gittag.Open(fixture.Dir, gittag.WithRemotePreflight())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.PingRemote("origin"); err == nil {
+		t.Fatal("PingRemote on a missing remote succeeded")
+	}
+	if err := repo.CreateTag("v1.1.0"); err == nil || !strings.Contains(err.Error(), "origin") {
+		t.Errorf("CreateTag with unreachable origin = %v, want preflight error", err)
+	}
+	if tags := fixture.Tags(); !reflect.DeepEqual(tags, []string{"v1.0.0"}) {
+		t.Errorf("local tags = %v, preflight should fail before tagging", tags)
+	}
+	if err := repo.CreateLocal("v1.1.0"); err != nil {
+		t.Errorf("CreateLocal should not ping the remote: %v", err)
+	}
+}
+
+func TestWithRemote(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0"), gittagtest.WithRemote())
+	fixture.Git("remote", "rename", "origin", "upstream")
+	repo, err := gittag.Open(fixture.Dir, gittag.WithRemote("upstream"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.CreateTag("v1.1.0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.DeleteRemote("v1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	if got := fixture.RemoteTags(); !reflect.DeepEqual(got, []string{"v1.1.0"}) {
+		t.Errorf("upstream tags = %v, want [v1.1.0]", got)
+	}
+	fixture.RemoteGit("tag", "v1.2.0", "v1.1.0^{commit}")
+	if err := repo.FetchTags(); err != nil {
+		t.Fatal(err)
+	}
+	if got := fixture.Tags(); !reflect.DeepEqual(got, []string{"v1.0.0", "v1.1.0", "v1.2.0"}) {
+		t.Errorf("local tags after FetchTags = %v", got)
+	}
+
+	if err := fixture.Open().CreateRemote("v1.0.0"); err == nil {
+		t.Error("pushing to the missing origin remote succeeded")
+	}
+	if _, err := gittag.Open(fixture.Dir, gittag.WithRemote("--upload-pack=evil")); err == nil {
+		t.Error("Open accepted a remote name starting with \"-\"")
+	}
+}
+
+func TestBump(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithRemote())
+	repo := fixture.Open()
+
+	if tag, err := repo.BumpPatch(); err != nil || tag != "v0.0.1" {
+		t.Fatalf("first BumpPatch = %q, %v, want v0.0.1", tag, err)
+	}
+	fixture.Commit("feature")
+	fixture.Tag("v1.2.3")
+	fixture.Tag("v1.10.0-rc.1")
+	fixture.Tag("v1.9.9")
+
+	for _, step := range []struct {
+		bump func() (string, error)
+		want string
+	}{
+		{repo.BumpPatch, "v1.9.10"},
+		{repo.BumpMinor, "v1.10.0"},
+		{repo.BumpMajor, "v2.0.0"},
+		{repo.BumpPatch, "v2.0.1"},
+	} {
+		fixture.Commit("change")
+		if tag, err := step.bump(); err != nil || tag != step.want {
+			t.Errorf("bump = %q, %v, want %s", tag, err, step.want)
+		}
+	}
+	if got := fixture.RemoteTags(); !reflect.DeepEqual(got, []string{"v0.0.1", "v1.10.0", "v1.9.10", "v2.0.0", "v2.0.1"}) {
+		t.Errorf("remote tags = %v", got)
+	}
+
+	// 旧写法的标签经 Normalize 规范化后参与比较，v3.1 视为 v3.1.0
+	fixture.Commit("legacy")
+	fixture.Tag("v3.1")
+	fixture.Tag("v03.0.9")
+	if tag, err := repo.BumpPatch(); err != nil || tag != "v3.1.1" {
+		t.Errorf("BumpPatch after legacy tags = %q, %v, want v3.1.1", tag, err)
+	}
+}
+
+func TestIdempotencyKey(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithRemote())
+	repo := fixture.Open()
+
+	if err := repo.WithIdempotencyKey("delivery-1").CreateTag("v1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	// 重新投递的同一请求不会再次创建标签，也不会因标签已存在而失败
+	if err := repo.WithIdempotencyKey("delivery-1").CreateTag("v1.0.0"); err != nil {
+		t.Errorf("duplicate CreateTag = %v, want nil", err)
+	}
+	if err := repo.WithIdempotencyKey("delivery-2").DeleteTag("v1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.WithIdempotencyKey("delivery-1").CreateTag("v1.0.0"); err != nil {
+		t.Errorf("duplicate CreateTag after delete = %v, want nil", err)
+	}
+	if local, remote := fixture.Tags(), fixture.RemoteTags(); len(local) != 0 || len(remote) != 0 {
+		t.Errorf("tags = %v / %v, the deleted tag was recreated", local, remote)
+	}
+
+	// 失败的操作不记录幂等键，可以重试
+	if err := repo.WithIdempotencyKey("delivery-3").CreateTag("bad..name"); err == nil {
+		t.Fatal("CreateTag with an invalid name succeeded")
+	}
+	if err := repo.WithIdempotencyKey("delivery-3").CreateTag("v1.1.0"); err != nil {
+		t.Fatal(err)
+	}
+	if got := fixture.RemoteTags(); !reflect.DeepEqual(got, []string{"v1.1.0"}) {
+		t.Errorf("remote tags = %v, want [v1.1.0]", got)
+	}
+
+	// 无法解析的幂等记录报告为损坏，而不是被当作空记录
+	journal := filepath.Join(fixture.Dir, ".git", "gittag", "idempotency.json")
+	if err := os.WriteFile(journal, []byte(`{"delivery-4":"2024-01-01T00:00:00Z"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.WithIdempotencyKey("delivery-4").CreateTag("v1.2.0"); err == nil || !strings.Contains(err.Error(), "幂等记录已损坏") {
+		t.Errorf("CreateTag with a corrupted journal = %v, want an error", err)
+	}
+}
+
+func TestIdempotencyKeyOperations(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithCommits(1), gittagtest.WithRemote())
+	repo := fixture.Open()
+	ctx := context.Background()
+
+	// 幂等键作用于整个发布：打标签之后的推送步骤不能被当作重放跳过
+	state, err := repo.WithIdempotencyKey("job-1").Release(ctx, "r1", "v1.0.0")
+	if err != nil || !state.Done() {
+		t.Fatalf("Release = %+v, %v", state, err)
+	}
+	if got := fixture.RemoteTags(); !reflect.DeepEqual(got, []string{"v1.0.0"}) {
+		t.Errorf("remote tags after Release = %v", got)
+	}
+	replayed, err := repo.WithIdempotencyKey("job-1").Release(ctx, "r1", "v1.0.0")
+	if err != nil || replayed.Tag != "v1.0.0" || !replayed.Done() {
+		t.Errorf("replayed Release = %+v, %v", replayed, err)
+	}
+
+	// 重放返回第一次的结果
+	fixture.Commit("fix")
+	first, err := repo.WithIdempotencyKey("job-2").BumpPatch()
+	if err != nil || first != "v1.0.1" {
+		t.Fatalf("BumpPatch = %q, %v", first, err)
+	}
+	fixture.Commit("another fix")
+	if again, err := repo.WithIdempotencyKey("job-2").BumpPatch(); err != nil || again != first {
+		t.Errorf("replayed BumpPatch = %q, %v; want %q", again, err, first)
+	}
+	if _, err := repo.WithIdempotencyKey("job-2").BumpMinor(); err == nil {
+		t.Error("a completed key was accepted for a different operation")
+	}
+}
+
+func TestCreateForce(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithRemote())
+	repo := fixture.Open()
+
+	if err := repo.Create("nightly", gittag.WithForce(), gittag.WithPush()); err != nil {
+		t.Fatal(err)
+	}
+	fixture.Commit("next")
+	fixture.Git("push", "--quiet", "origin", "HEAD")
+	if err := repo.Create("nightly", gittag.WithPush()); err == nil {
+		t.Fatal("moving a tag without WithForce succeeded")
+	}
+	if err := repo.Create("nightly", gittag.WithForce(), gittag.WithPush(), gittag.WithMessage("nightly build")); err != nil {
+		t.Fatal(err)
+	}
+	head := fixture.Git("rev-parse", "HEAD")
+	if local, remote := fixture.Git("rev-parse", "nightly^{commit}"), fixture.RemoteGit("rev-parse", "nightly^{commit}"); local != head || remote != head {
+		t.Errorf("nightly = %s local, %s remote; want HEAD %s", local, remote, head)
+	}
+
+	if err := repo.Create("ci/latest", gittag.WithLightweight(), gittag.WithForce(), gittag.WithTarget("HEAD~1")); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Create("ci/latest", gittag.WithLightweight(), gittag.WithForce()); err != nil {
+		t.Fatal(err)
+	}
+	if got := fixture.Git("rev-parse", "ci/latest"); got != head {
+		t.Errorf("ci/latest = %s, want %s", got, head)
+	}
+
+	if err := repo.Freeze("nightly"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Create("nightly", gittag.WithForce(), gittag.WithTarget("HEAD~1")); !errors.Is(err, gittag.ErrSeriesFrozen) {
+		t.Errorf("force-moving a frozen tag = %v, want ErrSeriesFrozen", err)
+	}
+}
+
+func TestWithDryRun(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0", "v1.1.0"), gittagtest.WithRemote())
+	fixture.Git("push", "--quiet", "origin", "--tags")
+	var out strings.Builder
+	repo, err := gittag.Open(fixture.Dir, gittag.WithDryRun(&out), gittag.WithPushVerification(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.DeleteAllTags(); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.WithIdempotencyKey("run-1").CreateTag("v1.2.0"); err != nil {
+		t.Fatal(err)
+	}
+	if tags, err := repo.FindMany("v*"); err != nil || len(tags) != 2 {
+		t.Errorf("FindMany = %v, %v; read-only commands should still run", tags, err)
+	}
+	if local, remote := fixture.Tags(), fixture.RemoteTags(); len(local) != 2 || len(remote) != 2 {
+		t.Errorf("dry run changed tags: local %v, remote %v", local, remote)
+	}
+	if _, err := os.Stat(filepath.Join(fixture.Dir, ".git", "gittag", "idempotency.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("dry run recorded the idempotency key: %v", err)
+	}
+
+	log := out.String()
+	for _, want := range []string{
+		"[dry-run] git tag -d -- v1.0.0\n",
+		"[dry-run] git push origin --delete refs/tags/v1.1.0\n",
+		"[dry-run] git tag -a --cleanup=verbatim -F - -- v1.2.0\n",
+		"[dry-run] git push origin refs/tags/v1.2.0\n",
+	} {
+		if !strings.Contains(log, want) {
+			t.Errorf("dry-run output is missing %q:\n%s", want, log)
+		}
+	}
+	if strings.Contains(log, "for-each-ref") || strings.Contains(log, "tag -l") {
+		t.Errorf("read-only commands were logged:\n%s", log)
+	}
+
+	// 钩子命令、版本文件和发布回调在 dry-run 下只输出不执行
+	if err := os.WriteFile(filepath.Join(fixture.Dir, "VERSION"), []byte("v1.1.0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fixture.Git("add", "VERSION")
+	fixture.Git("commit", "--quiet", "-m", "add VERSION")
+	out.Reset()
+	var hooks []string
+	hook := func(name string) gittag.ReleaseHook {
+		return func(context.Context, gittag.ReleaseState) error {
+			hooks = append(hooks, name)
+			return nil
+		}
+	}
+	state, err := repo.Release(context.Background(), "run-1", "v1.3.0",
+		gittag.WithCreateOptions(gittag.WithPreTagCommand("touch hooked")),
+		gittag.WithVersionFiles(gittag.PlainVersionFile("VERSION")),
+		gittag.WithPublish(hook("publish")), gittag.WithNotify(hook("notify")))
+	if err != nil || !state.Done() {
+		t.Fatalf("dry-run Release = %+v, %v", state, err)
+	}
+	if len(hooks) != 0 {
+		t.Errorf("dry-run Release ran hooks %v", hooks)
+	}
+	if _, err := os.Stat(filepath.Join(fixture.Dir, "hooked")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("dry-run Release ran the pre-tag command: %v", err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(fixture.Dir, "VERSION")); string(got) != "v1.1.0\n" {
+		t.Errorf("dry-run Release wrote VERSION = %q", got)
+	}
+	log = out.String()
+	for _, want := range []string{
+		"[dry-run] write VERSION\n",
+		"[dry-run] sh -c 'touch hooked'\n",
+		"[dry-run] publish v1.3.0\n",
+		"[dry-run] notify v1.3.0\n",
+	} {
+		if !strings.Contains(log, want) {
+			t.Errorf("dry-run Release output is missing %q:\n%s", want, log)
+		}
+	}
+}
+
+func TestWithURLRewrite(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0"), gittagtest.WithRemote())
+	fixture.Git("remote", "set-url", "origin", "https://git.example.invalid/remote.git")
+	mirror := filepath.Dir(fixture.RemoteDir) + string(filepath.Separator)
+
+	repo, err := gittag.Open(fixture.Dir, gittag.WithURLRewrite("https://git.example.invalid/", mirror))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.CreateRemote("v1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	if got := fixture.RemoteTags(); !reflect.DeepEqual(got, []string{"v1.0.0"}) {
+		t.Errorf("remote tags = %v", got)
+	}
+	if got := fixture.Git("config", "--local", "--list"); strings.Contains(got, "insteadof") {
+		t.Errorf("WithURLRewrite changed the repository config:\n%s", got)
+	}
+	if err := fixture.Open().CreateRemote("v1.0.0"); err == nil {
+		t.Error("push without the rewrite reached the unreachable URL")
+	}
+}
+
+func TestWithHTTPSFallback(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0"), gittagtest.WithRemote())
+	fixture.RemoteGit("config", "http.receivepack", "true")
+	execPath, err := exec.Command("git", "--exec-path").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend := &cgi.Handler{
+		Path: filepath.Join(strings.TrimSpace(string(execPath)), "git-http-backend"),
+		Env:  []string{"GIT_PROJECT_ROOT=" + filepath.Dir(fixture.RemoteDir), "GIT_HTTP_EXPORT_ALL=1"},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if user, token, ok := req.BasicAuth(); !ok || user != "x-access-token" || token != "s3cret" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="git"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		backend.ServeHTTP(w, req)
+	}))
+	defer server.Close()
+
+	// 模拟 SSH 被拦截：每次连接都失败
+	fixture.Git("remote", "set-url", "origin", "git@git.example.invalid:acme/remote.git")
+	var trace gittag.Trace
+	repo, err := gittag.Open(fixture.Dir,
+		gittag.WithEnv("GIT_SSH_COMMAND=false"),
+		gittag.WithHTTPSFallback(server.URL+"/remote.git", "s3cret"),
+		gittag.WithTrace(&trace))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.CreateRemote("v1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	if got := fixture.RemoteTags(); !reflect.DeepEqual(got, []string{"v1.0.0"}) {
+		t.Errorf("remote tags = %v", got)
+	}
+	commands := trace.Commands()
+	last := commands[len(commands)-1]
+	if last.Transport != "https" || last.ExitCode != 0 || strings.Contains(last.String(), base64.StdEncoding.EncodeToString([]byte("x-access-token:s3cret"))) {
+		t.Errorf("last traced command = %+v, want a successful redacted push over https", last)
+	}
+
+	// 网络和认证以外的错误不会改用 HTTPS 重试
+	trace.Reset()
+	if err := repo.PushTagRef("v9.0.0", "no-such-ref"); err == nil {
+		t.Error("push of an unknown ref succeeded")
+	}
+	for _, c := range trace.Commands() {
+		if c.Transport != "" {
+			t.Errorf("unexpected fallback: %s", c)
+		}
+	}
+}
+
+func TestPushManyAndPushAllTags(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0"), gittagtest.WithRemote())
+	for _, tag := range []string{"v2.0.0", "v2.1.0", "v3.0.0"} {
+		fixture.Git("tag", tag)
+	}
+	var trace gittag.Trace
+	repo, err := gittag.Open(fixture.Dir, gittag.WithTrace(&trace), gittag.WithPushVerification(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pushes := func() int {
+		n := 0
+		for _, c := range trace.Commands() {
+			if slices.Contains(c.Args, "push") {
+				n++
+			}
+		}
+		return n
+	}
+
+	if err := repo.PushMany("v2.*"); err != nil {
+		t.Fatal(err)
+	}
+	if got := fixture.RemoteTags(); !reflect.DeepEqual(got, []string{"v1.0.0", "v2.0.0", "v2.1.0"}) {
+		t.Errorf("remote tags after PushMany = %v", got)
+	}
+	if n := pushes(); n != 1 {
+		t.Errorf("PushMany ran %d pushes, want 1", n)
+	}
+	if err := repo.PushMany("v9.*"); err != nil {
+		t.Errorf("PushMany without matches = %v", err)
+	}
+
+	trace.Reset()
+	if err := repo.PushAllTags(); err != nil {
+		t.Fatal(err)
+	}
+	if got := fixture.RemoteTags(); !reflect.DeepEqual(got, []string{"v1.0.0", "v2.0.0", "v2.1.0", "v3.0.0"}) {
+		t.Errorf("remote tags after PushAllTags = %v", got)
+	}
+	if n := pushes(); n != 1 {
+		t.Errorf("PushAllTags ran %d pushes, want 1", n)
+	}
+
+	// 远程已有指向其他对象的同名标签时推送失败
+	fixture.Commit("next")
+	fixture.Git("tag", "-f", "v3.0.0")
+	if err := repo.PushAllTags(); !errors.Is(err, gittag.ErrTagExists) {
+		t.Errorf("PushAllTags over a moved tag = %v, want ErrTagExists", err)
+	}
+}
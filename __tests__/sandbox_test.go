@@ -0,0 +1,54 @@
+package gittag
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/afeiship/gittag"
+	"github.com/afeiship/gittag/gittagtest"
+)
+
+func TestSandbox(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0"), gittagtest.WithRemote())
+	repo, err := gittag.Open(fixture.Dir, gittag.WithSandbox("ci-run-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.CreateTag("v1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	tags, err := repo.FindMany("v*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"v1.0.0"}; !reflect.DeepEqual(tags, want) {
+		t.Errorf("FindMany = %v, want %v", tags, want)
+	}
+	if got, want := fixture.RemoteTags(), []string{"sandbox/ci-run-1/v1.0.0", "v1.0.0"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("remote tags = %v, want %v", got, want)
+	}
+
+	fixture.Git("tag", "-d", "sandbox/ci-run-1/v1.0.0")
+	if err := fixture.Open().CleanupSandbox("ci-run-1"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fixture.RemoteTags(), []string{"v1.0.0"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("remote tags after cleanup = %v, want %v", got, want)
+	}
+}
+
+func TestCleanupSandboxRemoteUnreachable(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0"), gittagtest.WithRemote())
+	fixture.Git("tag", "sandbox/ci-run-1/v1.0.0")
+	fixture.Git("tag", "sandbox/ci-run-1/v1.1.0")
+	fixture.Git("remote", "set-url", "origin", "http://127.0.0.1:1/repo.git")
+
+	// 远程不可达时仍然删除本地沙箱标签，并返回远程的错误
+	if err := fixture.Open().CleanupSandbox("ci-run-1"); err == nil {
+		t.Error("CleanupSandbox with unreachable remote = nil, want error")
+	}
+	if got, want := fixture.Tags(), []string{"v1.0.0"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("local tags after cleanup = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,150 @@
+package gittag
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/afeiship/gittag"
+	"github.com/afeiship/gittag/gittagtest"
+)
+
+func TestParseCron(t *testing.T) {
+	from := time.Date(2024, 1, 1, 10, 7, 30, 0, time.UTC) // Monday
+	cases := []struct {
+		spec string
+		want time.Time
+	}{
+		{"*/15 * * * *", time.Date(2024, 1, 1, 10, 15, 0, 0, time.UTC)},
+		{"0 3 * * *", time.Date(2024, 1, 2, 3, 0, 0, 0, time.UTC)},
+		{"30 9 * * 6,7", time.Date(2024, 1, 6, 9, 30, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		// 日期或星期字段包含所有取值时等同于 "*"，只按另一个字段匹配
+		{"0 0 */1 * 5", time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1-31 * 5", time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)},
+		{"0 0 13 * 0-7", time.Date(2024, 1, 13, 0, 0, 0, 0, time.UTC)},
+		// 两者都被限制时满足任意一个即可
+		{"0 0 13 * 5", time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		schedule, err := gittag.ParseCron(c.spec)
+		if err != nil {
+			t.Fatalf("ParseCron(%q): %v", c.spec, err)
+		}
+		if got := schedule.Next(from); !got.Equal(c.want) {
+			t.Errorf("ParseCron(%q).Next = %s, want %s", c.spec, got, c.want)
+		}
+	}
+	for _, spec := range []string{"* * * *", "60 * * * *", "*/0 * * * *", "5-1 * * * *"} {
+		if _, err := gittag.ParseCron(spec); err == nil {
+			t.Errorf("ParseCron(%q) should fail", spec)
+		}
+	}
+}
+
+func TestEveryRejectsNonPositive(t *testing.T) {
+	for _, interval := range []time.Duration{0, -time.Second} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Every(%s) should panic", interval)
+				}
+			}()
+			gittag.Every(interval)
+		}()
+	}
+}
+
+func TestScheduler(t *testing.T) {
+	s := gittag.NewScheduler()
+	s.Add("ok", gittag.Every(10*time.Millisecond), func(ctx context.Context) error { return nil })
+	s.Add("fail", gittag.Every(10*time.Millisecond), func(ctx context.Context) error { return errors.New("boom") },
+		gittag.WithJitter(5*time.Millisecond))
+	if err := s.Add("ok", gittag.Every(time.Second), nil); err == nil {
+		t.Error("duplicate task name should be rejected")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := s.Run(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Run = %v", err)
+	}
+
+	stats := s.Stats()
+	if len(stats) != 2 || stats[0].Runs == 0 || stats[0].Failures != 0 {
+		t.Errorf("ok stats = %+v", stats)
+	}
+	if stats[1].Failures == 0 || stats[1].Failures != stats[1].Runs || stats[1].LastError != "boom" {
+		t.Errorf("fail stats = %+v", stats[1])
+	}
+}
+
+func TestSchedulerTasks(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0", "v2.0.0"), gittagtest.WithRemote())
+	repo := fixture.Open()
+	ctx := context.Background()
+
+	// 远程新增的标签被获取到本地
+	fixture.RemoteGit("tag", "v2.1.0", "v2.0.0")
+	if err := repo.FetchTagsTask()(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := fixture.Tags(); !reflect.DeepEqual(got, []string{"v1.0.0", "v2.0.0", "v2.1.0"}) {
+		t.Errorf("tags after FetchTagsTask = %v", got)
+	}
+
+	// 只删除已过 EOL 系列的本地标签
+	if err := repo.SetEOL("v1.*", time.Now().AddDate(0, 0, -1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.SetEOL("v2.*", time.Now().AddDate(1, 0, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.PruneEOLTask("v*")(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := fixture.Tags(); !reflect.DeepEqual(got, []string{"v2.0.0", "v2.1.0"}) {
+		t.Errorf("tags after PruneEOLTask = %v", got)
+	}
+	if got := fixture.RemoteTags(); !slices.Contains(got, "v1.0.0") {
+		t.Errorf("PruneEOLTask deleted remote tags: %v", got)
+	}
+
+	// 本地与远程一致时不告警，出现差异时告警
+	var alerts []*gittag.TagSyncStatus
+	alert := repo.SyncStatusTask("v*", func(ctx context.Context, status *gittag.TagSyncStatus) error {
+		alerts = append(alerts, status)
+		return errors.New("out of sync")
+	})
+	fixture.Git("fetch", "--quiet", "origin", "tag", "v1.0.0")
+	if err := alert(ctx); err != nil || len(alerts) != 0 {
+		t.Fatalf("SyncStatusTask in sync = %v, alerts %v", err, alerts)
+	}
+	fixture.Git("tag", "v2.2.0")
+	fixture.Git("tag", "-f", "v2.1.0", "v1.0.0")
+	fixture.RemoteGit("tag", "v3.0.0")
+	if err := alert(ctx); err == nil || len(alerts) != 1 {
+		t.Fatalf("SyncStatusTask out of sync = %v, alerts %v", err, alerts)
+	}
+	if want := (&gittag.TagSyncStatus{LocalOnly: []string{"v2.2.0"}, RemoteOnly: []string{"v3.0.0"}, Diverged: []string{"v2.1.0"}}); !reflect.DeepEqual(alerts[0], want) {
+		t.Errorf("alert status = %+v, want %+v", alerts[0], want)
+	}
+	fixture.Git("tag", "-d", "v2.2.0")
+	fixture.Git("fetch", "--quiet", "origin", "tag", "v3.0.0")
+
+	fixture.Git("checkout", "--quiet", "-b", "experiment")
+	fixture.Commit("experiment")
+	fixture.Git("tag", "exp-1")
+	fixture.Git("checkout", "--quiet", "-")
+	fixture.Git("branch", "-D", "experiment")
+	if err := repo.DeleteOrphansTask()(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := fixture.Tags(); slices.Contains(got, "exp-1") {
+		t.Errorf("tags after DeleteOrphansTask = %v", got)
+	}
+}
@@ -0,0 +1,34 @@
+package gittag
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/afeiship/gittag"
+	"github.com/afeiship/gittag/gittagtest"
+)
+
+func TestSigningPreflight(t *testing.T) {
+	fixture := gittagtest.NewRepo(t)
+
+	fixture.Git("config", "gpg.format", "ssh")
+	fixture.Git("config", "user.signingkey", filepath.Join(t.TempDir(), "missing_ed25519"))
+	if err := fixture.Open().SigningPreflight(); err == nil || !strings.Contains(err.Error(), "missing_ed25519") {
+		t.Errorf("ssh preflight error = %v, want missing key file", err)
+	}
+
+	if _, err := exec.LookPath("gpg"); err != nil {
+		t.Skip("gpg not available")
+	}
+	fixture.Git("config", "gpg.format", "openpgp")
+	fixture.Git("config", "user.signingkey", "DEADBEEFDEADBEEF")
+	repo, err := gittag.Open(fixture.Dir, gittag.WithEnv("GNUPGHOME="+t.TempDir()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.SigningPreflight(); err == nil || !strings.Contains(err.Error(), "DEADBEEFDEADBEEF") {
+		t.Errorf("openpgp preflight error = %v, want missing secret key", err)
+	}
+}
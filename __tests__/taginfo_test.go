@@ -0,0 +1,495 @@
+package gittag
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/afeiship/gittag"
+	"github.com/afeiship/gittag/gittagtest"
+)
+
+func TestListInfoCacheInvalidation(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0", "v1.1.0"))
+	fixture.Git("tag", "nightly")
+	repo, err := gittag.Open(fixture.Dir, gittag.WithCache())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	infos, err := repo.ListInfo("*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 3 || !infos[2].Annotated || infos[0].Annotated || infos[2].Message != "release v1.1.0\n" {
+		t.Fatalf("unexpected infos: %+v", infos)
+	}
+
+	head := fixture.Commit("move nightly")
+	fixture.Git("tag", "-f", "nightly")
+	fixture.Git("tag", "-d", "v1.0.0")
+
+	infos, err = repo.ListInfo("*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 2 || infos[0].Name != "nightly" || infos[0].Commit != head {
+		t.Errorf("cache was not invalidated: %+v", infos)
+	}
+}
+
+func TestListInfoCachePrune(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0", "v1.1.0"))
+	fixture.Git("tag", "nightly")
+	store := gittag.NewMemoryStore()
+	repo, err := gittag.Open(fixture.Dir, gittag.WithStore(store), gittag.WithCache())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cached := func() string {
+		data, _ := store.Get(context.Background(), "cache/taginfo.json")
+		return string(data)
+	}
+
+	if _, err := repo.ListInfo("*"); err != nil {
+		t.Fatal(err)
+	}
+	// 已删除的标签从缓存中移除，不匹配本次模式的条目保留
+	fixture.Git("tag", "-d", "v1.0.0")
+	if _, err := repo.ListInfo("v*"); err != nil {
+		t.Fatal(err)
+	}
+	if got := cached(); strings.Contains(got, `"v1.0.0"`) || !strings.Contains(got, `"nightly"`) || !strings.Contains(got, `"v1.1.0"`) {
+		t.Errorf("cache after deleting v1.0.0 = %s", got)
+	}
+
+	// 只读的仓库读取缓存但不写入
+	readOnly, err := gittag.Open(fixture.Dir, gittag.WithStore(store), gittag.WithCache(), gittag.WithReadOnly())
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixture.Git("tag", "v1.2.0")
+	before := cached()
+	if infos, err := readOnly.ListInfo("v*"); err != nil || len(infos) != 2 {
+		t.Fatalf("read-only ListInfo = %+v, %v", infos, err)
+	}
+	if got := cached(); got != before {
+		t.Errorf("read-only handle wrote the cache: %s", got)
+	}
+}
+
+func TestRecent(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags("v1.1.0", "v1.0.1", "v2.0.0-rc.1"))
+
+	infos, err := fixture.Open().Recent(2, "v1.*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 2 || infos[0].Name != "v1.0.1" || infos[1].Name != "v1.1.0" {
+		t.Errorf("Recent = %+v", infos)
+	}
+
+	// 模式与 FindMany 一样匹配完整的标签名称，* 可以匹配 "/"
+	fixture.Git("tag", "v1/hotfix")
+	for pattern, want := range map[string][]string{
+		"v1*": {"v1/hotfix", "v1.0.1", "v1.1.0"},
+		"v1":  nil,
+	} {
+		infos, err := fixture.Open().Recent(5, pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, info := range infos {
+			names = append(names, info.Name)
+		}
+		if !reflect.DeepEqual(names, want) {
+			t.Errorf("Recent(5, %q) = %v, want %v", pattern, names, want)
+		}
+	}
+}
+
+func TestNestedTag(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags("v1.2.3"))
+	repo := fixture.Open()
+	head := fixture.Git("rev-parse", "HEAD")
+
+	if err := repo.CreateNestedTag("verified/v1.2.3", "v1.2.3", "verified"); err != nil {
+		t.Fatal(err)
+	}
+	if got := fixture.Git("cat-file", "-t", "verified/v1.2.3^{}"); got != "commit" {
+		t.Fatalf("nested tag peels to %s", got)
+	}
+	if sha, err := repo.Resolve("verified/v1.2.3"); err != nil || sha != head {
+		t.Errorf("Resolve = %s, %v; want %s", sha, err, head)
+	}
+	infos, err := repo.ListInfo("verified/*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 1 || infos[0].Commit != head || infos[0].TargetTag != "v1.2.3" {
+		t.Errorf("ListInfo = %+v", infos)
+	}
+	if err := repo.CreateNestedTag("bad", "missing"); err == nil {
+		t.Error("CreateNestedTag should fail for a missing target")
+	}
+
+	// 与其他创建标签的方法一样检查命名约定并记录操作者
+	bot := gittag.Actor{Name: "Release Bot", Email: "bot@example.com", ID: "svc-release"}
+	strict, err := gittag.Open(fixture.Dir, gittag.WithNamingConvention(gittag.SemverNaming), gittag.WithActor(bot))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var namingErr *gittag.NamingError
+	if err := strict.CreateNestedTag("verified-v1.2.3", "v1.2.3"); !errors.As(err, &namingErr) {
+		t.Errorf("CreateNestedTag with a bad name = %v, want *NamingError", err)
+	}
+	if err := strict.CreateNestedTag("v1.2.3-verified", "v1.2.3"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := strict.TagActor("v1.2.3-verified"); err != nil || got == nil || *got != bot {
+		t.Errorf("TagActor = %v, %v; want %v", got, err, bot)
+	}
+}
+
+func TestRawTagObject(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0"))
+	fixture.Git("tag", "light")
+	repo := fixture.Open()
+
+	raw, err := repo.RawTagObject("v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := fixture.Git("cat-file", "tag", "v1.0.0") + "\n"; string(raw) != want {
+		t.Errorf("RawTagObject = %q, want %q", raw, want)
+	}
+	if _, err := repo.RawTagObject("light"); err == nil {
+		t.Error("RawTagObject should fail for lightweight tags")
+	}
+}
+
+func TestManifest(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0", "v1.1.0", "v1.2.0"))
+	repo := fixture.Open()
+	lock := filepath.Join(t.TempDir(), "tags.lock")
+
+	if err := repo.WriteManifest(lock, "v*"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.VerifyManifest(lock); err != nil {
+		t.Fatalf("fresh manifest: %v", err)
+	}
+
+	// 空的或被截断的清单不能通过校验
+	content, err := os.ReadFile(lock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.SplitAfter(string(content), "\n")
+	for name, broken := range map[string]string{
+		"empty":          "",
+		"header only":    lines[0],
+		"missing footer": strings.Join(lines[:len(lines)-2], ""),
+		"missing entry":  lines[0] + lines[1] + lines[len(lines)-2],
+	} {
+		path := filepath.Join(t.TempDir(), "tags.lock")
+		if err := os.WriteFile(path, []byte(broken), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.VerifyManifest(path); err == nil {
+			t.Errorf("%s manifest: VerifyManifest = nil, want error", name)
+		}
+	}
+
+	fixture.Git("tag", "-d", "v1.0.0")
+	fixture.Git("tag", "-f", "-a", "-m", "release v1.1.0", "v1.1.0", "HEAD~2")
+	fixture.Git("tag", "-f", "-a", "-m", "rewritten", "v1.2.0")
+
+	err = repo.VerifyManifest(lock)
+	var manifestErr *gittag.ManifestError
+	if !errors.As(err, &manifestErr) || len(manifestErr.Mismatches) != 3 {
+		t.Fatalf("VerifyManifest = %v, want 3 mismatches", err)
+	}
+}
+
+func TestArtifacts(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0"))
+	repo := fixture.Open()
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	linux, darwin := write("app_linux", "linux build"), write("app_darwin", "darwin build")
+
+	for _, path := range []string{linux, darwin} {
+		digest, err := gittag.DigestFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.RecordArtifacts("v1.0.0", []gittag.ArtifactDigest{digest}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if recorded, _ := repo.Artifacts("v1.0.0"); len(recorded) != 2 {
+		t.Fatalf("Artifacts() = %v, want 2 entries", recorded)
+	}
+	if err := repo.VerifyArtifacts("v1.0.0", linux, darwin); err != nil {
+		t.Fatal(err)
+	}
+
+	write("app_linux", "tampered build")
+	unknown := write("app_windows", "windows build")
+	err := repo.VerifyArtifacts("v1.0.0", linux, unknown)
+	if err == nil || !strings.Contains(err.Error(), "app_linux") || !strings.Contains(err.Error(), "app_windows") {
+		t.Errorf("VerifyArtifacts = %v, want mismatch and missing errors", err)
+	}
+}
+
+func TestOrphans(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0"))
+	fixture.Git("checkout", "--quiet", "-b", "experiment")
+	fixture.Commit("experiment")
+	fixture.Git("tag", "exp-1")
+	fixture.Git("checkout", "--quiet", "-")
+	fixture.Git("branch", "-D", "experiment")
+	repo := fixture.Open()
+
+	orphans, err := repo.Orphans()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orphans) != 1 || orphans[0].Name != "exp-1" {
+		t.Fatalf("Orphans() = %v, want [exp-1]", orphans)
+	}
+	deleted, err := repo.DeleteOrphans()
+	if err != nil || len(deleted) != 1 {
+		t.Fatalf("DeleteOrphans() = %v, %v", deleted, err)
+	}
+	if got := fixture.Tags(); len(got) != 1 || got[0] != "v1.0.0" {
+		t.Errorf("tags = %v, want [v1.0.0]", got)
+	}
+}
+
+func TestRewriteImpact(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0", "v1.1.0", "v1.2.0"))
+	repo := fixture.Open()
+
+	impacted, err := repo.RewriteImpact("v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, info := range impacted {
+		names = append(names, info.Name)
+	}
+	if want := []string{"v1.1.0", "v1.2.0"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("RewriteImpact(v1.0.0) = %v, want %v", names, want)
+	}
+	if impacted, _ := repo.RewriteImpact("HEAD"); len(impacted) != 0 {
+		t.Errorf("RewriteImpact(HEAD) = %v, want none", impacted)
+	}
+}
+
+func TestRemapTags(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithRemote(), gittagtest.WithTags("v1.0.0"))
+	fixture.Git("tag", "nightly")
+	fixture.Git("push", "--quiet", "origin", "nightly")
+	var trace gittag.Trace
+	repo, err := gittag.Open(fixture.Dir, gittag.WithTrace(&trace))
+	if err != nil {
+		t.Fatal(err)
+	}
+	before, err := repo.ListInfo("v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	old := fixture.Git("rev-parse", "HEAD")
+	fixture.Git("commit", "--quiet", "--amend", "--allow-empty", "-m", "rewritten")
+	rewritten := fixture.Git("rev-parse", "HEAD")
+
+	trace.Reset()
+	moved, err := repo.RemapTags(map[string]string{strings.ToUpper(old): rewritten})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 冻结系列只读取一次，而不是每个标签读取一次
+	reads := 0
+	for _, c := range trace.Commands() {
+		if slices.Contains(c.Args, "gittag.frozen") {
+			reads++
+		}
+	}
+	if reads != 1 {
+		t.Errorf("RemapTags read the frozen series %d times, want 1", reads)
+	}
+	if want := []string{"nightly", "v1.0.0"}; !reflect.DeepEqual(moved, want) {
+		t.Errorf("RemapTags = %v, want %v", moved, want)
+	}
+	after, err := repo.ListInfo("v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after[0].Commit != rewritten || after[0].Message != before[0].Message ||
+		after[0].TaggerEmail != before[0].TaggerEmail || !after[0].Date.Equal(before[0].Date) {
+		t.Errorf("remapped tag = %+v, want %+v on %s", after[0], before[0], rewritten)
+	}
+	for _, tag := range []string{"nightly", "v1.0.0"} {
+		if got := fixture.RemoteGit("rev-parse", tag+"^{commit}"); got != rewritten {
+			t.Errorf("remote %s = %s, want %s", tag, got, rewritten)
+		}
+	}
+}
+
+func TestCompareReport(t *testing.T) {
+	fixture := gittagtest.NewRepo(t)
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(fixture.Dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		fixture.Git("add", name)
+	}
+	write("go.mod", "module example.com/app\n\nrequire (\n\tgolang.org/x/sys v0.4.0\n\tgolang.org/x/text v0.3.0 // indirect\n)\n")
+	fixture.Commit("add go.mod")
+	fixture.Tag("v1.0.0")
+	write("go.mod", "module example.com/app\n\nrequire (\n\tgolang.org/x/sys v0.5.0\n\tgithub.com/fsnotify/fsnotify v1.7.0\n)\n")
+	fixture.Commit("deps: bump x/sys <script>")
+	write("main.go", "package main\n")
+	fixture.Commit("feat: add main")
+	fixture.Tag("v1.1.0")
+	repo := fixture.Open()
+
+	for format, golden := range map[gittag.Format]string{
+		gittag.FormatMarkdown: "testdata/compare_report.golden",
+		gittag.FormatHTML:     "testdata/compare_report_html.golden",
+	} {
+		report, err := repo.CompareReport("v1.0.0", "v1.1.0", format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gittagtest.Golden(t, golden, report)
+	}
+	if _, err := repo.CompareReport("v1.0.0", "v1.1.0", "pdf"); err == nil {
+		t.Error("CompareReport(pdf) succeeded")
+	}
+}
+
+func TestLag(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0"))
+	fixture.Git("tag", "nightly")
+	fixture.Git("tag", "-a", "-m", "verified", "verified/v1.0.0", "v1.0.0")
+	repo := fixture.Open()
+
+	if lag, err := repo.Lag("v1.0.0"); err != nil || lag != time.Minute {
+		t.Errorf("Lag(v1.0.0) = %v, %v; want 1m", lag, err)
+	}
+	if _, err := repo.Lag("nightly"); err == nil {
+		t.Error("Lag of a lightweight tag succeeded")
+	}
+	var notFound *gittag.NotFoundError
+	if _, err := repo.Lag("v9.9.9"); !errors.As(err, &notFound) {
+		t.Errorf("Lag(v9.9.9) = %v, want *NotFoundError", err)
+	}
+
+	infos, err := repo.ListInfo("*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitDate := infos[1].CommitterDate
+	for _, info := range infos {
+		if !info.CommitterDate.Equal(commitDate) || commitDate.IsZero() {
+			t.Errorf("%s CommitterDate = %v, want %v", info.Name, info.CommitterDate, commitDate)
+		}
+		if info.Annotated == info.TaggerDate.IsZero() {
+			t.Errorf("%s TaggerDate = %v", info.Name, info.TaggerDate)
+		}
+	}
+}
+
+func TestTagsTouching(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0"))
+	change := func(name, message string) {
+		path := filepath.Join(fixture.Dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(message+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		fixture.Git("add", name)
+		fixture.Commit(message)
+	}
+	change("config/prod.yaml", "tune prod")
+	fixture.Tag("v1.1.0")
+	change("main.go", "feature")
+	fixture.Tag("v1.2.0")
+	change("config/prod.yaml", "tune prod again")
+	change("README.md", "docs")
+	fixture.Tag("v1.3.0")
+	fixture.Git("tag", "nightly")
+
+	infos, err := fixture.Open().TagsTouching("config/prod.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, info := range infos {
+		names = append(names, info.Name)
+	}
+	if want := []string{"v1.1.0", "v1.3.0"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("TagsTouching = %v, want %v", names, want)
+	}
+}
+
+func TestTagFS(t *testing.T) {
+	fixture := gittagtest.NewRepo(t)
+	files := map[string]string{"README.md": "hello\n", "deploy/values.yaml": "replicas: 2\n", "deploy/env/prod.env": "A=1\n"}
+	for name, content := range files {
+		path := filepath.Join(fixture.Dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink("values.yaml", filepath.Join(fixture.Dir, "deploy", "current.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	fixture.Git("add", ".")
+	fixture.Commit("add files")
+	fixture.Tag("v1.0.0")
+	fixture.Git("rm", "--quiet", "README.md")
+	fixture.Commit("remove README")
+
+	fsys, err := fixture.Open().TagFS("v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fstest.TestFS(fsys, "README.md", "deploy/values.yaml", "deploy/env/prod.env", "deploy/current.yaml"); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := fs.ReadFile(fsys, "deploy/values.yaml"); err != nil || string(data) != files["deploy/values.yaml"] {
+		t.Errorf("ReadFile = %q, %v", data, err)
+	}
+	if info, err := fs.Stat(fsys, "deploy/current.yaml"); err != nil || info.Mode()&fs.ModeSymlink == 0 {
+		t.Errorf("symlink mode = %v, %v", info, err)
+	}
+	var notFound *gittag.NotFoundError
+	if _, err := fixture.Open().TagFS("v9.9.9"); !errors.As(err, &notFound) {
+		t.Errorf("TagFS(v9.9.9) = %v, want *NotFoundError", err)
+	}
+}
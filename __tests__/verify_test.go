@@ -0,0 +1,303 @@
+package gittag
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/afeiship/gittag"
+	"github.com/afeiship/gittag/gittagtest"
+)
+
+// sshKey 生成一个测试用的 ed25519 密钥并返回私钥路径和公钥内容
+func sshKey(t *testing.T, name string) (string, string) {
+	t.Helper()
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen not available")
+	}
+	path := filepath.Join(t.TempDir(), name)
+	if out, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-C", name, "-f", path).CombinedOutput(); err != nil {
+		t.Fatalf("ssh-keygen: %v\n%s", err, out)
+	}
+	pub, err := os.ReadFile(path + ".pub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path, strings.TrimSpace(string(pub))
+}
+
+func TestListVerified(t *testing.T) {
+	trustedKey, trustedPub := sshKey(t, "release")
+	otherKey, _ := sshKey(t, "other")
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0"))
+	fixture.Git("config", "gpg.format", "ssh")
+
+	fixture.Git("-c", "user.signingkey="+trustedKey, "tag", "-s", "-m", "signed", "v1.1.0")
+	fixture.Git("-c", "user.signingkey="+otherKey, "tag", "-s", "-m", "signed by someone else", "v1.2.0")
+
+	allowed := filepath.Join(t.TempDir(), "allowed_signers")
+	if err := os.WriteFile(allowed, []byte("release@example.com "+trustedPub+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	repo := fixture.Open()
+
+	sig, err := repo.Verify("v1.0.0")
+	if err != nil || sig.Signed {
+		t.Errorf("Verify(v1.0.0) = %+v, %v; want unsigned", sig, err)
+	}
+
+	infos, err := repo.ListVerified("v*", gittag.TrustPolicy{AllowedSignersFile: allowed})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 1 || infos[0].Name != "v1.1.0" || infos[0].Signature.Signer != "release@example.com" {
+		t.Fatalf("ListVerified = %+v", infos)
+	}
+
+	byFingerprint := gittag.TrustPolicy{Fingerprints: []string{infos[0].Signature.Fingerprint}}
+	if _, ok, err := repo.VerifyWithPolicy("v1.2.0", byFingerprint); err != nil || ok {
+		t.Errorf("v1.2.0 trusted = %v, %v; want untrusted", ok, err)
+	}
+
+	// 只读、受限和 dry-run 句柄同样可以校验签名
+	policy := gittag.TrustPolicy{AllowedSignersFile: allowed}
+	for name, opts := range map[string][]gittag.Option{
+		"read-only": {gittag.WithReadOnly()},
+		"dry-run":   {gittag.WithDryRun(io.Discard)},
+	} {
+		handle, err := gittag.Open(fixture.Dir, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sig, ok, err := handle.VerifyWithPolicy("v1.1.0", policy); err != nil || !ok || !sig.Valid {
+			t.Errorf("%s: VerifyWithPolicy(v1.1.0) = %+v, %v, %v; want valid and trusted", name, sig, ok, err)
+		}
+	}
+	if sig, ok, err := repo.Restrict(gittag.CapRead).VerifyWithPolicy("v1.1.0", policy); err != nil || !ok || !sig.Valid {
+		t.Errorf("Restrict(CapRead): VerifyWithPolicy(v1.1.0) = %+v, %v, %v; want valid and trusted", sig, ok, err)
+	}
+	if infos, err := repo.Restrict(gittag.CapRead).ListVerified("v*", policy); err != nil || len(infos) != 1 {
+		t.Errorf("Restrict(CapRead): ListVerified = %+v, %v", infos, err)
+	}
+}
+
+func TestResignAll(t *testing.T) {
+	key, _ := sshKey(t, "release")
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0", "v1.1.0"), gittagtest.WithRemote())
+	fixture.Git("tag", "v1.2.0")
+	fixture.Git("config", "gpg.format", "ssh")
+	repo := fixture.Open()
+	before, err := repo.ListInfo("v*")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.ResignAll("v*"); err == nil {
+		t.Fatal("ResignAll without a signing key succeeded")
+	}
+	resigned, err := repo.ResignAll("v*", gittag.WithSigningKey(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(resigned, ",") != "v1.0.0,v1.1.0" {
+		t.Errorf("resigned = %v, want the annotated tags only", resigned)
+	}
+
+	after, err := repo.ListInfo("v*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, info := range after {
+		old := before[i]
+		if info.Commit != old.Commit || info.Message != old.Message {
+			t.Errorf("%s changed target or message: %+v -> %+v", info.Name, old, info)
+		}
+		if signed := info.SignatureFormat == "ssh"; signed != old.Annotated {
+			t.Errorf("%s signature format = %q", info.Name, info.SignatureFormat)
+		}
+		if !info.Annotated {
+			continue
+		}
+		if remote := fixture.RemoteGit("rev-parse", "refs/tags/"+info.Name); remote != info.Object {
+			t.Errorf("remote %s = %s, want %s", info.Name, remote, info.Object)
+		}
+	}
+}
+
+func TestReleaseSigned(t *testing.T) {
+	key, _ := sshKey(t, "release")
+	fixture := gittagtest.NewRepo(t, gittagtest.WithCommits(1), gittagtest.WithRemote())
+	fixture.Git("config", "gpg.format", "ssh")
+	repo := fixture.Open()
+
+	state, err := repo.Release(context.Background(), "run-1", "v1.0.0", gittag.WithCreateOptions(gittag.WithSign(key)))
+	if err != nil || !state.Done() {
+		t.Fatalf("Release = %+v, %v", state, err)
+	}
+	infos, err := repo.ListInfo("v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 1 || infos[0].SignatureFormat != "ssh" {
+		t.Errorf("released tag = %+v, want an SSH signature", infos)
+	}
+}
+
+func TestCreateSigned(t *testing.T) {
+	repoKey, _ := sshKey(t, "repo")
+	callKey, _ := sshKey(t, "call")
+	fixture := gittagtest.NewRepo(t, gittagtest.WithRemote())
+	fixture.Git("config", "gpg.format", "ssh")
+	repo, err := gittag.Open(fixture.Dir, gittag.WithSignedTags(repoKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.CreateTag("v1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Create("v1.1.0", gittag.WithSign(callKey)); err != nil {
+		t.Fatal(err)
+	}
+	if err := fixture.Open().CreateLocal("v1.2.0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Create("ci/1", gittag.WithLightweight(), gittag.WithSign(callKey)); err == nil {
+		t.Error("signed lightweight tag was created")
+	}
+
+	infos, err := repo.ListInfo("v*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, info := range infos {
+		if signed := info.SignatureFormat == "ssh"; signed != (info.Name != "v1.2.0") {
+			t.Errorf("%s signature format = %q", info.Name, info.SignatureFormat)
+		}
+	}
+	for tag, key := range map[string]string{"v1.0.0": repoKey, "v1.1.0": callKey} {
+		pub, err := os.ReadFile(key + ".pub")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if raw := fixture.Git("cat-file", "tag", tag); !strings.Contains(raw, "-----BEGIN SSH SIGNATURE-----") {
+			t.Errorf("%s is not signed:\n%s", tag, raw)
+		}
+		allowed := filepath.Join(t.TempDir(), "allowed_signers")
+		if err := os.WriteFile(allowed, []byte("release@example.com "+string(pub)), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		fixture.Git("-c", "gpg.ssh.allowedSignersFile="+allowed, "tag", "-v", tag)
+	}
+	if remote := fixture.RemoteGit("cat-file", "tag", "v1.0.0"); !strings.Contains(remote, "SSH SIGNATURE") {
+		t.Error("pushed v1.0.0 is not signed")
+	}
+}
+
+func TestWithSSHSigning(t *testing.T) {
+	key, pub := sshKey(t, "release")
+	fixture := gittagtest.NewRepo(t)
+	repo, err := gittag.Open(fixture.Dir, gittag.WithSSHSigning(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.SigningPreflight(); err != nil {
+		t.Fatalf("SigningPreflight = %v", err)
+	}
+	if err := repo.CreateLocal("v1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	if got := fixture.Git("config", "--default", "", "gpg.format"); got != "" {
+		t.Errorf("WithSSHSigning changed the repository config: gpg.format = %q", got)
+	}
+
+	allowed := filepath.Join(t.TempDir(), "allowed_signers")
+	if err := os.WriteFile(allowed, []byte("release@example.com "+pub+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fixture.Git("-c", "gpg.ssh.allowedSignersFile="+allowed, "tag", "-v", "v1.0.0")
+
+	findings, err := repo.Doctor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range findings {
+		if f.Check == "signing" && (f.Severity != gittag.SeverityOK || f.Message != "签名配置可用") {
+			t.Errorf("signing finding = %v", f)
+		}
+	}
+
+	broken, err := gittag.Open(fixture.Dir, gittag.WithSSHSigning(filepath.Join(t.TempDir(), "missing")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := broken.SigningPreflight(); err == nil || !strings.Contains(err.Error(), "无法读取") {
+		t.Errorf("SigningPreflight with a missing key = %v", err)
+	}
+	// git 2.39 在密钥不存在时仍会创建未签名的标签，CreateLocal 必须拒绝并清理它
+	if err := broken.CreateLocal("v1.1.0"); err == nil {
+		t.Error("CreateLocal with a missing SSH key succeeded")
+	}
+	if tags := fixture.Tags(); !reflect.DeepEqual(tags, []string{"v1.0.0"}) {
+		t.Errorf("tags = %v, the unsigned tag was kept", tags)
+	}
+}
+
+func TestSignSnapshot(t *testing.T) {
+	key, pub := sshKey(t, "attest")
+	otherKey, _ := sshKey(t, "other")
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0", "v1.1.0"))
+	repo := fixture.Open()
+	allowed := filepath.Join(t.TempDir(), "allowed_signers")
+	if err := os.WriteFile(allowed, []byte("attest@example.com "+pub+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	policy := gittag.TrustPolicy{AllowedSignersFile: allowed}
+
+	signed, err := repo.SignSnapshot(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 快照保存为 JSON 后再读回，签名仍然有效
+	data, err := json.Marshal(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stored gittag.SignedSnapshot
+	if err := json.Unmarshal(data, &stored); err != nil {
+		t.Fatal(err)
+	}
+	fixture.Git("tag", "v1.2.0")
+	if err := repo.VerifySnapshot(&stored, policy); err != nil {
+		t.Fatalf("VerifySnapshot after creating a tag = %v", err)
+	}
+
+	untrusted, err := repo.SignSnapshot(otherKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.VerifySnapshot(untrusted, policy); err == nil {
+		t.Error("snapshot signed by an untrusted key verified")
+	}
+	forged := stored
+	forged.Tags = map[string]string{"v1.0.0": stored.Tags["v1.0.0"]}
+	if err := repo.VerifySnapshot(&forged, policy); err == nil || !strings.Contains(err.Error(), "签名无效") {
+		t.Errorf("VerifySnapshot of an edited snapshot = %v", err)
+	}
+
+	fixture.Git("tag", "-d", "v1.0.0")
+	fixture.Git("tag", "-f", "-m", "rewritten", "v1.1.0")
+	err = repo.VerifySnapshot(&stored, policy)
+	var rewritten *gittag.SnapshotError
+	if !errors.As(err, &rewritten) || len(rewritten.Changes) != 2 ||
+		rewritten.Changes[0].Type != gittag.TagDeleted || rewritten.Changes[1].Type != gittag.TagMoved {
+		t.Errorf("VerifySnapshot after rewriting tags = %v", err)
+	}
+}
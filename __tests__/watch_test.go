@@ -0,0 +1,85 @@
+package gittag
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/afeiship/gittag"
+	"github.com/afeiship/gittag/gittagtest"
+)
+
+func TestWatchLocal(t *testing.T) {
+	fixture := gittagtest.NewRepo(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := fixture.Open().WatchLocal(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := func() gittag.TagEvent {
+		t.Helper()
+		select {
+		case event := <-events:
+			return event
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for tag event")
+			return gittag.TagEvent{}
+		}
+	}
+
+	fixture.Tag("release/v1.0.0")
+	if event := next(); event.Type != gittag.TagCreated || event.Name != "release/v1.0.0" {
+		t.Errorf("unexpected event %+v", event)
+	}
+
+	fixture.Git("pack-refs", "--all")
+	fixture.Git("tag", "-d", "release/v1.0.0")
+	if event := next(); event.Type != gittag.TagDeleted || event.Name != "release/v1.0.0" {
+		t.Errorf("unexpected event %+v", event)
+	}
+
+	cancel()
+	for range events {
+	}
+}
+
+func TestSnapshotDiff(t *testing.T) {
+	fixture := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0", "v1.1.0", "nightly"))
+	repo := fixture.Open()
+
+	before, err := repo.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(before.Tags) != 3 || before.Tags["v1.0.0"] != fixture.Git("rev-parse", "v1.0.0") {
+		t.Fatalf("snapshot = %+v", before)
+	}
+
+	oldNightly := before.Tags["nightly"]
+	fixture.Commit("next")
+	fixture.Git("tag", "-f", "-m", "nightly", "nightly")
+	fixture.Git("tag", "-d", "v1.0.0")
+	fixture.Git("tag", "-m", "v1.2.0", "v1.2.0")
+	after, err := repo.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []gittag.TagEvent{
+		{Type: gittag.TagMoved, Name: "nightly", Object: after.Tags["nightly"], OldObject: oldNightly},
+		{Type: gittag.TagDeleted, Name: "v1.0.0", OldObject: before.Tags["v1.0.0"]},
+		{Type: gittag.TagCreated, Name: "v1.2.0", Object: after.Tags["v1.2.0"]},
+	}
+	if got := gittag.DiffSnapshots(before, after); !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffSnapshots = %+v, want %+v", got, want)
+	}
+	if got := gittag.DiffSnapshots(after, after); len(got) != 0 {
+		t.Errorf("DiffSnapshots of the same snapshot = %+v", got)
+	}
+	if got := gittag.DiffSnapshots(nil, before); len(got) != 3 {
+		t.Errorf("DiffSnapshots from nil = %+v", got)
+	}
+}
@@ -0,0 +1,154 @@
+package gittag
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// actorNotesRef 是记录标签操作者的 git notes 引用，推送方式：git push origin refs/notes/gittag/actors
+const actorNotesRef = "refs/notes/gittag/actors"
+
+// Actor 是发起操作的人或系统，用于在共享的发布服务中回答"这个标签是谁打的"
+type Actor struct {
+	Name  string `json:"name,omitempty"`
+	Email string `json:"email,omitempty"`
+	// ID 是外部系统中的标识，例如 SSO 用户 id 或 CI 的触发者
+	ID string `json:"id,omitempty"`
+}
+
+// String 返回 "Name <email> (id)" 形式的描述，省略为空的部分
+func (a Actor) String() string {
+	var parts []string
+	if a.Name != "" {
+		parts = append(parts, a.Name)
+	}
+	if a.Email != "" {
+		parts = append(parts, "<"+a.Email+">")
+	}
+	if a.ID != "" {
+		parts = append(parts, "("+a.ID+")")
+	}
+	return strings.Join(parts, " ")
+}
+
+// WithActor 指定仓库执行操作的操作者，通过 ContextWithActor 放入上下文的操作者优先
+//
+// Example:
+//
+//	repo, err := gittag.Open(".", gittag.WithActor(gittag.Actor{Name: "Release Bot", Email: "bot@example.com", ID: "svc-release"}))
+func WithActor(a Actor) Option {
+	return func(r *Repository) {
+		r.actor = &a
+	}
+}
+
+type actorKey struct{}
+
+// ContextWithActor 返回携带操作者的上下文，用于按请求区分操作者的服务，配合 WithContext 使用
+//
+// Example:
+//
+//	ctx := gittag.ContextWithActor(req.Context(), gittag.Actor{Name: user.Name, Email: user.Email, ID: user.ID})
+//	err := repo.WithContext(ctx).CreateTag("v1.2.0")
+func ContextWithActor(ctx context.Context, a Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, a)
+}
+
+// ActorFromContext 返回 ContextWithActor 放入上下文的操作者
+func ActorFromContext(ctx context.Context) (Actor, bool) {
+	a, ok := ctx.Value(actorKey{}).(Actor)
+	return a, ok
+}
+
+// actorFor 返回执行 ctx 中的操作的操作者，上下文中没有时使用 WithActor 指定的操作者
+func (r *Repository) actorFor(ctx context.Context) *Actor {
+	if a, ok := ActorFromContext(ctx); ok {
+		return &a
+	}
+	return r.actor
+}
+
+// actorEnv 返回以操作者作为标签创建者和提交者的环境变量
+func actorEnv(a *Actor) []string {
+	if a == nil {
+		return nil
+	}
+	var env []string
+	if a.Name != "" {
+		env = append(env, "GIT_COMMITTER_NAME="+a.Name, "GIT_AUTHOR_NAME="+a.Name)
+	}
+	if a.Email != "" {
+		env = append(env, "GIT_COMMITTER_EMAIL="+a.Email, "GIT_AUTHOR_EMAIL="+a.Email)
+	}
+	return env
+}
+
+// recordActor 在 git notes 中记录创建附注标签的操作者，没有操作者时什么也不做
+// 标签已经创建，记录失败不影响创建和推送，只在启用 WithVerbose 时输出错误
+func (r *Repository) recordActor(ctx context.Context, name string) {
+	a := r.actorFor(ctx)
+	if a == nil {
+		return
+	}
+	data, err := json.Marshal(a)
+	if err == nil {
+		_, _, err = r.execInput(ctx, strings.NewReader(string(data)), "notes", "--ref="+actorNotesRef, "add", "-f", "-F", "-", tagRef(name))
+	}
+	if err != nil && r.verbose != nil {
+		r.verbose.writeLine(fmt.Sprintf("记录标签 %s 的操作者失败: %v", name, err))
+	}
+}
+
+// TagActor returns who created a tag, as recorded in git notes (refs/notes/gittag/actors) when the
+// tag was created with WithActor or ContextWithActor. The annotated tag's tagger is set to the
+// actor's name and email as well; the note additionally keeps the external ID. Lightweight tags
+// have no tag object to attach a note to, so their actor is not recorded. Recording is
+// best-effort: a failure to write the note does not fail the tag creation.
+// @param tagName - 标签名称，例如："v1.2.0"
+// @return (*Actor, error) - 返回操作者，没有记录或是轻量标签时返回 nil
+//
+// Example:
+//
+//	actor, err := gittag.TagActor("v1.2.0")
+//	if err == nil && actor != nil {
+//		fmt.Printf("v1.2.0 was cut by %s\n", actor)
+//	}
+func TagActor(tagName string) (*Actor, error) {
+	return Default().TagActor(tagName)
+}
+
+// TagActor returns the recorded creator of a tag in the repository, see the package-level TagActor.
+func (r *Repository) TagActor(tagName string) (*Actor, error) {
+	ctx := r.baseContext()
+	object, err := r.revParse(ctx, tagRef(r.tagName(tagName)))
+	if err != nil {
+		return nil, fmt.Errorf("读取标签 %s 失败: %w", tagName, err)
+	}
+	// 轻量标签指向提交，提交上的 note 不属于该标签
+	kind, err := r.output(ctx, "cat-file", "-t", object)
+	if err != nil {
+		return nil, fmt.Errorf("读取标签 %s 失败: %w", tagName, err)
+	}
+	if strings.TrimSpace(string(kind)) != "tag" {
+		return nil, nil
+	}
+	return r.objectActor(ctx, object)
+}
+
+// objectActor 读取对象上的操作者 note，没有时返回 nil
+func (r *Repository) objectActor(ctx context.Context, object string) (*Actor, error) {
+	output, stderr, err := r.exec(ctx, "notes", "--ref="+actorNotesRef, "show", object)
+	if err != nil {
+		if strings.Contains(string(stderr), "no note found") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("读取操作者失败: %w", err)
+	}
+	var a Actor
+	if err := json.Unmarshal(output, &a); err != nil {
+		return nil, fmt.Errorf("操作者记录已损坏: %w", err)
+	}
+	return &a, nil
+}
@@ -0,0 +1,115 @@
+package gittag
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// aliasSection 是记录别名的 git 配置节，别名 "torino" 保存为 [gittag-alias "torino"] tag = v2.3.0
+const aliasSection = "gittag-alias"
+
+// SetAlias gives a release a human name, e.g. the codename "torino" for v2.3.0, so tooling and
+// people can refer to releases by name. The alias is recorded in the repository's local git config
+// ([gittag-alias "torino"]) and replaces any previous target of the same alias.
+// @param name - 别名，规则同标签名称，例如："torino"
+// @param tag - 已存在的标签名称，例如："v2.3.0"
+// @return error - 如果别名无效、标签不存在或写入配置失败，返回相应的错误信息
+//
+// Example:
+//
+//	if err := gittag.SetAlias("torino", "v2.3.0"); err != nil {
+//		log.Fatal(err)
+//	}
+//	tag, _ := gittag.ResolveAlias("torino") // "v2.3.0"
+func SetAlias(name, tag string) error {
+	return Default().SetAlias(name, tag)
+}
+
+// SetAlias names a release of the repository, see the package-level SetAlias.
+func (r *Repository) SetAlias(name, tag string) error {
+	if reason := invalidRefReason(name); reason != "" {
+		return fmt.Errorf("无效的别名 %q: %s", name, reason)
+	}
+	ctx := r.baseContext()
+	if _, err := r.revParse(ctx, tagRef(r.tagName(tag))); err != nil {
+		return &NotFoundError{Pattern: tag}
+	}
+	if err := r.run(ctx, "config", "--local", aliasSection+"."+name+".tag", tag); err != nil {
+		return fmt.Errorf("设置别名 %s 失败: %w", name, err)
+	}
+	return nil
+}
+
+// RemoveAlias 删除别名，别名不存在时不做任何操作，不会影响标签本身
+func RemoveAlias(name string) error {
+	return Default().RemoveAlias(name)
+}
+
+// RemoveAlias removes an alias of the repository, see the package-level RemoveAlias.
+func (r *Repository) RemoveAlias(name string) error {
+	aliases, err := r.Aliases()
+	if err != nil {
+		return err
+	}
+	if _, ok := aliases[name]; !ok {
+		return nil
+	}
+	if err := r.run(r.baseContext(), "config", "--local", "--remove-section", aliasSection+"."+name); err != nil {
+		return fmt.Errorf("删除别名 %s 失败: %w", name, err)
+	}
+	return nil
+}
+
+// Aliases 返回所有别名到标签名称的映射
+func Aliases() (map[string]string, error) {
+	return Default().Aliases()
+}
+
+// Aliases lists the aliases of the repository, see the package-level Aliases.
+func (r *Repository) Aliases() (map[string]string, error) {
+	output, err := r.output(r.baseContext(), "config", "--get-regexp", `^`+aliasSection+`\..*\.tag$`)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return map[string]string{}, nil // 没有任何别名
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取别名失败: %w", err)
+	}
+	aliases := map[string]string{}
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		key, tag, _ := strings.Cut(line, " ")
+		name := strings.TrimSuffix(strings.TrimPrefix(key, aliasSection+"."), ".tag")
+		aliases[name] = tag
+	}
+	return aliases, nil
+}
+
+// ResolveAlias returns the tag an alias refers to.
+// @param name - 别名，例如："torino"
+// @return (string, error) - 返回标签名称，别名不存在时返回 *NotFoundError
+//
+// Example:
+//
+//	tag, err := gittag.ResolveAlias("torino")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	fmt.Println(tag) // v2.3.0
+func ResolveAlias(name string) (string, error) {
+	return Default().ResolveAlias(name)
+}
+
+// ResolveAlias resolves an alias of the repository, see the package-level ResolveAlias.
+func (r *Repository) ResolveAlias(name string) (string, error) {
+	aliases, err := r.Aliases()
+	if err != nil {
+		return "", err
+	}
+	tag, ok := aliases[name]
+	if !ok {
+		return "", &NotFoundError{Pattern: name}
+	}
+	return tag, nil
+}
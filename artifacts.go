@@ -0,0 +1,179 @@
+package gittag
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// artifactNotesRef 是保存制品摘要的 git notes 引用，推送方式：git push origin refs/notes/gittag/artifacts
+const artifactNotesRef = "refs/notes/gittag/artifacts"
+
+// ArtifactDigest 是一个构建产物的名称和 SHA-256 摘要
+type ArtifactDigest struct {
+	// Name 是产物的文件名，不含目录，例如："gittag_linux_amd64.tar.gz"
+	Name string `json:"name"`
+	// SHA256 是十六进制编码的摘要
+	SHA256 string `json:"sha256"`
+}
+
+// DigestFile 计算文件的 SHA-256 摘要，Name 为文件名
+func DigestFile(path string) (ArtifactDigest, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return ArtifactDigest{}, fmt.Errorf("读取制品失败: %w", err)
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return ArtifactDigest{}, fmt.Errorf("读取制品失败: %w", err)
+	}
+	return ArtifactDigest{Name: filepath.Base(path), SHA256: hex.EncodeToString(h.Sum(nil))}, nil
+}
+
+// RecordArtifacts stores the names and SHA-256 digests of built artifacts against a tag in git
+// notes (refs/notes/gittag/artifacts), merging with digests recorded earlier. The note is
+// attached to the tag object, so a rebuilt tag does not inherit the old artifacts.
+// @param tagName - 发布标签名称，例如："v1.2.0"
+// @param artifacts - 产物摘要，同名产物会覆盖之前的记录
+// @return error - 如果标签不存在或写入 notes 失败，返回相应的错误信息
+//
+// Example:
+//
+//	digest, err := gittag.DigestFile("dist/gittag_linux_amd64.tar.gz")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	if err := gittag.RecordArtifacts("v1.2.0", []gittag.ArtifactDigest{digest}); err != nil {
+//		log.Fatal(err)
+//	}
+func RecordArtifacts(tagName string, artifacts []ArtifactDigest) error {
+	return Default().RecordArtifacts(tagName, artifacts)
+}
+
+// RecordArtifacts records artifact digests for a tag in the repository, see the package-level RecordArtifacts.
+func (r *Repository) RecordArtifacts(tagName string, artifacts []ArtifactDigest) error {
+	for _, a := range artifacts {
+		if a.Name == "" || strings.ContainsAny(a.Name, "\n/") {
+			return fmt.Errorf("无效的制品名称 %q", a.Name)
+		}
+		if sum, err := hex.DecodeString(a.SHA256); err != nil || len(sum) != sha256.Size {
+			return fmt.Errorf("制品 %s 的 SHA-256 摘要 %q 无效", a.Name, a.SHA256)
+		}
+	}
+	return r.withLock("RecordArtifacts", func() error {
+		ctx := r.baseContext()
+		object, err := r.revParse(ctx, tagRef(r.tagName(tagName)))
+		if err != nil {
+			return fmt.Errorf("读取标签 %s 失败: %w", tagName, err)
+		}
+		existing, err := r.artifacts(ctx, object)
+		if err != nil {
+			return err
+		}
+		merged := make(map[string]string)
+		for _, a := range append(existing, artifacts...) {
+			merged[a.Name] = strings.ToLower(a.SHA256)
+		}
+		names := make([]string, 0, len(merged))
+		for name := range merged {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		// 与 sha256sum 的输出格式相同，可以直接用 sha256sum -c 校验
+		var b strings.Builder
+		for _, name := range names {
+			fmt.Fprintf(&b, "%s  %s\n", merged[name], name)
+		}
+		_, _, err = r.execInput(ctx, strings.NewReader(b.String()), "notes", "--ref="+artifactNotesRef, "add", "-f", "-F", "-", object)
+		if err != nil {
+			return fmt.Errorf("记录制品摘要失败: %w", err)
+		}
+		return nil
+	})
+}
+
+// Artifacts 返回 RecordArtifacts 为标签记录的所有制品摘要，没有记录时返回空
+func Artifacts(tagName string) ([]ArtifactDigest, error) {
+	return Default().Artifacts(tagName)
+}
+
+// Artifacts lists the artifact digests recorded for a tag, see the package-level Artifacts.
+func (r *Repository) Artifacts(tagName string) ([]ArtifactDigest, error) {
+	ctx := r.baseContext()
+	object, err := r.revParse(ctx, tagRef(r.tagName(tagName)))
+	if err != nil {
+		return nil, fmt.Errorf("读取标签 %s 失败: %w", tagName, err)
+	}
+	return r.artifacts(ctx, object)
+}
+
+// artifacts 读取对象上的制品摘要 note
+func (r *Repository) artifacts(ctx context.Context, object string) ([]ArtifactDigest, error) {
+	output, stderr, err := r.exec(ctx, "notes", "--ref="+artifactNotesRef, "show", object)
+	if err != nil {
+		if strings.Contains(string(stderr), "no note found") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("读取制品摘要失败: %w", err)
+	}
+	var digests []ArtifactDigest
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if sum, name, ok := strings.Cut(line, "  "); ok {
+			digests = append(digests, ArtifactDigest{Name: name, SHA256: sum})
+		}
+	}
+	return digests, nil
+}
+
+// VerifyArtifacts checks that each file's SHA-256 digest matches the digest recorded for a file
+// of the same name with RecordArtifacts, linking a binary back to the exact release tag.
+// @param tagName - 发布标签名称，例如："v1.2.0"
+// @param files - 待校验的文件路径，按文件名查找记录
+// @return error - 如果文件没有记录或摘要不一致，返回列出所有问题的错误信息
+//
+// Example:
+//
+//	if err := gittag.VerifyArtifacts("v1.2.0", "downloads/gittag_linux_amd64.tar.gz"); err != nil {
+//		log.Fatalf("artifact does not belong to v1.2.0: %v", err)
+//	}
+func VerifyArtifacts(tagName string, files ...string) error {
+	return Default().VerifyArtifacts(tagName, files...)
+}
+
+// VerifyArtifacts verifies files against a tag's recorded digests, see the package-level VerifyArtifacts.
+func (r *Repository) VerifyArtifacts(tagName string, files ...string) error {
+	recorded, err := r.Artifacts(tagName)
+	if err != nil {
+		return err
+	}
+	want := make(map[string]string, len(recorded))
+	for _, a := range recorded {
+		want[a.Name] = a.SHA256
+	}
+
+	var errs []error
+	for _, file := range files {
+		got, err := DigestFile(file)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		sum, ok := want[got.Name]
+		switch {
+		case !ok:
+			errs = append(errs, fmt.Errorf("标签 %s 没有记录制品 %s", tagName, got.Name))
+		case sum != got.SHA256:
+			errs = append(errs, fmt.Errorf("制品 %s 的摘要 %s 与标签 %s 记录的 %s 不一致", got.Name, got.SHA256, tagName, sum))
+		}
+	}
+	return errors.Join(errs...)
+}
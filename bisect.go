@@ -0,0 +1,103 @@
+package gittag
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+// BisectTags binary-searches the releases between goodTag and badTag for the first bad one.
+// Candidates are the stable versions with the same prefix ordered by version; each one that is
+// tested is checked out into a temporary detached worktree whose path is passed to test together
+// with the tag, and the worktree is removed afterwards. test reports whether the release is good.
+// @param goodTag - 已知正常的标签，例如："v1.2.0"
+// @param badTag - 已知有问题的标签，例如："v1.8.0"
+// @param test - 检查一个发布，dir 为检出该标签的临时工作树，返回 true 表示正常
+// @return (string, error) - 返回第一个有问题的标签，test 返回错误时立即停止并返回该错误
+//
+// Example:
+//
+//	first, err := gittag.BisectTags("v1.2.0", "v1.8.0", func(tag, dir string) (bool, error) {
+//		cmd := exec.Command("go", "test", "./payments/...")
+//		cmd.Dir = dir
+//		return cmd.Run() == nil, nil
+//	})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	fmt.Println("first bad release:", first)
+func BisectTags(goodTag, badTag string, test func(tag, dir string) (bool, error)) (string, error) {
+	return Default().BisectTags(goodTag, badTag, test)
+}
+
+// BisectTags bisects the repository's releases, see the package-level BisectTags.
+func (r *Repository) BisectTags(goodTag, badTag string, test func(tag, dir string) (bool, error)) (string, error) {
+	if err := r.checkWritable("添加工作树"); err != nil {
+		return "", err
+	}
+	goodPrefix, good, ok := splitVersionTag(goodTag)
+	badPrefix, bad, ok2 := splitVersionTag(badTag)
+	if !ok || !ok2 || goodPrefix != badPrefix {
+		return "", fmt.Errorf("标签 %q 和 %q 必须是同一系列的语义化版本", goodTag, badTag)
+	}
+	if compareSemver(good, bad) >= 0 {
+		return "", fmt.Errorf("正常的标签 %s 必须早于有问题的标签 %s", goodTag, badTag)
+	}
+	for _, tag := range []string{goodTag, badTag} {
+		if _, err := r.revParse(r.baseContext(), tagRef(r.tagName(tag))); err != nil {
+			return "", &NotFoundError{Pattern: tag}
+		}
+	}
+
+	// candidates 只包含 goodTag 和 badTag 之间的正式版本，最后一个是 badTag
+	tags, err := r.FindMany(goodPrefix + "*")
+	if err != nil {
+		return "", err
+	}
+	type release struct {
+		tag     string
+		version semver
+	}
+	var candidates []release
+	for _, tag := range tags {
+		prefix, v, ok := splitVersionTag(tag)
+		if ok && prefix == goodPrefix && len(v.prerelease) == 0 && compareSemver(v, good) > 0 && compareSemver(v, bad) < 0 {
+			candidates = append(candidates, release{tag, v})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return compareSemver(candidates[i].version, candidates[j].version) < 0
+	})
+	candidates = append(candidates, release{badTag, bad})
+
+	// 不变式：lo 之前（含 goodTag）都是正常的，hi 是已知有问题的
+	lo, hi := -1, len(candidates)-1
+	for hi-lo > 1 {
+		mid := lo + (hi-lo)/2
+		ok, err := r.testRelease(candidates[mid].tag, test)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return candidates[hi].tag, nil
+}
+
+// testRelease 将标签检出到临时工作树并运行 test，结束后删除工作树
+func (r *Repository) testRelease(tag string, test func(tag, dir string) (bool, error)) (bool, error) {
+	ctx := r.baseContext()
+	dir, err := os.MkdirTemp("", "gittag-bisect-")
+	if err != nil {
+		return false, fmt.Errorf("创建临时目录失败: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	if err := r.run(ctx, "worktree", "add", "--quiet", "--detach", "--", dir, tagRef(r.tagName(tag))+"^{commit}"); err != nil {
+		return false, fmt.Errorf("检出标签 %s 失败: %w", tag, err)
+	}
+	defer r.run(ctx, "worktree", "remove", "--force", "--", dir)
+	return test(tag, dir)
+}
@@ -0,0 +1,150 @@
+// Package buildinfo 根据 Git 仓库生成构建时注入的版本信息，代替各项目复制粘贴的 shell 片段
+package buildinfo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"go/format"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/afeiship/gittag"
+)
+
+// Info 是注入到构建产物中的版本信息
+type Info struct {
+	// Version 是 git describe --tags 的结果，例如 "v1.2.0" 或 "v1.2.0-3-gabc1234"
+	// 仓库没有标签时为 "v0.0.0-<短 SHA>"，工作区有未提交的修改时追加 "-dirty"
+	Version string `json:"version"`
+	// Commit 是 HEAD 的完整 SHA
+	Commit string `json:"commit"`
+	// Dirty 表示工作区是否有未提交的修改，未跟踪的文件不计入
+	Dirty bool `json:"dirty"`
+	// Date 是 HEAD 的提交时间，同一提交的多次构建结果相同，便于可重现构建
+	Date time.Time `json:"date"`
+}
+
+// Read collects the version information of repo's HEAD.
+// @param ctx - 用于取消 git 命令的上下文
+// @param repo - 仓库，传入 nil 时使用 gittag.Default()
+// @return (*Info, error) - 返回版本信息，HEAD 不可达任何标签时版本为 "v0.0.0-<短 SHA>"，其他 git 错误原样返回
+//
+// Example:
+//
+//	repo, err := gittag.Open(".")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	info, err := buildinfo.Read(ctx, repo)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	fmt.Println(info.LDFlags("main"))
+func Read(ctx context.Context, repo *gittag.Repository) (*Info, error) {
+	if repo == nil {
+		repo = gittag.Default()
+	}
+	stdout, _, err := repo.Git(ctx, "log", "-1", "--format=%H%x1f%cI", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("读取 HEAD 失败: %w", err)
+	}
+	commit, date, _ := strings.Cut(strings.TrimSpace(string(stdout)), "\x1f")
+	info := &Info{Commit: commit}
+	if info.Date, err = time.Parse(time.RFC3339, date); err != nil {
+		return nil, fmt.Errorf("无法解析提交时间 %q: %w", date, err)
+	}
+
+	stdout, _, err = repo.Git(ctx, "status", "--porcelain", "--untracked-files=no")
+	if err != nil {
+		return nil, fmt.Errorf("读取工作区状态失败: %w", err)
+	}
+	info.Dirty = len(bytes.TrimSpace(stdout)) > 0
+
+	info.Version, err = repo.WithContext(ctx).Describe("HEAD")
+	if errors.Is(err, gittag.ErrTagNotFound) {
+		info.Version = "v0.0.0-" + commit[:min(12, len(commit))]
+	} else if err != nil {
+		return nil, err
+	}
+	if info.Dirty {
+		info.Version += "-dirty"
+	}
+	return info, nil
+}
+
+// LDFlags returns -X flags that set the string variables Version, Commit, Date (RFC 3339) and
+// Dirty ("true" or "false") of the package with import path pkg, ready for go build -ldflags.
+// @param pkg - 变量所在包的导入路径，main 包为 "main"，例如："example.com/app/internal/version"
+// @return string - 返回 -ldflags 的值
+//
+// Example:
+//
+//	cmd := exec.Command("go", "build", "-ldflags", info.LDFlags("main"), "./cmd/app")
+func (i *Info) LDFlags(pkg string) string {
+	var flags []string
+	for _, kv := range [][2]string{
+		{"Version", i.Version},
+		{"Commit", i.Commit},
+		{"Date", i.Date.UTC().Format(time.RFC3339)},
+		{"Dirty", strconv.FormatBool(i.Dirty)},
+	} {
+		flags = append(flags, "-X "+pkg+"."+kv[0]+"="+kv[1])
+	}
+	return strings.Join(flags, " ")
+}
+
+var goFileTemplate = template.Must(template.New("buildinfo").Parse(`// Code generated by gittag/buildinfo. DO NOT EDIT.
+
+package {{.Package}}
+
+import "time"
+
+const (
+	// Version 是构建时的 git describe 版本
+	Version = {{printf "%q" .Info.Version}}
+	// Commit 是构建时 HEAD 的完整 SHA
+	Commit = {{printf "%q" .Info.Commit}}
+	// Dirty 表示构建时工作区是否有未提交的修改
+	Dirty = {{.Info.Dirty}}
+)
+
+// Date 是构建时 HEAD 的提交时间
+var Date = time.Date({{.Date.Year}}, {{printf "%d" .Date.Month}}, {{.Date.Day}}, {{.Date.Hour}}, {{.Date.Minute}}, {{.Date.Second}}, 0, time.UTC)
+`))
+
+// WriteGoFile writes a generated Go file declaring Version, Commit, Dirty and Date for projects
+// that prefer go:generate over -ldflags.
+// @param path - 生成的文件路径，例如："internal/version/version_gen.go"
+// @param pkg - 生成文件的包名，例如："version"
+// @return error - 如果包名无效或写入失败，返回相应的错误信息
+//
+// Example:
+//
+//	//go:generate go run ./tools/stamp
+//	if err := info.WriteGoFile("internal/version/version_gen.go", "version"); err != nil {
+//		log.Fatal(err)
+//	}
+func (i *Info) WriteGoFile(path, pkg string) error {
+	var buf bytes.Buffer
+	data := struct {
+		Package string
+		Info    *Info
+		Date    time.Time
+	}{pkg, i, i.Date.UTC()}
+	if err := goFileTemplate.Execute(&buf, data); err != nil {
+		return err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("生成的代码无效，请检查包名 %q: %w", pkg, err)
+	}
+	if err := os.WriteFile(path, src, 0o644); err != nil {
+		return fmt.Errorf("写入 %s 失败: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package gittag
+
+import (
+	"errors"
+	"fmt"
+)
+
+// bumpLevel 是 bump 递增的版本段
+type bumpLevel int
+
+const (
+	bumpMajor bumpLevel = iota
+	bumpMinor
+	bumpPatch
+)
+
+// BumpPatch reads the highest stable semantic version tag ("v*", prereleases ignored), creates the
+// next patch release on HEAD and pushes it, e.g. v1.2.3 becomes v1.2.4. The first release of a
+// repository without version tags is v0.0.1. Reading and tagging happen under the repository
+// lock, so concurrent bumps cannot create the same version twice. Bumping only applies to
+// semantic versions; repositories opened with another WithVersionScheme get an error. Legacy
+// tags such as "v1.2" or "v01.3.0" are read through Normalize, so they count as v1.2.0 and v1.3.0.
+// @return (string, error) - 返回创建的标签，例如："v1.2.4"
+//
+// Example:
+//
+//	tag, err := gittag.BumpPatch()
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	fmt.Println("released", tag)
+func BumpPatch() (string, error) {
+	return Default().BumpPatch()
+}
+
+// BumpMinor 创建下一个次版本并推送，例如 v1.2.3 -> v1.3.0，没有版本标签时为 v0.1.0，参见 BumpPatch
+func BumpMinor() (string, error) {
+	return Default().BumpMinor()
+}
+
+// BumpMajor 创建下一个主版本并推送，例如 v1.2.3 -> v2.0.0，没有版本标签时为 v1.0.0，参见 BumpPatch
+func BumpMajor() (string, error) {
+	return Default().BumpMajor()
+}
+
+// BumpPatch creates the next patch release in the repository, see the package-level BumpPatch.
+func (r *Repository) BumpPatch() (string, error) {
+	return r.bump(bumpPatch)
+}
+
+// BumpMinor creates the next minor release in the repository, see the package-level BumpMinor.
+func (r *Repository) BumpMinor() (string, error) {
+	return r.bump(bumpMinor)
+}
+
+// BumpMajor creates the next major release in the repository, see the package-level BumpMajor.
+func (r *Repository) BumpMajor() (string, error) {
+	return r.bump(bumpMajor)
+}
+
+func (r *Repository) bump(level bumpLevel) (string, error) {
+	return withLockResult(r, level.operation(), func() (string, error) {
+		latest, err := r.latestStable()
+		if err != nil {
+			return "", err
+		}
+		switch level {
+		case bumpMajor:
+			latest = semver{major: latest.major + 1}
+		case bumpMinor:
+			latest = semver{major: latest.major, minor: latest.minor + 1}
+		default:
+			latest = semver{major: latest.major, minor: latest.minor, patch: latest.patch + 1}
+		}
+		next := fmt.Sprintf("v%d.%d.%d", latest.major, latest.minor, latest.patch)
+		if err := r.createTag(next); err != nil {
+			return "", err
+		}
+		return next, nil
+	})
+}
+
+// operation 返回记录在幂等记录中的操作名称
+func (l bumpLevel) operation() string {
+	switch l {
+	case bumpMajor:
+		return "BumpMajor"
+	case bumpMinor:
+		return "BumpMinor"
+	}
+	return "BumpPatch"
+}
+
+// latestStable 返回最高的正式版本，没有版本标签时返回 v0.0.0，"v1.2" 这类旧写法经 Normalize 规范化后参与比较
+// 递增主、次、修订号只对语义化版本有意义，仓库使用其他版本方案时返回错误
+func (r *Repository) latestStable() (semver, error) {
+	if r.versionScheme() != SemVer {
+		return semver{}, errors.New("BumpPatch、BumpMinor 和 BumpMajor 只支持 SemVer 版本方案，请直接使用 CreateTag 创建下一个版本")
+	}
+	tags, err := r.FindMany("v*")
+	var notFound *NotFoundError
+	if errors.As(err, &notFound) {
+		return semver{}, nil
+	}
+	if err != nil {
+		return semver{}, err
+	}
+	var latest semver
+	for _, tag := range tags {
+		if v, ok := parseLooseSemver(tag); ok && len(v.prerelease) == 0 && compareSemver(v, latest) > 0 {
+			latest = v
+		}
+	}
+	return latest, nil
+}
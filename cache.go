@@ -0,0 +1,161 @@
+package gittag
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// cacheVersion 在缓存格式变化时递增，旧版本的缓存会被丢弃
+const cacheVersion = 4
+
+// maxCacheMisses 超过该数量的未命中时直接按模式重新读取，避免命令行参数过长
+const maxCacheMisses = 200
+
+// cacheKey 是 TagInfo 缓存在 Store 中的键
+const cacheKey = "cache/taginfo.json"
+
+// tagInfoCache 是缓存 cacheKey 的内容
+type tagInfoCache struct {
+	Version int                `json:"version"`
+	Tags    map[string]TagInfo `json:"tags"`
+}
+
+// WithCache 启用 TagInfo 的磁盘缓存，缓存保存在 .git/gittag/cache 下（或 WithStore 指定的 Store 中）
+// 每个条目以标签引用指向的 SHA 校验，标签被移动、删除或重建后会自动失效
+func WithCache() Option {
+	return func(r *Repository) {
+		r.cache = true
+	}
+}
+
+// ClearCache 删除仓库的 TagInfo 磁盘缓存
+func (r *Repository) ClearCache() error {
+	store, err := r.stateStore()
+	if err != nil {
+		return err
+	}
+	if err := store.Delete(r.baseContext(), cacheKey); err != nil {
+		return fmt.Errorf("删除缓存失败: %w", err)
+	}
+	return nil
+}
+
+// cachedListInfo 先读取标签名和 SHA，只为缓存中不存在或已变化的标签读取完整信息
+func (r *Repository) cachedListInfo(pattern string) ([]TagInfo, error) {
+	output, err := r.output(r.baseContext(), "tag", "-l", "--format=%(objectname) %(refname:strip=2)", "--", pattern)
+	if err != nil {
+		return nil, fmt.Errorf("读取标签信息失败: %w", err)
+	}
+
+	cache := r.loadCache()
+	type ref struct{ name, object string }
+	var refs []ref
+	var misses []string
+	current := map[string]bool{}
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		object, name, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		refs = append(refs, ref{name, object})
+		current[name] = true
+		if cached, hit := cache.Tags[name]; !hit || cached.Object != object {
+			misses = append(misses, name)
+		}
+	}
+
+	// 删除匹配 pattern 但已不存在的标签，其他模式的条目不受影响
+	changed := false
+	if match, err := globRegexp(pattern); err == nil {
+		for name := range cache.Tags {
+			if !current[name] && match.MatchString(name) {
+				delete(cache.Tags, name)
+				changed = true
+			}
+		}
+	}
+
+	if len(misses) > 0 {
+		query := misses
+		if len(misses) > maxCacheMisses {
+			query = []string{pattern}
+		}
+		fresh, err := r.queryTagInfo(query...)
+		if err != nil {
+			return nil, err
+		}
+		for _, info := range fresh {
+			cache.Tags[info.Name] = info
+		}
+		changed = true
+	}
+	if changed {
+		r.saveCache(cache)
+	}
+
+	infos := make([]TagInfo, 0, len(refs))
+	for _, ref := range refs {
+		if info, ok := cache.Tags[ref.name]; ok && info.Object == ref.object {
+			infos = append(infos, info)
+		}
+	}
+	return infos, nil
+}
+
+// loadCache 读取缓存，多个工作树共享同一个缓存，缓存不存在或损坏时返回空缓存
+func (r *Repository) loadCache() *tagInfoCache {
+	empty := &tagInfoCache{Version: cacheVersion, Tags: map[string]TagInfo{}}
+	store, err := r.stateStore()
+	if err != nil {
+		return empty
+	}
+	data, err := store.Get(r.baseContext(), cacheKey)
+	if err != nil {
+		return empty
+	}
+	cache := &tagInfoCache{}
+	if err := json.Unmarshal(data, cache); err != nil || cache.Version != cacheVersion || cache.Tags == nil {
+		return empty
+	}
+	return cache
+}
+
+// saveCache 原子地写入缓存，写入失败只会导致下次重新读取，因此忽略错误；只读的仓库不写入缓存
+func (r *Repository) saveCache(cache *tagInfoCache) {
+	if r.readOnly {
+		return
+	}
+	store, err := r.stateStore()
+	if err != nil {
+		return
+	}
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return
+	}
+	store.Put(r.baseContext(), cacheKey, data)
+}
+
+// writeFileAtomic 先写入同目录下的临时文件再重命名，读取方不会看到写了一半的文件
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,138 @@
+package gittag
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Capability 是受限仓库句柄允许执行的一类操作，可以按位组合
+type Capability uint
+
+const (
+	// CapRead 允许查找、列出、解析标签等只读操作
+	CapRead Capability = 1 << iota
+	// CapCreate 允许创建和推送标签、记录制品摘要
+	CapCreate
+	// CapDelete 允许删除本地和远程标签
+	CapDelete
+	// CapFetch 允许从远程获取标签，会覆盖本地同名标签
+	CapFetch
+	// CapConfig 允许修改仓库配置，例如 Freeze、SetEOL
+	CapConfig
+	// CapCommit 允许提交文件，例如 SyncVersionFiles
+	CapCommit
+
+	// CapAll 包含所有能力，无法归类的 git 命令（例如通过 Git 执行的 update-ref）只允许拥有 CapAll 的句柄执行
+	CapAll = CapRead | CapCreate | CapDelete | CapFetch | CapConfig | CapCommit
+)
+
+var capabilityNames = []struct {
+	cap  Capability
+	name string
+}{
+	{CapRead, "read"}, {CapCreate, "create"}, {CapDelete, "delete"},
+	{CapFetch, "fetch"}, {CapConfig, "config"}, {CapCommit, "commit"},
+}
+
+func (c Capability) String() string {
+	var names []string
+	for _, n := range capabilityNames {
+		if c&n.cap != 0 {
+			names = append(names, n.name)
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, "|")
+}
+
+// Restrict returns a handle to the same repository that may only perform the given operations,
+// e.g. to hand a plugin a handle that can create and find tags but never delete them. The
+// restriction is enforced for every git command the handle runs, including Git, and can only be
+// narrowed further: restricting a restricted handle never grants capabilities it did not have.
+// @param caps - 允许的能力，例如 CapRead、CapCreate；几乎所有操作都需要读取仓库，通常应包含 CapRead
+// @return *Repository - 返回受限的仓库句柄，原句柄不受影响
+//
+// Example:
+//
+//	plugin.Run(repo.Restrict(gittag.CapRead, gittag.CapCreate))
+//	// inside the plugin: repo.DeleteTag("v1.0.0") -> ErrNotPermitted
+func (r *Repository) Restrict(caps ...Capability) *Repository {
+	var allowed Capability
+	for _, c := range caps {
+		allowed |= c
+	}
+	restricted := *r
+	restricted.caps = r.capabilities() & allowed
+	restricted.restricted = true
+	return &restricted
+}
+
+// Capabilities 返回该句柄允许的能力，未受限的句柄返回 CapAll
+func (r *Repository) Capabilities() Capability {
+	return r.capabilities()
+}
+
+func (r *Repository) capabilities() Capability {
+	if !r.restricted {
+		return CapAll
+	}
+	return r.caps
+}
+
+// checkCapability 检查句柄是否有执行 git 命令所需的能力
+func (r *Repository) checkCapability(args []string) error {
+	if !r.restricted || len(args) == 0 {
+		return nil
+	}
+	need := commandCapability(args)
+	if r.caps&need != need {
+		return fmt.Errorf("%w: git %s 需要 %s 能力，该句柄只有 %s", ErrNotPermitted, args[0], need, r.caps)
+	}
+	return nil
+}
+
+// commandCapability 返回执行 git 命令所需的能力
+func commandCapability(args []string) Capability {
+	if isReadOnlyCommand(args) {
+		return CapRead
+	}
+	rest := args[1:]
+	hasAny := func(flags ...string) bool {
+		for _, arg := range rest {
+			for _, flag := range flags {
+				if arg == flag {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	switch args[0] {
+	case "tag":
+		if hasAny("-d", "--delete") {
+			return CapDelete
+		}
+		return CapCreate
+	case "push":
+		for _, arg := range rest {
+			if strings.HasPrefix(arg, ":") {
+				return CapDelete
+			}
+		}
+		if hasAny("-d", "--delete", "--prune", "--mirror") {
+			return CapDelete
+		}
+		return CapCreate
+	case "notes":
+		return CapCreate
+	case "fetch":
+		return CapFetch
+	case "config":
+		return CapConfig
+	case "add", "commit":
+		return CapCommit
+	}
+	return CapAll
+}
@@ -0,0 +1,159 @@
+package gittag
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// changelogCommit 是变更日志中的一条提交
+type changelogCommit struct {
+	sha     string
+	subject string
+}
+
+// Changelog renders the commits between two revisions as a Markdown list, newest first.
+// Merge commits are skipped.
+// @param from - 起始版本（不包含），例如上一个标签 "v1.1.0"，为空时从第一个提交开始
+// @param to - 结束版本（包含），例如 "v1.2.0"，为空时为 HEAD
+// @return (string, error) - 返回 Markdown 格式的变更日志，没有提交时返回空字符串
+//
+// Example:
+//
+//	notes, err := gittag.Changelog("v1.1.0", "v1.2.0")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	fmt.Print(notes)
+//	// - feat: add FindLatest (a1b2c3d)
+//	// - fix: handle empty patterns (d4e5f6a)
+func Changelog(from, to string) (string, error) {
+	return Default().Changelog(from, to)
+}
+
+// Changelog renders the commits between two revisions in the repository, see the package-level Changelog.
+func (r *Repository) Changelog(from, to string) (string, error) {
+	return r.renderChangelog(from, to)
+}
+
+// ChangelogForPath renders the changelog between two revisions restricted to commits that touch
+// path, so a module of a monorepo gets release notes of only its own changes.
+// @param path - 相对仓库根目录的路径，例如："services/payments"
+// @param from - 起始版本（不包含），例如："services/payments/v1.1.0"，为空时从第一个提交开始
+// @param to - 结束版本（包含），为空时为 HEAD
+// @return (string, error) - 返回 Markdown 格式的变更日志，没有相关提交时返回空字符串
+//
+// Example:
+//
+//	notes, err := gittag.ChangelogForPath("services/payments", "services/payments/v1.1.0", "")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	fmt.Print(notes)
+func ChangelogForPath(path, from, to string) (string, error) {
+	return Default().ChangelogForPath(path, from, to)
+}
+
+// ChangelogForPath renders a path-scoped changelog in the repository, see the package-level ChangelogForPath.
+func (r *Repository) ChangelogForPath(path, from, to string) (string, error) {
+	path = strings.Trim(filepath.ToSlash(path), "/")
+	if path == "" || path == "." {
+		return r.renderChangelog(from, to)
+	}
+	return r.renderChangelog(from, to, path)
+}
+
+// renderChangelog 将 from..to 之间的提交渲染为 Markdown 列表
+func (r *Repository) renderChangelog(from, to string, paths ...string) (string, error) {
+	commits, err := r.changelogCommits(from, to, paths...)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for _, c := range commits {
+		fmt.Fprintf(&b, "- %s (%s)\n", c.subject, c.sha)
+	}
+	return b.String(), nil
+}
+
+// changelogCommits 返回 from..to 之间的非合并提交，指定 paths 时只返回修改了这些路径的提交
+// paths 相对仓库根目录，与仓库打开时所在的子目录无关
+func (r *Repository) changelogCommits(from, to string, paths ...string) ([]changelogCommit, error) {
+	if to == "" {
+		to = "HEAD"
+	}
+	rev := to
+	if from != "" {
+		rev = from + ".." + to
+	}
+	args := []string{"log", "--no-merges", "--format=%h%x1f%s", "--end-of-options", rev, "--"}
+	for _, path := range paths {
+		args = append(args, ":(top,literal)"+path)
+	}
+	output, err := r.output(r.baseContext(), args...)
+	if err != nil {
+		return nil, fmt.Errorf("读取提交记录失败: %w", err)
+	}
+	var commits []changelogCommit
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if sha, subject, ok := strings.Cut(line, "\x1f"); ok {
+			commits = append(commits, changelogCommit{sha: sha, subject: subject})
+		}
+	}
+	return commits, nil
+}
+
+// CreateWithChangelog creates an annotated tag on HEAD (or the WithTarget commit) whose message
+// contains the changelog from the previous release in the same series up to the tagged commit,
+// so `git show <tag>` shows the full release notes.
+// @param tagName - 新标签名称，例如："v1.2.0"
+// @param opts - 可选配置，例如 WithPush；WithMessage 会作为变更日志之前的标题
+// @return error - 如果生成变更日志或创建标签失败，返回相应的错误信息
+//
+// Example:
+//
+//	if err := gittag.CreateWithChangelog("v1.2.0", gittag.WithPush()); err != nil {
+//		log.Fatal(err)
+//	}
+func CreateWithChangelog(tagName string, opts ...CreateOption) error {
+	return Default().CreateWithChangelog(tagName, opts...)
+}
+
+// CreateWithChangelog creates a tag annotated with release notes, see the package-level CreateWithChangelog.
+func (r *Repository) CreateWithChangelog(tagName string, opts ...CreateOption) error {
+	cfg, err := r.preTag(tagName, newCreateConfig(opts...))
+	if err != nil {
+		return err
+	}
+	return r.withTagLock("CreateWithChangelog", tagName, func() error {
+		message, err := r.releaseNotes(tagName, cfg.message, cfg.target)
+		if err != nil {
+			return err
+		}
+		cfg.message, cfg.messageFile = message, ""
+		return r.create(tagName, cfg)
+	})
+}
+
+// releaseNotes 生成包含标题和截至 target 的变更日志的标签信息，target 为空时为 HEAD
+func (r *Repository) releaseNotes(tagName, title, target string) (string, error) {
+	previous, err := r.PreviousTag(tagName)
+	var notFound *NotFoundError
+	if err != nil && !errors.As(err, &notFound) {
+		return "", err
+	}
+	changelog, err := r.Changelog(previous, target)
+	if err != nil {
+		return "", err
+	}
+	if title == "" {
+		if title, err = r.defaultMessage(tagName); err != nil {
+			return "", err
+		}
+	}
+	if changelog == "" {
+		return title + "\n", nil
+	}
+	return title + "\n\n" + changelog, nil
+}
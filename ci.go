@@ -0,0 +1,129 @@
+package gittag
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// CIInfo 描述当前 CI 流水线的信息
+type CIInfo struct {
+	// Provider 是 CI 名称，例如 "GitHub Actions"
+	Provider string `json:"provider"`
+	// RunURL 是本次运行的页面地址
+	RunURL string `json:"runUrl,omitempty"`
+	// BuildNumber 是流水线编号
+	BuildNumber string `json:"buildNumber,omitempty"`
+	// Actor 是触发流水线的用户
+	Actor string `json:"actor,omitempty"`
+	// Commit 是流水线检出的提交 SHA
+	Commit string `json:"commit,omitempty"`
+	// Ref 是触发流水线的分支或引用
+	Ref string `json:"ref,omitempty"`
+}
+
+// DetectCI detects GitHub Actions, GitLab CI and Jenkins from the environment.
+// @return (*CIInfo, bool) - 返回 CI 信息，不在受支持的 CI 中运行时返回 false
+//
+// Example:
+//
+//	if ci, ok := gittag.DetectCI(); ok {
+//		fmt.Printf("running in %s: %s\n", ci.Provider, ci.RunURL)
+//	}
+func DetectCI() (*CIInfo, bool) {
+	switch {
+	case os.Getenv("GITHUB_ACTIONS") == "true":
+		info := &CIInfo{
+			Provider:    "GitHub Actions",
+			BuildNumber: os.Getenv("GITHUB_RUN_NUMBER"),
+			Actor:       os.Getenv("GITHUB_ACTOR"),
+			Commit:      os.Getenv("GITHUB_SHA"),
+			Ref:         os.Getenv("GITHUB_REF"),
+		}
+		if server, repo, run := os.Getenv("GITHUB_SERVER_URL"), os.Getenv("GITHUB_REPOSITORY"), os.Getenv("GITHUB_RUN_ID"); server != "" && repo != "" && run != "" {
+			info.RunURL = strings.TrimSuffix(server, "/") + "/" + repo + "/actions/runs/" + run
+		}
+		return info, true
+	case os.Getenv("GITLAB_CI") == "true":
+		return &CIInfo{
+			Provider:    "GitLab CI",
+			RunURL:      os.Getenv("CI_PIPELINE_URL"),
+			BuildNumber: os.Getenv("CI_PIPELINE_IID"),
+			Actor:       os.Getenv("GITLAB_USER_LOGIN"),
+			Commit:      os.Getenv("CI_COMMIT_SHA"),
+			Ref:         os.Getenv("CI_COMMIT_REF_NAME"),
+		}, true
+	case os.Getenv("JENKINS_URL") != "":
+		return &CIInfo{
+			Provider:    "Jenkins",
+			RunURL:      os.Getenv("BUILD_URL"),
+			BuildNumber: os.Getenv("BUILD_NUMBER"),
+			Actor:       os.Getenv("BUILD_USER_ID"),
+			Commit:      os.Getenv("GIT_COMMIT"),
+			Ref:         os.Getenv("GIT_BRANCH"),
+		}, true
+	}
+	return nil, false
+}
+
+// ciMessageTemplate 是 CreateFromCI 生成的标签信息，Title 为 WithMessage 指定的标题或默认标题
+var ciMessageTemplate = template.Must(template.New("ci").Parse(`{{.Title}}
+
+CI: {{.CI.Provider}}
+{{- if .CI.RunURL}}
+Run: {{.CI.RunURL}}{{end}}
+{{- if .CI.BuildNumber}}
+Build: {{.CI.BuildNumber}}{{end}}
+{{- if .CI.Actor}}
+Actor: {{.CI.Actor}}{{end}}
+Commit: {{.CI.Commit}}
+`))
+
+// CreateFromCI creates a tag on the commit the CI pipeline checked out (not whatever HEAD is now),
+// with the run URL, build number and actor recorded in the tag message.
+// @param tagName - 标签名称，例如："v1.2.0"
+// @param opts - 可选配置，例如 WithPush；WithMessage 会作为标签信息的标题
+// @return error - 如果不在 CI 中运行、CI 未提供提交 SHA 或创建失败，返回相应的错误信息
+//
+// Example:
+//
+//	// In a GitHub Actions release job
+//	if err := gittag.CreateFromCI("v1.2.0", gittag.WithPush()); err != nil {
+//		log.Fatal(err)
+//	}
+func CreateFromCI(tagName string, opts ...CreateOption) error {
+	return Default().CreateFromCI(tagName, opts...)
+}
+
+// CreateFromCI creates a tag from CI metadata in the repository, see the package-level CreateFromCI.
+func (r *Repository) CreateFromCI(tagName string, opts ...CreateOption) error {
+	ci, ok := DetectCI()
+	if !ok {
+		return errors.New("未检测到受支持的 CI 环境（GitHub Actions、GitLab CI、Jenkins）")
+	}
+	if ci.Commit == "" {
+		return errors.New("CI 环境未提供检出的提交 SHA，无法确定要标记的提交")
+	}
+
+	cfg := newCreateConfig(opts...)
+	title := cfg.message
+	if title == "" {
+		var err error
+		if title, err = r.defaultMessage(tagName); err != nil {
+			return err
+		}
+	}
+	var b strings.Builder
+	if err := ciMessageTemplate.Execute(&b, map[string]any{"Title": title, "CI": ci}); err != nil {
+		return err
+	}
+	cfg.message, cfg.messageFile, cfg.target = b.String(), "", ci.Commit
+	cfg, err := r.preTag(tagName, cfg)
+	if err != nil {
+		return err
+	}
+	return r.withTagLock("CreateFromCI", tagName, func() error {
+		return r.create(tagName, cfg)
+	})
+}
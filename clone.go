@@ -0,0 +1,164 @@
+package gittag
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// cloneConfig 保存 CloneTemp 的可选配置
+type cloneConfig struct {
+	parentDir string
+	filter    string
+	tagsOnly  bool
+	sparse    []string
+	repoOpts  []Option
+}
+
+// CloneOption 用于配置 CloneTemp 的行为
+type CloneOption func(*cloneConfig)
+
+// WithTempDir 指定临时克隆目录所在的父目录，默认使用系统临时目录
+func WithTempDir(dir string) CloneOption {
+	return func(c *cloneConfig) {
+		c.parentDir = dir
+	}
+}
+
+// WithFilter 指定部分克隆使用的对象过滤器，例如："blob:none"，默认为 "tree:0"
+// 传入空字符串表示完整克隆
+func WithFilter(spec string) CloneOption {
+	return func(c *cloneConfig) {
+		c.filter = spec
+	}
+}
+
+// WithTagsOnly 只获取标签及其指向的历史，不获取任何分支
+// 适用于只需要列出、描述标签或生成变更日志的 CI 场景
+func WithTagsOnly() CloneOption {
+	return func(c *cloneConfig) {
+		c.tagsOnly = true
+	}
+}
+
+// WithSkipLFS 不下载 Git LFS 对象，检出的文件保留为指针文件
+// 克隆以及返回的仓库执行的所有 git 命令都会设置 GIT_LFS_SKIP_SMUDGE=1
+func WithSkipLFS() CloneOption {
+	return func(c *cloneConfig) {
+		c.repoOpts = append(c.repoOpts, WithEnv("GIT_LFS_SKIP_SMUDGE=1"))
+	}
+}
+
+// WithSparse 以稀疏检出的方式检出默认分支，只包含 paths 中的目录以及仓库根目录下的文件
+// 默认克隆没有工作区，不能与 WithTagsOnly 同时使用
+func WithSparse(paths ...string) CloneOption {
+	return func(c *cloneConfig) {
+		c.sparse = append(c.sparse, paths...)
+	}
+}
+
+// WithRepositoryOptions 指定克隆时以及返回的仓库使用的配置，例如 WithEnv
+func WithRepositoryOptions(opts ...Option) CloneOption {
+	return func(c *cloneConfig) {
+		c.repoOpts = append(c.repoOpts, opts...)
+	}
+}
+
+// CloneTemp clones remoteURL into a temporary directory with just enough data for tag operations.
+// The clone is treeless (--filter=tree:0) and has no working tree, so commits and tags are
+// available while trees and blobs are only fetched on demand. For multi-gigabyte repositories
+// WithSkipLFS avoids downloading LFS objects and WithSparse checks out only the listed directories.
+// @param ctx - 用于取消克隆操作的上下文
+// @param remoteURL - 远程仓库地址，例如："https://github.com/afeiship/go-git-tag.git"
+// @param opts - 可选配置，例如 WithTempDir、WithFilter、WithTagsOnly、WithSkipLFS、WithSparse
+// @return (*Repository, func(), error) - 返回临时仓库、用于删除临时目录的清理函数以及错误信息
+//
+// Example:
+//
+//	// Tag a repository without having it checked out
+//	repo, cleanup, err := gittag.CloneTemp(ctx, "git@github.com:afeiship/go-git-tag.git")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer cleanup()
+//
+//	if err := repo.CreateTag("v1.0.0"); err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	// Fetch only tags and commits for listing or changelog generation
+//	repo, cleanup, err = gittag.CloneTemp(ctx, url, gittag.WithTagsOnly(), gittag.WithFilter("blob:none"))
+//
+//	// Materialize only docs/ of a large LFS-backed repository
+//	repo, cleanup, err = gittag.CloneTemp(ctx, url, gittag.WithSkipLFS(), gittag.WithSparse("docs"))
+func CloneTemp(ctx context.Context, remoteURL string, opts ...CloneOption) (*Repository, func(), error) {
+	cfg := &cloneConfig{filter: "tree:0"}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.tagsOnly && len(cfg.sparse) > 0 {
+		return nil, nil, fmt.Errorf("WithSparse 不能与 WithTagsOnly 同时使用")
+	}
+
+	dir, err := os.MkdirTemp(cfg.parentDir, "gittag-clone-")
+	if err != nil {
+		return nil, nil, fmt.Errorf("创建临时目录失败: %w", err)
+	}
+	cleanup := func() {
+		os.RemoveAll(dir)
+	}
+
+	repo := newRepository(dir, cfg.repoOpts...)
+	// 克隆只写入新建的临时目录，只读仓库也允许
+	readOnly := repo.readOnly
+	repo.readOnly = false
+	defer func() { repo.readOnly = readOnly }()
+	if cfg.tagsOnly {
+		err = repo.initTagsOnly(ctx, remoteURL, cfg.filter)
+	} else {
+		args := []string{"clone", "--quiet", "--no-checkout", "--origin", repo.remoteName()}
+		if cfg.filter != "" {
+			args = append(args, "--filter="+cfg.filter)
+		}
+		err = repo.run(ctx, append(args, "--", remoteURL, dir)...)
+		if err == nil && len(cfg.sparse) > 0 {
+			err = repo.sparseCheckout(ctx, cfg.sparse, "HEAD")
+		}
+	}
+	if err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("克隆仓库 %s 失败: %w", Redact(remoteURL), err)
+	}
+	return repo, cleanup, nil
+}
+
+// initTagsOnly 初始化一个只跟踪远程标签的仓库并获取所有标签
+func (r *Repository) initTagsOnly(ctx context.Context, remoteURL, filter string) error {
+	steps := [][]string{
+		{"init", "--quiet"},
+		{"remote", "add", "--", r.remoteName(), remoteURL},
+		{"config", "remote." + r.remoteName() + ".fetch", tagRefspec},
+	}
+	for _, args := range steps {
+		if err := r.run(ctx, args...); err != nil {
+			return err
+		}
+	}
+	args := []string{"fetch", "--quiet"}
+	if filter != "" {
+		args = append(args, "--filter="+filter)
+	}
+	return r.run(ctx, append(args, r.remoteName())...)
+}
+
+// sparseCheckout 以 cone 模式只检出 paths 中的目录，然后检出 rev
+func (r *Repository) sparseCheckout(ctx context.Context, paths []string, rev string) error {
+	args := []string{"sparse-checkout", "set", "--cone", "--"}
+	if err := r.run(ctx, append(args, paths...)...); err != nil {
+		return fmt.Errorf("设置稀疏检出失败: %w", err)
+	}
+	if err := r.run(ctx, "checkout", "--quiet", rev); err != nil {
+		return fmt.Errorf("检出 %s 失败: %w", rev, err)
+	}
+	return nil
+}
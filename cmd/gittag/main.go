@@ -0,0 +1,290 @@
+// Command gittag is a command-line frontend for the gittag package, so tags can be created,
+// deleted and listed from shell scripts and CI without writing Go.
+//
+//	gittag [-C dir] [-remote name] [-v] [-n] <command> [flags] [args]
+//
+//	gittag create -m "Release 1.2.3" -push v1.2.3
+//	gittag delete -pattern 'v1.*' -remote
+//	gittag list -sort version-desc -json
+//	gittag doctor
+//
+// Errors are printed to stderr together with a suggestion from gittag.Hint when one is
+// available. The exit status is 0 on success, 1 when the operation fails and 2 for usage errors.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/afeiship/gittag"
+)
+
+// errUsage 表示命令行参数错误，退出码为 2
+var errUsage = errors.New("usage")
+
+// sortOrders 是 list -sort 的取值
+var sortOrders = map[string]gittag.SortOrder{
+	"name":         gittag.SortByName,
+	"version":      gittag.SortByVersion,
+	"version-desc": gittag.SortByVersionDesc,
+	"created":      gittag.SortByCreated,
+	"created-desc": gittag.SortByCreatedDesc,
+}
+
+const usage = `用法: gittag [-C dir] [-remote name] [-v] [-n] <command> [flags] [args]
+
+命令:
+  create   创建标签，-push 同时推送到远程
+  delete   删除标签或匹配 -pattern 的标签，-remote 同时删除远程标签
+  list     列出匹配 -pattern 的标签
+  doctor   检查发布所需的 git、身份、远程和签名配置
+
+运行 gittag <command> -h 查看命令的参数
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run 执行命令并返回退出码
+func run(args []string, stdout, stderr io.Writer) int {
+	global := flag.NewFlagSet("gittag", flag.ContinueOnError)
+	global.SetOutput(stderr)
+	global.Usage = func() { fmt.Fprint(stderr, usage) }
+	dir := global.String("C", ".", "仓库目录")
+	remote := global.String("remote", "", "远程名称，默认为 origin")
+	verbose := global.Bool("v", false, "将执行的 git 命令及其输出写到标准错误")
+	dryRun := global.Bool("n", false, "只打印会修改标签的 git 命令，不执行")
+	if err := global.Parse(args); err != nil {
+		return exitCode(err, stderr)
+	}
+	if global.NArg() == 0 {
+		global.Usage()
+		return 2
+	}
+
+	var opts []gittag.Option
+	if *remote != "" {
+		opts = append(opts, gittag.WithRemote(*remote))
+	}
+	if *verbose {
+		opts = append(opts, gittag.WithVerbose(stderr))
+	}
+	if *dryRun {
+		opts = append(opts, gittag.WithDryRun(stdout))
+	}
+	repo, err := gittag.Open(*dir, opts...)
+	if err != nil {
+		return exitCode(err, stderr)
+	}
+
+	command, rest := global.Arg(0), global.Args()[1:]
+	switch command {
+	case "create":
+		err = create(repo, rest, stderr)
+	case "delete":
+		err = remove(repo, rest, stderr)
+	case "list":
+		err = list(repo, rest, stdout, stderr)
+	case "doctor":
+		err = doctor(repo, rest, stdout, stderr)
+	default:
+		fmt.Fprintf(stderr, "gittag: 未知命令 %q\n\n%s", command, usage)
+		return 2
+	}
+	return exitCode(err, stderr)
+}
+
+// exitCode 输出 err 及其处理建议并返回对应的退出码
+func exitCode(err error, stderr io.Writer) int {
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.Is(err, errUsage):
+		return 2
+	}
+	message := err.Error()
+	fmt.Fprintf(stderr, "gittag: %s\n", message)
+	// 永久性错误的信息中已经附带了建议
+	if hint := gittag.Hint(err); hint != "" && !strings.Contains(message, hint) {
+		fmt.Fprintf(stderr, "提示: %s\n", hint)
+	}
+	return 1
+}
+
+// newFlagSet 创建子命令的参数集合
+func newFlagSet(name, args string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "用法: gittag %s [flags] %s\n", name, args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parse 解析子命令的参数，参数错误时返回 errUsage
+func parse(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errUsage
+	}
+	return nil
+}
+
+// usageError 输出 message 和子命令的用法，返回 errUsage
+func usageError(fs *flag.FlagSet, message string) error {
+	fmt.Fprintf(fs.Output(), "gittag %s: %s\n", fs.Name(), message)
+	fs.Usage()
+	return errUsage
+}
+
+func create(repo *gittag.Repository, args []string, stderr io.Writer) error {
+	fs := newFlagSet("create", "<tag>", stderr)
+	message := fs.String("m", "", "标签信息，默认按仓库的信息模板生成，例如 \"chore(release): <tag>\"")
+	messageFile := fs.String("F", "", "从文件读取标签信息")
+	target := fs.String("target", "", "标签指向的提交、分支或标签，默认为 HEAD")
+	lightweight := fs.Bool("lightweight", false, "创建轻量标签")
+	sign := fs.String("sign", "", "使用指定的密钥签名，\"default\" 表示使用 user.signingkey")
+	force := fs.Bool("force", false, "标签已存在时移动它，与 -push 一起使用时强制推送")
+	push := fs.Bool("push", false, "创建后推送到远程")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return usageError(fs, "需要一个标签名称")
+	}
+
+	var opts []gittag.CreateOption
+	if *message != "" {
+		opts = append(opts, gittag.WithMessage(*message))
+	}
+	if *messageFile != "" {
+		opts = append(opts, gittag.WithMessageFile(*messageFile))
+	}
+	if *target != "" {
+		opts = append(opts, gittag.WithTarget(*target))
+	}
+	if *lightweight {
+		opts = append(opts, gittag.WithLightweight())
+	}
+	switch *sign {
+	case "":
+	case "default":
+		opts = append(opts, gittag.WithSign(""))
+	default:
+		opts = append(opts, gittag.WithSign(*sign))
+	}
+	if *force {
+		opts = append(opts, gittag.WithForce())
+	}
+	if *push {
+		opts = append(opts, gittag.WithPush())
+	}
+	return repo.Create(fs.Arg(0), opts...)
+}
+
+func remove(repo *gittag.Repository, args []string, stderr io.Writer) error {
+	fs := newFlagSet("delete", "[<tag>...]", stderr)
+	pattern := fs.String("pattern", "", "删除匹配该模式的标签，例如 'v1.*'；仍受支持系列（见 SetEOL）的标签会被跳过")
+	remote := fs.Bool("remote", false, "同时删除远程标签")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if (*pattern == "") == (fs.NArg() == 0) {
+		return usageError(fs, "需要标签名称或 -pattern 之一")
+	}
+
+	if *pattern != "" {
+		if err := repo.DeleteLocalAll(*pattern); err != nil {
+			return err
+		}
+		if *remote {
+			return repo.DeleteRemoteByPattern(*pattern)
+		}
+		return nil
+	}
+	for _, tag := range fs.Args() {
+		del := repo.DeleteLocal
+		if *remote {
+			del = repo.DeleteTag
+		}
+		if err := del(tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func list(repo *gittag.Repository, args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("list", "", stderr)
+	pattern := fs.String("pattern", "*", "标签匹配模式")
+	order := fs.String("sort", "name", "排序方式：name、version、version-desc、created 或 created-desc")
+	limit := fs.Int("limit", 0, "最多列出的标签数量，0 表示不限制")
+	asJSON := fs.Bool("json", false, "以 JSON 数组输出")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	sortOrder, ok := sortOrders[*order]
+	if !ok {
+		return usageError(fs, fmt.Sprintf("无效的排序方式 %q", *order))
+	}
+	if fs.NArg() != 0 {
+		return usageError(fs, "不接受位置参数，请使用 -pattern")
+	}
+
+	opts := []gittag.FindOption{gittag.WithSort(sortOrder)}
+	if *limit > 0 {
+		opts = append(opts, gittag.WithLimit(*limit))
+	}
+	tags, err := repo.FindMany(*pattern, opts...)
+	if errors.Is(err, gittag.ErrTagNotFound) {
+		tags, err = []string{}, nil
+	}
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return json.NewEncoder(stdout).Encode(tags)
+	}
+	for _, tag := range tags {
+		fmt.Fprintln(stdout, tag)
+	}
+	return nil
+}
+
+func doctor(repo *gittag.Repository, args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("doctor", "", stderr)
+	asJSON := fs.Bool("json", false, "以 JSON 数组输出检查结果")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	findings, err := repo.Doctor()
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		if err := json.NewEncoder(stdout).Encode(findings); err != nil {
+			return err
+		}
+	} else {
+		for _, f := range findings {
+			fmt.Fprintln(stdout, f)
+		}
+	}
+	for _, f := range findings {
+		if f.Severity == gittag.SeverityError {
+			return errors.New("检查未通过")
+		}
+	}
+	return nil
+}
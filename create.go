@@ -1,20 +1,25 @@
 package gittag
 
 import (
+	"context"
 	"fmt"
-	"os/exec"
+	"os"
+	"slices"
+	"strings"
 )
 
 // CreateLocal 创建一个本地 Git 标签
 // @param tagName - 标签名称，例如："v1.0.0"
 // @param message - 标签信息（可选），如果不提供则使用默认格式："Release <tagName>"
-// @return error - 如果创建过程中出现错误，返回相应的错误信息
+// @return error - 如果创建过程中出现错误，返回相应的错误信息，标签已存在时满足 errors.Is(err, ErrTagExists)
 //
 // Example:
 //
 //	// Create a tag with default message
 //	err := gittag.CreateLocal("v1.0.0")
-//	if err != nil {
+//	if errors.Is(err, gittag.ErrTagExists) {
+//		fmt.Println("v1.0.0 is already tagged")
+//	} else if err != nil {
 //		log.Fatal(err)
 //	}
 //
@@ -24,15 +29,46 @@ import (
 //		log.Fatal(err)
 //	}
 func CreateLocal(tagName string, message ...string) error {
-	tagMessage := "chore(release): " + tagName
-	if len(message) > 0 && message[0] != "" {
-		tagMessage = message[0]
-	}
-	cmd := exec.Command("git", "tag", "-a", tagName, "-m", tagMessage)
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("创建本地标签失败: %v", err)
+	return Default().CreateLocal(tagName, message...)
+}
+
+// CreateLocal 在仓库中创建一个本地 Git 标签，参数同包级函数 CreateLocal
+func (r *Repository) CreateLocal(tagName string, message ...string) error {
+	return r.withTagLock("CreateLocal", tagName, func() error {
+		return r.createLocal(tagName, message...)
+	})
+}
+
+func (r *Repository) createLocal(tagName string, message ...string) error {
+	return r.create(tagName, newCreateConfig(messageOptions(message)...))
+}
+
+// CreateLocalAt 在指定的提交、分支或其他引用上创建一个本地 Git 标签，而不是 HEAD
+// @param tagName - 标签名称，例如："v1.0.0"
+// @param ref - 标签指向的提交 SHA、分支或其他引用，例如："release/1.x"
+// @param message - 标签信息（可选），默认由 SetMessageTemplate 或 WithMessageTemplate 的模板生成
+// @return error - 如果 ref 为空、无法解析或创建过程中出现错误，返回相应的错误信息
+//
+// Example:
+//
+//	// Tag the tip of a release branch from automation
+//	err := gittag.CreateLocalAt("v1.4.2", "release/1.4")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	// Tag the commit CI just built
+//	err = gittag.CreateLocalAt("v1.5.0", os.Getenv("GITHUB_SHA"), "Release 1.5.0")
+func CreateLocalAt(tagName, ref string, message ...string) error {
+	return Default().CreateLocalAt(tagName, ref, message...)
+}
+
+// CreateLocalAt 在仓库中的 ref 上创建一个本地 Git 标签，参数同包级函数 CreateLocalAt
+func (r *Repository) CreateLocalAt(tagName, ref string, message ...string) error {
+	if strings.TrimSpace(ref) == "" {
+		return fmt.Errorf("未指定标签 %s 指向的引用", tagName)
 	}
-	return nil
+	return r.Create(tagName, append(messageOptions(message), WithTarget(ref))...)
 }
 
 // CreateRemote 将本地标签推送到远程仓库
@@ -47,16 +83,44 @@ func CreateLocal(tagName string, message ...string) error {
 //		log.Fatal(err)
 //	}
 func CreateRemote(tagName string) error {
-	cmd := exec.Command("git", "push", "origin", tagName)
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("推送标签到远程仓库失败: %v", err)
+	return Default().CreateRemote(tagName)
+}
+
+// CreateRemote 将仓库中的本地标签推送到远程仓库，参数同包级函数 CreateRemote
+func (r *Repository) CreateRemote(tagName string) error {
+	return r.withTagLock("CreateRemote", tagName, func() error {
+		return r.createRemote(tagName)
+	})
+}
+
+func (r *Repository) createRemote(tagName string) error {
+	return r.pushTag(tagName, false)
+}
+
+// pushTag 推送标签，force 为 true 时覆盖远程已有的同名标签
+func (r *Repository) pushTag(tagName string, force bool) error {
+	ctx := r.baseContext()
+	name := r.tagName(tagName)
+	refspec := tagRef(name)
+	if force {
+		refspec = "+" + refspec
 	}
-	return nil
+	if err := r.run(ctx, "push", r.remoteName(), refspec); err != nil {
+		return fmt.Errorf("推送标签到远程仓库失败: %w", err)
+	}
+	if r.pushVerifyTimeout <= 0 || r.dryRun != nil {
+		return nil
+	}
+	object, err := r.revParse(ctx, tagRef(name))
+	if err != nil {
+		return fmt.Errorf("读取标签 %s 失败: %w", tagName, err)
+	}
+	return r.verifyPush(ctx, map[string]string{name: object})
 }
 
 // CreateTag creates a tag both locally and remotely in one operation
 // @param tagName - 标签名称，例如："v1.0.0"
-// @param message - 标签信息（可选），如果不提供则使用默认格式："chore(release): <tagName>"
+// @param message - 标签信息（可选），如果不提供则使用默认格式："chore(release): <tagName>"，可通过 SetMessageTemplate 修改
 // @return error - 如果创建过程中出现错误，返回相应的错误信息
 //
 // Example:
@@ -73,11 +137,361 @@ func CreateRemote(tagName string) error {
 //		log.Fatal(err)
 //	}
 func CreateTag(tagName string, message ...string) error {
-	if err := CreateLocal(tagName, message...); err != nil {
+	return Default().CreateTag(tagName, message...)
+}
+
+// CreateTag 在仓库中同时创建本地标签并推送到远程，参数同包级函数 CreateTag
+func (r *Repository) CreateTag(tagName string, message ...string) error {
+	return r.withTagLock("CreateTag", tagName, func() error {
+		return r.createTag(tagName, message...)
+	})
+}
+
+func (r *Repository) createTag(tagName string, message ...string) error {
+	return r.create(tagName, newCreateConfig(append(messageOptions(message), WithPush())...))
+}
+
+// messageOptions 将旧接口中可选的 message 参数转换为 CreateOption
+func messageOptions(message []string) []CreateOption {
+	if len(message) > 0 && message[0] != "" {
+		return []CreateOption{WithMessage(message[0])}
+	}
+	return nil
+}
+
+// createConfig 保存 Create 的可选配置
+type createConfig struct {
+	message     string
+	messageFile string
+	push        bool
+	target      string
+
+	allowDetached bool
+	lightweight   bool
+
+	// sign 为 true 时签名标签，signKey 为 -u 指定的密钥，见 WithSign
+	sign    bool
+	signKey string
+
+	// force 为 true 时替换已存在的同名标签，见 WithForce
+	force bool
+
+	replaceCheck bool
+	replaceAllow []string
+
+	preTagCommands []string
+}
+
+// CreateOption 用于配置 Create 的行为
+type CreateOption func(*createConfig)
+
+// WithMessage 指定标签信息，支持多行和非 ASCII 内容，默认由 SetMessageTemplate 或 WithMessageTemplate 的模板生成
+func WithMessage(message string) CreateOption {
+	return func(c *createConfig) {
+		c.message = message
+	}
+}
+
+// WithMessageFile 从文件读取标签信息，适合将完整的变更日志写入标签
+func WithMessageFile(path string) CreateOption {
+	return func(c *createConfig) {
+		c.messageFile = path
+	}
+}
+
+// WithTarget 指定标签指向的提交、分支或其他引用，默认为 HEAD
+func WithTarget(rev string) CreateOption {
+	return func(c *createConfig) {
+		c.target = rev
+	}
+}
+
+// WithAllowDetached 允许在分离 HEAD 状态下标记 HEAD
+// 默认情况下，分离 HEAD 且未通过 WithTarget 指定目标时会返回错误，避免意外标记了错误的提交
+func WithAllowDetached() CreateOption {
+	return func(c *createConfig) {
+		c.allowDetached = true
+	}
+}
+
+// WithReplaceCheck 在 go.mod 包含本地 replace 指令时拒绝创建标签，避免发布其他模块无法使用的版本
+// allow 为允许保留的被替换模块路径，支持 path.Match 通配符，例如 "example.com/internal/*"
+func WithReplaceCheck(allow ...string) CreateOption {
+	return func(c *createConfig) {
+		c.replaceCheck = true
+		c.replaceAllow = append(c.replaceAllow, allow...)
+	}
+}
+
+// WithLightweight 创建轻量标签（只是指向提交的引用，没有标签对象），适合 CI 标记等不需要信息和作者的场景
+// 不能与 WithMessage 或 WithMessageFile 同时使用
+func WithLightweight() CreateOption {
+	return func(c *createConfig) {
+		c.lightweight = true
+	}
+}
+
+// WithSign 使用 git tag -s 签名标签，key 非空时通过 -u 指定签名密钥，否则使用 user.signingkey
+// 优先于仓库的 WithSignedTags，不能与 WithLightweight 同时使用
+func WithSign(key string) CreateOption {
+	return func(c *createConfig) {
+		c.sign = true
+		c.signKey = key
+	}
+}
+
+// WithForce 在标签已存在时将其移动到新的目标（git tag -f），与 WithPush 一起使用时强制推送，
+// 用于 nightly、latest 这类浮动标签；冻结系列中的标签仍然不能被移动
+//
+// Example:
+//
+//	err := gittag.Create("nightly", gittag.WithForce(), gittag.WithPush())
+func WithForce() CreateOption {
+	return func(c *createConfig) {
+		c.force = true
+	}
+}
+
+// WithPush 在创建本地标签后将其推送到远程仓库
+func WithPush() CreateOption {
+	return func(c *createConfig) {
+		c.push = true
+	}
+}
+
+func newCreateConfig(opts ...CreateOption) *createConfig {
+	cfg := &createConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// tagMessage 返回最终写入标签的信息，保证以换行结尾，其余内容原样保留
+// 未指定信息时使用 defaultMessage
+func (c *createConfig) tagMessage(defaultMessage string) (string, error) {
+	message := defaultMessage
+	switch {
+	case c.messageFile != "":
+		data, err := os.ReadFile(c.messageFile)
+		if err != nil {
+			return "", fmt.Errorf("读取标签信息文件失败: %w", err)
+		}
+		message = string(data)
+	case c.message != "":
+		message = c.message
+	}
+	if !strings.HasSuffix(message, "\n") {
+		message += "\n"
+	}
+	return message, nil
+}
+
+// CreateLightweight creates a lightweight tag — a plain ref to the commit without a tag object,
+// message or tagger — on HEAD. It is shorthand for Create(tagName, WithLightweight()).
+// @param tagName - 标签名称，例如："ci/build-1234"
+// @return error - 如果创建过程中出现错误，返回相应的错误信息
+//
+// Example:
+//
+//	// Mark the commit CI just built; nothing is pushed
+//	err := gittag.CreateLightweight("ci/build-" + os.Getenv("BUILD_ID"))
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+func CreateLightweight(tagName string) error {
+	return Default().CreateLightweight(tagName)
+}
+
+// CreateLightweight creates a lightweight tag in the repository, see the package-level CreateLightweight.
+func (r *Repository) CreateLightweight(tagName string) error {
+	return r.Create(tagName, WithLightweight())
+}
+
+// Create creates an annotated tag on HEAD, or on the revision given by WithTarget, configured by opts.
+// The message is passed to git on stdin and stored verbatim, so long, multi-line and non-ASCII
+// messages (including Markdown headings starting with "#") are kept exactly as given.
+// @param tagName - 标签名称，例如："v1.0.0"
+// @param opts - 可选配置，例如 WithMessage、WithMessageFile、WithTarget、WithLightweight、WithSign、WithForce、WithPush
+// @return error - 如果创建过程中出现错误，返回相应的错误信息
+//
+// Example:
+//
+//	// Use a generated changelog as the tag message and push the tag
+//	err := gittag.Create("v1.2.0", gittag.WithMessageFile("CHANGELOG.md"), gittag.WithPush())
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+func Create(tagName string, opts ...CreateOption) error {
+	return Default().Create(tagName, opts...)
+}
+
+// Create creates a tag in the repository, see the package-level Create.
+func (r *Repository) Create(tagName string, opts ...CreateOption) error {
+	cfg, err := r.preTag(tagName, newCreateConfig(opts...))
+	if err != nil {
+		return err
+	}
+	return r.withTagLock("Create", tagName, func() error {
+		return r.create(tagName, cfg)
+	})
+}
+
+func (r *Repository) create(tagName string, cfg *createConfig) error {
+	if err := r.checkFrozen(tagName); err != nil {
+		return err
+	}
+	if cfg.lightweight && (cfg.message != "" || cfg.messageFile != "") {
+		return fmt.Errorf("轻量标签 %s 不能有标签信息", tagName)
+	}
+	if cfg.lightweight && cfg.sign {
+		return fmt.Errorf("轻量标签 %s 不能签名", tagName)
+	}
+	defaultMessage, err := r.defaultMessage(tagName)
+	if err != nil {
+		return err
+	}
+	message, err := cfg.tagMessage(defaultMessage)
+	if err != nil {
+		return err
+	}
+	if cfg.target == "" && !cfg.allowDetached {
+		if err := r.requireAttachedHead(); err != nil {
+			return err
+		}
+	}
+	var extra []string
+	if cfg.target != "" {
+		extra = append(extra, cfg.target)
+	}
+	if cfg.push && r.remotePreflight {
+		if err := r.PingRemote(r.remoteName()); err != nil {
+			return err
+		}
+	}
+	if err := r.checkCreate(tagName, cfg); err != nil {
+		return err
+	}
+	var flags []string
+	if cfg.force {
+		flags = append(flags, "-f")
+	}
+	if cfg.lightweight {
+		args := append(append([]string{"tag"}, flags...), "--", r.tagName(tagName))
+		err = r.run(r.baseContext(), append(args, extra...)...)
+	} else {
+		err = r.writeTag(r.baseContext(), r.tagName(tagName), message, append(flags, r.signArgs(cfg)...), extra...)
+	}
+	if err != nil {
+		return fmt.Errorf("创建本地标签失败: %w", err)
+	}
+	// 轻量标签没有标签对象，note 只能挂在提交上，会被指向同一提交的其他标签共享
+	if !cfg.lightweight {
+		r.recordActor(r.baseContext(), r.tagName(tagName))
+	}
+	if cfg.push {
+		return r.pushTag(tagName, cfg.force)
+	}
+	return nil
+}
+
+// checkCreate 执行创建标签前的检查
+func (r *Repository) checkCreate(tagName string, cfg *createConfig) error {
+	if err := r.checkNaming(tagName); err != nil {
 		return err
 	}
-	if err := CreateRemote(tagName); err != nil {
+	target := cfg.target
+	if target == "" {
+		target = "HEAD"
+	}
+	if cfg.replaceCheck {
+		if err := r.checkReplaces(r.baseContext(), tagName, target, cfg.replaceAllow); err != nil {
+			return err
+		}
+	}
+	return r.runPreTagCommands(r.baseContext(), tagName, target, cfg.preTagCommands)
+}
+
+// writeTag 通过标准输入写入附注标签信息，flags 为 tag 子命令的其他参数，例如 -f 和 signArgs 返回的签名参数，
+// extra 为 tag 子命令在名称之后的参数，例如目标提交
+func (r *Repository) writeTag(ctx context.Context, name, message string, flags []string, extra ...string) error {
+	args := append([]string{"tag", "-a", "--cleanup=verbatim", "-F", "-"}, flags...)
+	args = append(append(args, "--", name), extra...)
+	if _, _, err := r.execInput(ctx, strings.NewReader(message), args...); err != nil {
 		return err
 	}
+	if r.dryRun == nil && (slices.Contains(flags, "-s") || slices.Contains(flags, "-u")) {
+		return r.requireSignature(ctx, name)
+	}
 	return nil
-}
\ No newline at end of file
+}
+
+// requireSignature 确认刚创建的标签带有签名，否则删除它并返回错误
+// 部分 git 版本在无法读取 SSH 密钥时只打印警告，仍以成功状态创建未签名的标签
+func (r *Repository) requireSignature(ctx context.Context, name string) error {
+	output, err := r.output(ctx, "cat-file", "tag", tagRef(name))
+	if err != nil {
+		return err
+	}
+	if strings.Contains(string(output), "\n-----BEGIN ") {
+		return nil
+	}
+	if err := r.run(ctx, "tag", "-d", "--", name); err != nil {
+		return fmt.Errorf("标签 %s 未被签名，删除它失败: %w", name, err)
+	}
+	return fmt.Errorf("标签 %s 未被签名，请用 SigningPreflight 检查签名配置", name)
+}
+
+// WithSignedTags 让仓库创建的附注标签都使用 git tag -s 签名，key 非空时通过 -u 指定签名密钥，否则使用 user.signingkey
+// 单次调用可以用 WithSign 指定其他密钥
+//
+// Example:
+//
+//	repo, err := gittag.Open(".", gittag.WithSignedTags("0xA1B2C3D4E5F60718"))
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	err = repo.CreateTag("v1.2.0") // git tag -a -u 0xA1B2C3D4E5F60718 ...
+func WithSignedTags(key string) Option {
+	return func(r *Repository) {
+		r.sign = true
+		r.signKey = key
+	}
+}
+
+// WithSSHSigning 让仓库创建的附注标签都使用 SSH 密钥签名（gpg.format=ssh，需要 git 2.34 或更高版本），不需要 GPG
+// keyPath 为私钥或公钥文件的路径，"~/" 开头时展开为用户主目录；只提供公钥时私钥需要已加载到 ssh-agent
+//
+// Example:
+//
+//	repo, err := gittag.Open(".", gittag.WithSSHSigning("~/.ssh/release_ed25519"))
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	if err := repo.SigningPreflight(); err != nil {
+//		log.Fatal(err)
+//	}
+//	err = repo.CreateTag("v1.2.0")
+func WithSSHSigning(keyPath string) Option {
+	return func(r *Repository) {
+		r.config = append(r.config, "gpg.format=ssh", "user.signingkey="+expandHome(keyPath))
+		r.sign = true
+		r.signKey = ""
+	}
+}
+
+// signArgs 返回 git tag 的签名参数，cfg 的 WithSign 优先于仓库的 WithSignedTags，不签名时返回 nil
+func (r *Repository) signArgs(cfg *createConfig) []string {
+	sign, key := r.sign, r.signKey
+	if cfg != nil && cfg.sign {
+		sign, key = true, cfg.signKey
+	}
+	switch {
+	case !sign:
+		return nil
+	case key != "":
+		return []string{"-u", key}
+	default:
+		return []string{"-s"}
+	}
+}
@@ -1,13 +1,13 @@
 package gittag
 
 import (
+	"errors"
 	"fmt"
-	"os/exec"
 )
 
 // DeleteLocal 删除本地标签
 // @param tagName - 要删除的标签名称
-// @return error - 如果删除过程中出现错误，返回相应的错误信息
+// @return error - 如果删除过程中出现错误，返回相应的错误信息，标签不存在时返回 *NotFoundError（满足 errors.Is(err, ErrTagNotFound)）
 //
 // Example:
 //
@@ -17,9 +17,25 @@ import (
 //		log.Fatal(err)
 //	}
 func DeleteLocal(tagName string) error {
-	cmd := exec.Command("git", "tag", "-d", tagName)
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("删除本地标签失败: %v", err)
+	return Default().DeleteLocal(tagName)
+}
+
+// DeleteLocal 删除仓库中的本地标签，参数同包级函数 DeleteLocal
+func (r *Repository) DeleteLocal(tagName string) error {
+	return r.withLock("DeleteLocal", func() error {
+		return r.deleteLocal(tagName)
+	})
+}
+
+func (r *Repository) deleteLocal(tagName string) error {
+	if err := r.checkFrozen(tagName); err != nil {
+		return err
+	}
+	if err := r.run(r.baseContext(), "tag", "-d", "--", r.tagName(tagName)); err != nil {
+		if Kind(err) == KindNotFound {
+			return &NotFoundError{Pattern: tagName}
+		}
+		return fmt.Errorf("删除本地标签失败: %w", err)
 	}
 	return nil
 }
@@ -36,16 +52,29 @@ func DeleteLocal(tagName string) error {
 //		log.Fatal(err)
 //	}
 func DeleteRemote(tagName string) error {
-	cmd := exec.Command("git", "push", "origin", "--delete", tagName)
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("删除远程标签失败: %v", err)
+	return Default().DeleteRemote(tagName)
+}
+
+// DeleteRemote 删除仓库远程中的标签，参数同包级函数 DeleteRemote
+func (r *Repository) DeleteRemote(tagName string) error {
+	return r.withLock("DeleteRemote", func() error {
+		return r.deleteRemote(tagName)
+	})
+}
+
+func (r *Repository) deleteRemote(tagName string) error {
+	if err := r.checkFrozen(tagName); err != nil {
+		return err
+	}
+	if err := r.run(r.baseContext(), "push", r.remoteName(), "--delete", tagRef(r.tagName(tagName))); err != nil {
+		return fmt.Errorf("删除远程标签失败: %w", err)
 	}
 	return nil
 }
 
 // DeleteTag deletes a tag both locally and remotely in one operation
 // @param tagName - 要删除的标签名称
-// @return error - 如果删除过程中出现错误，返回相应的错误信息
+// @return error - 如果删除过程中出现错误，返回相应的错误信息，本地标签不存在时返回 *NotFoundError 且不会删除远程标签
 //
 // Example:
 //
@@ -64,10 +93,21 @@ func DeleteRemote(tagName string) error {
 //		}
 //	}
 func DeleteTag(tagName string) error {
-	if err := DeleteLocal(tagName); err != nil {
+	return Default().DeleteTag(tagName)
+}
+
+// DeleteTag 同时删除仓库中的本地标签和远程标签，参数同包级函数 DeleteTag
+func (r *Repository) DeleteTag(tagName string) error {
+	return r.withLock("DeleteTag", func() error {
+		return r.deleteTag(tagName)
+	})
+}
+
+func (r *Repository) deleteTag(tagName string) error {
+	if err := r.deleteLocal(tagName); err != nil {
 		return err
 	}
-	if err := DeleteRemote(tagName); err != nil {
+	if err := r.deleteRemote(tagName); err != nil {
 		return err
 	}
 	return nil
@@ -91,14 +131,32 @@ func DeleteTag(tagName string) error {
 //		log.Fatal(err)
 //	}
 func DeleteLocalAll(pattern string) error {
-	tags, err := FindMany(pattern)
-	if err != nil {
+	return Default().DeleteLocalAll(pattern)
+}
+
+// DeleteLocalAll 删除仓库中所有匹配指定模式的本地标签，参数同包级函数 DeleteLocalAll
+func (r *Repository) DeleteLocalAll(pattern string) error {
+	return r.withLock("DeleteLocalAll", func() error {
+		return r.deleteLocalAll(pattern)
+	})
+}
+
+func (r *Repository) deleteLocalAll(pattern string) error {
+	tags, err := r.FindMany(pattern)
+	var notFound *NotFoundError
+	if errors.As(err, &notFound) {
 		return nil // 如果没有找到标签，直接返回
 	}
+	if err != nil {
+		return err
+	}
+	if tags, err = r.skipSupported(tags); err != nil {
+		return err
+	}
 
 	for _, tag := range tags {
-		if err := DeleteLocal(tag); err != nil {
-			return fmt.Errorf("删除标签 %s 失败: %v", tag, err)
+		if err := r.deleteLocal(tag); err != nil {
+			return fmt.Errorf("删除标签 %s 失败: %w", tag, err)
 		}
 	}
 	return nil
@@ -122,14 +180,32 @@ func DeleteLocalAll(pattern string) error {
 //		log.Fatal(err)
 //	}
 func DeleteRemoteAll(pattern string) error {
-	tags, err := FindMany(pattern)
-	if err != nil {
+	return Default().DeleteRemoteAll(pattern)
+}
+
+// DeleteRemoteAll 删除仓库远程中所有匹配指定模式的标签，参数同包级函数 DeleteRemoteAll
+func (r *Repository) DeleteRemoteAll(pattern string) error {
+	return r.withLock("DeleteRemoteAll", func() error {
+		return r.deleteRemoteAll(pattern)
+	})
+}
+
+func (r *Repository) deleteRemoteAll(pattern string) error {
+	tags, err := r.FindMany(pattern)
+	var notFound *NotFoundError
+	if errors.As(err, &notFound) {
 		return nil // 如果没有找到标签，直接返回
 	}
+	if err != nil {
+		return err
+	}
+	if tags, err = r.skipSupported(tags); err != nil {
+		return err
+	}
 
 	for _, tag := range tags {
-		if err := DeleteRemote(tag); err != nil {
-			return fmt.Errorf("删除远程标签 %s 失败: %v", tag, err)
+		if err := r.deleteRemote(tag); err != nil {
+			return fmt.Errorf("删除远程标签 %s 失败: %w", tag, err)
 		}
 	}
 	return nil
@@ -161,11 +237,22 @@ func DeleteRemoteAll(pattern string) error {
 //		 return nil
 //	 }
 func DeleteAllTags() error {
-	if err := DeleteLocalAll("*"); err != nil {
+	return Default().DeleteAllTags()
+}
+
+// DeleteAllTags 同时删除仓库中所有本地标签和远程标签，参见包级函数 DeleteAllTags
+func (r *Repository) DeleteAllTags() error {
+	return r.withLock("DeleteAllTags", func() error {
+		return r.deleteAllTags()
+	})
+}
+
+func (r *Repository) deleteAllTags() error {
+	if err := r.deleteLocalAll("*"); err != nil {
 		return err
 	}
-	if err := DeleteRemoteAll("*"); err != nil {
+	if err := r.deleteRemoteAll("*"); err != nil {
 		return err
 	}
 	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,97 @@
+package gittag
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// TagPair 是两个模式中对应同一版本的一对标签
+type TagPair struct {
+	A string `json:"a"`
+	B string `json:"b"`
+}
+
+// SetDiff 描述两个标签模式的成员差异
+type SetDiff struct {
+	// OnlyA 是只在模式 A 中存在的标签
+	OnlyA []string `json:"onlyA"`
+	// OnlyB 是只在模式 B 中存在的标签
+	OnlyB []string `json:"onlyB"`
+	// Common 是两个模式中都存在的版本
+	Common []TagPair `json:"common"`
+}
+
+// DiffPatterns compares the tags matched by two patterns as sets.
+// Tags are paired by the part of their name after the pattern's literal prefix, so "v1.2.3"
+// matched by "v1.*" pairs with "release/1.2.3" matched by "release/1.*".
+// @param patternA - 第一个模式，例如旧命名规则 "v1.*"
+// @param patternB - 第二个模式，例如新命名规则 "release/1.*"
+// @return (*SetDiff, error) - 返回两个模式的差异
+//
+// Example:
+//
+//	// Check which releases still lack a tag under the new naming scheme
+//	diff, err := gittag.DiffPatterns("v*", "release/*")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	for _, tag := range diff.OnlyA {
+//		fmt.Printf("missing release/ tag for %s\n", tag)
+//	}
+func DiffPatterns(patternA, patternB string) (*SetDiff, error) {
+	return Default().DiffPatterns(patternA, patternB)
+}
+
+// DiffPatterns compares two tag patterns in the repository, see the package-level DiffPatterns.
+func (r *Repository) DiffPatterns(patternA, patternB string) (*SetDiff, error) {
+	a, err := r.keyedTags(patternA)
+	if err != nil {
+		return nil, err
+	}
+	b, err := r.keyedTags(patternB)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &SetDiff{}
+	for key, tagA := range a {
+		if tagB, ok := b[key]; ok {
+			diff.Common = append(diff.Common, TagPair{A: tagA, B: tagB})
+		} else {
+			diff.OnlyA = append(diff.OnlyA, tagA)
+		}
+	}
+	for key, tagB := range b {
+		if _, ok := a[key]; !ok {
+			diff.OnlyB = append(diff.OnlyB, tagB)
+		}
+	}
+	sort.Strings(diff.OnlyA)
+	sort.Strings(diff.OnlyB)
+	sort.Slice(diff.Common, func(i, j int) bool {
+		return diff.Common[i].A < diff.Common[j].A
+	})
+	return diff, nil
+}
+
+// keyedTags 返回匹配模式的标签，键为去掉模式字面前缀后的名称
+func (r *Repository) keyedTags(pattern string) (map[string]string, error) {
+	tags, err := r.FindMany(pattern)
+	var notFound *NotFoundError
+	if errors.As(err, &notFound) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	prefix := pattern
+	if i := strings.IndexAny(pattern, "*?["); i >= 0 {
+		prefix = pattern[:i]
+	}
+	keyed := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		keyed[strings.TrimPrefix(strings.TrimPrefix(tag, prefix), "v")] = tag
+	}
+	return keyed, nil
+}
@@ -0,0 +1,222 @@
+package gittag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// minGitVersion 是支持的最低 git 版本，sparse-checkout --cone 需要 2.25
+const minGitVersion = "2.25.0"
+
+// doctorProbeRef 是检查推送权限时 dry-run 推送的引用，不会真正写入远程仓库
+const doctorProbeRef = "refs/tags/gittag-doctor-probe"
+
+// Severity 表示 Doctor 检查结果的严重程度
+type Severity string
+
+const (
+	// SeverityOK 表示检查通过
+	SeverityOK Severity = "ok"
+	// SeverityWarning 表示发布可以进行，但部分功能受限或结果可能不完整
+	SeverityWarning Severity = "warning"
+	// SeverityError 表示发布会失败
+	SeverityError Severity = "error"
+)
+
+// Finding 是 Doctor 的一项检查结果
+type Finding struct {
+	// Check 是检查项：git、repository、identity、remote、credentials、signing、shallow、head
+	Check    string   `json:"check"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+	// Fix 是修复建议，检查通过时为空
+	Fix string `json:"fix,omitempty"`
+}
+
+// String 返回适合逐行输出的检查结果
+func (f Finding) String() string {
+	line := fmt.Sprintf("[%s] %s: %s", f.Severity, f.Check, f.Message)
+	if f.Fix != "" {
+		line += "\n  fix: " + f.Fix
+	}
+	return line
+}
+
+// Doctor checks everything a release depends on before one is attempted: the git binary and its
+// version, the repository and its HEAD commit, the tagger identity, whether the remote is reachable
+// and accepts pushes (using a dry-run push that writes nothing), the signing setup when
+// tag.gpgSign, WithSignedTags or WithSSHSigning is enabled, and shallow or detached checkouts. Each check yields one Finding.
+// @return ([]Finding, error) - 返回每项检查的结果；只有检查本身无法进行（例如上下文被取消）时才返回错误
+//
+// Example:
+//
+//	findings, err := gittag.Doctor()
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	for _, f := range findings {
+//		fmt.Println(f)
+//	}
+//	// [ok] git: git 2.39.5
+//	// [error] credentials: 没有推送到 origin 的权限: ...
+//	//   fix: 检查远程仓库的凭据（token、SSH 密钥或 credential helper）是否有效且具有推送权限
+func Doctor() ([]Finding, error) {
+	return Default().Doctor()
+}
+
+// Doctor checks the repository's release setup, see the package-level Doctor.
+func (r *Repository) Doctor() ([]Finding, error) {
+	ctx := r.baseContext()
+	var findings []Finding
+	add := func(check string, severity Severity, message, fix string) {
+		findings = append(findings, Finding{Check: check, Severity: severity, Message: message, Fix: fix})
+	}
+
+	// 没有可用的 git 或不在仓库中时，其余检查都没有意义
+	if !r.doctorGit(ctx, add) || !r.doctorRepository(ctx, add) {
+		return findings, ctx.Err()
+	}
+	r.doctorIdentity(ctx, add)
+	if r.doctorRemote(ctx, add) {
+		r.doctorCredentials(ctx, add)
+	}
+	r.doctorSigning(ctx, add)
+	r.doctorCheckout(ctx, add)
+	return findings, ctx.Err()
+}
+
+// addFinding 记录一项检查结果
+type addFinding func(check string, severity Severity, message, fix string)
+
+var gitVersionPattern = regexp.MustCompile(`\d+\.\d+\.\d+`)
+
+func (r *Repository) doctorGit(ctx context.Context, add addFinding) bool {
+	if _, err := exec.LookPath("git"); err != nil {
+		add("git", SeverityError, "找不到 git 可执行文件", "安装 git "+minGitVersion+" 或更高版本，并确保它在 PATH 中")
+		return false
+	}
+	output, err := r.output(ctx, "version")
+	if err != nil {
+		add("git", SeverityError, "无法运行 git: "+err.Error(), "检查 git 安装是否完整")
+		return false
+	}
+	line := strings.TrimSpace(string(output))
+	version := gitVersionPattern.FindString(line)
+	if version == "" {
+		add("git", SeverityWarning, "无法识别的 git 版本: "+line, "")
+		return true
+	}
+	if SemVer.Compare("v"+version, "v"+minGitVersion) < 0 {
+		add("git", SeverityWarning, fmt.Sprintf("git %s 低于支持的最低版本 %s，部分操作可能失败", version, minGitVersion), "升级 git")
+		return true
+	}
+	add("git", SeverityOK, "git "+version, "")
+	return true
+}
+
+func (r *Repository) doctorRepository(ctx context.Context, add addFinding) bool {
+	if _, err := r.output(ctx, "rev-parse", "--git-dir"); err != nil {
+		add("repository", SeverityError, "不是 Git 仓库: "+err.Error(), "在仓库目录中运行，或使用 Open 指定仓库路径")
+		return false
+	}
+	head, err := r.revParse(ctx, "HEAD^{commit}")
+	if err != nil {
+		add("repository", SeverityError, "HEAD 没有指向任何提交，仓库还没有提交", "先创建至少一个提交再发布")
+		return false
+	}
+	add("repository", SeverityOK, "HEAD 位于 "+head, "")
+	return true
+}
+
+func (r *Repository) doctorIdentity(ctx context.Context, add addFinding) {
+	output, err := r.output(ctx, "var", "GIT_COMMITTER_IDENT")
+	if err != nil {
+		add("identity", SeverityError, "未配置提交者身份，无法创建附注标签", `运行 git config user.name "Your Name" 和 git config user.email you@example.com`)
+		return
+	}
+	ident := string(output)
+	if i := strings.IndexByte(ident, '>'); i >= 0 {
+		ident = ident[:i+1]
+	}
+	add("identity", SeverityOK, "标签创建者为 "+ident, "")
+}
+
+func (r *Repository) doctorRemote(ctx context.Context, add addFinding) bool {
+	remote := r.remoteName()
+	url := r.configValue(ctx, "remote."+remote+".url")
+	if url == "" {
+		add("remote", SeverityWarning, "未配置远程仓库 "+remote+"，只能创建本地标签", "运行 git remote add "+remote+" <url>")
+		return false
+	}
+	if err := r.pingRemote(ctx, remote); err != nil {
+		message, fix := doctorProblem(err, "检查网络连接和远程地址")
+		add("remote", SeverityError, "无法连接 "+remote+" ("+Redact(url)+"): "+message, fix)
+		return false
+	}
+	add("remote", SeverityOK, remote+" ("+Redact(url)+") 可以访问", "")
+	return true
+}
+
+func (r *Repository) doctorCredentials(ctx context.Context, add addFinding) {
+	remote := r.remoteName()
+	err := r.run(ctx, "push", "--dry-run", "--porcelain", remote, "HEAD:"+doctorProbeRef)
+	switch {
+	case errors.Is(err, ErrReadOnly), errors.Is(err, ErrNotPermitted):
+		add("credentials", SeverityOK, "仓库句柄不允许推送，跳过推送权限检查", "")
+	case err != nil:
+		message, fix := doctorProblem(err, "检查远程仓库的凭据是否具有推送权限")
+		add("credentials", SeverityError, "没有推送到 "+remote+" 的权限: "+message, fix)
+	default:
+		add("credentials", SeverityOK, "可以向 "+remote+" 推送标签", "")
+	}
+}
+
+func (r *Repository) doctorSigning(ctx context.Context, add addFinding) {
+	if !r.sign && r.configValue(ctx, "tag.gpgSign") != "true" {
+		add("signing", SeverityOK, "未启用 tag.gpgSign，标签不签名", "")
+		return
+	}
+	if err := r.SigningPreflight(); err != nil {
+		if r.sign {
+			add("signing", SeverityError, "已启用标签签名，但无法签名: "+err.Error(), "修复签名配置或 WithSSHSigning 指定的密钥")
+			return
+		}
+		add("signing", SeverityError, "已启用 tag.gpgSign，但无法签名: "+err.Error(), "修复签名配置，或运行 git config tag.gpgSign false 关闭签名")
+		return
+	}
+	add("signing", SeverityOK, "签名配置可用", "")
+}
+
+func (r *Repository) doctorCheckout(ctx context.Context, add addFinding) {
+	output, err := r.output(ctx, "rev-parse", "--is-shallow-repository")
+	if err == nil && strings.TrimSpace(string(output)) == "true" {
+		add("shallow", SeverityWarning, "仓库是浅克隆，较早的标签和提交可能缺失，Previous、Changelog 等结果不完整", "运行 git fetch --unshallow --tags")
+	} else {
+		add("shallow", SeverityOK, "仓库包含完整历史", "")
+	}
+
+	detached, err := r.IsDetached()
+	switch {
+	case err != nil:
+		add("head", SeverityWarning, err.Error(), "")
+	case detached:
+		add("head", SeverityWarning, "HEAD 处于分离状态，CreateTag 会拒绝标记当前提交", "使用 WithTarget 指定提交，或使用 WithAllowDetached")
+	default:
+		add("head", SeverityOK, "HEAD 位于分支上", "")
+	}
+}
+
+// doctorProblem 将 git 错误拆分为错误信息和处理建议，withGuidance 附加的建议单独作为 Fix，没有时使用 fallback
+func doctorProblem(err error, fallback string) (message, fix string) {
+	if hint, ok := guidance[Kind(err)]; ok {
+		if inner := errors.Unwrap(err); inner != nil {
+			return inner.Error(), hint
+		}
+		return err.Error(), hint
+	}
+	return err.Error(), fallback
+}
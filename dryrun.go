@@ -0,0 +1,62 @@
+package gittag
+
+import (
+	"io"
+	"strings"
+)
+
+// WithDryRun makes the repository print the git commands that would modify the repository or a
+// remote — creating, moving, deleting and pushing tags, fetching — to w instead of running them,
+// so scripts that call DeleteAllTags or RemapTags can be tested safely against real repositories.
+// Read-only commands still run, so the printed commands are the ones a real run would execute.
+// Side effects outside git are printed and skipped as well: WithPreTagCommand commands, the file
+// writes of SyncVersionFiles and WithVersionFiles, and the WithPublish and WithNotify hooks of
+// Release. WithValidate hooks still run, since they only check whether a release may proceed.
+// Operations report success, and nothing is recorded in the idempotency journal or release state.
+// Use SetDefault to put the package-level functions in dry-run mode.
+// @param w - 接收命令行的 writer，例如 os.Stderr，为 nil 时关闭 dry-run
+// @return Option - 返回仓库配置项
+//
+// Example:
+//
+//	repo, err := gittag.Open(".", gittag.WithDryRun(os.Stderr))
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	err = repo.DeleteAllTags()
+//	// [dry-run] git tag -d -- v1.0.0
+//	// [dry-run] git tag -d -- v1.1.0
+//	// [dry-run] git push origin --delete refs/tags/v1.0.0
+//	// [dry-run] git push origin --delete refs/tags/v1.1.0
+func WithDryRun(w io.Writer) Option {
+	return func(r *Repository) {
+		if w == nil {
+			r.dryRun = nil
+			return
+		}
+		r.dryRun = &verboseWriter{w: w}
+	}
+}
+
+// skipDryRun 在 dry-run 模式下输出会修改仓库的命令并返回 true，调用方不应再执行该命令
+func (r *Repository) skipDryRun(args []string) bool {
+	if r.dryRun == nil || len(args) == 0 || isReadOnlyCommand(args) {
+		return false
+	}
+	parts := []string{"[dry-run] git"}
+	for _, arg := range args {
+		parts = append(parts, shellQuote(arg))
+	}
+	r.dryRun.writeLine(strings.Join(parts, " "))
+	return true
+}
+
+// skipDryRunStep 在 dry-run 模式下输出不经过 git 的操作（钩子命令、写文件、发布回调）并返回 true，
+// 调用方不应再执行该操作
+func (r *Repository) skipDryRunStep(parts ...string) bool {
+	if r.dryRun == nil {
+		return false
+	}
+	r.dryRun.writeLine("[dry-run] " + strings.Join(parts, " "))
+	return true
+}
@@ -0,0 +1,253 @@
+package gittag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// ErrorKind 是错误的分类，用于编写精确的重试和告警逻辑
+type ErrorKind string
+
+const (
+	// KindUnknown 表示无法归类的错误
+	KindUnknown ErrorKind = "unknown"
+	// KindAuthFailed 表示远程仓库认证或授权失败
+	KindAuthFailed ErrorKind = "auth_failed"
+	// KindNotFastForward 表示远程拒绝了非快进的更新
+	KindNotFastForward ErrorKind = "not_fast_forward"
+	// KindRefLocked 表示引用或仓库被其他进程锁定
+	KindRefLocked ErrorKind = "ref_locked"
+	// KindNetworkTimeout 表示网络不可达、连接中断或超时
+	KindNetworkTimeout ErrorKind = "network_timeout"
+	// KindUnknownRevision 表示提交、标签或其他修订不存在
+	KindUnknownRevision ErrorKind = "unknown_revision"
+	// KindTagExists 表示本地或远程已存在同名标签
+	KindTagExists ErrorKind = "tag_exists"
+	// KindNotFound 表示没有匹配的标签，见 NotFoundError
+	KindNotFound ErrorKind = "not_found"
+	// KindSeriesFrozen 表示标签属于已冻结的系列，见 ErrSeriesFrozen
+	KindSeriesFrozen ErrorKind = "series_frozen"
+	// KindServerError 表示远程仓库返回了 5xx 错误
+	KindServerError ErrorKind = "server_error"
+	// KindRejected 表示远程仓库的策略（受保护的标签、pre-receive hook）拒绝了推送
+	KindRejected ErrorKind = "rejected"
+	// KindReadOnly 表示仓库以 WithReadOnly 打开，见 ErrReadOnly
+	KindReadOnly ErrorKind = "read_only"
+	// KindNotPermitted 表示受限的仓库句柄没有执行该操作的能力，见 ErrNotPermitted
+	KindNotPermitted ErrorKind = "not_permitted"
+)
+
+// GitError 表示一次 git 命令执行失败，保存了命令行、退出码和 git 的标准错误输出（已脱敏），
+// 可以通过 errors.As 从本包返回的错误中取出，根据 git 实际的失败原因处理
+//
+// Example:
+//
+//	var gitErr *gittag.GitError
+//	if errors.As(err, &gitErr) && strings.Contains(gitErr.Stderr, "remote rejected") {
+//		log.Printf("git %s 被拒绝 (exit %d): %s", gitErr.Args[0], gitErr.ExitCode, gitErr.Stderr)
+//	}
+type GitError struct {
+	// Args 是 git 子命令及其参数，例如 ["push", "origin", "refs/tags/v1.2.0"]，每个参数都已脱敏
+	// 仓库通过 -c 传给 git 的配置（默认配置、WithHTTPSFallback 的认证头等）不在其中
+	Args []string
+	// ExitCode 是 git 的退出码，git 未能启动或被信号终止时为 -1
+	ExitCode int
+	// Stderr 是 git 的标准错误输出
+	Stderr string
+	// Err 是执行命令返回的原始错误，通常为 *exec.ExitError
+	Err error
+}
+
+// newGitError 根据执行 git 命令返回的错误构造 GitError
+func newGitError(args []string, stderr []byte, err error) *GitError {
+	code := -1
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		code = exitErr.ExitCode()
+	}
+	redacted := make([]string, len(args))
+	for i, arg := range args {
+		redacted[i] = Redact(arg)
+	}
+	return &GitError{Args: redacted, ExitCode: code, Stderr: Redact(strings.TrimSpace(string(stderr))), Err: err}
+}
+
+// Error 返回原始错误和标准错误的最后一行，git 通常在最后一行给出失败原因
+func (e *GitError) Error() string {
+	lines := strings.Split(e.Stderr, "\n")
+	if last := strings.TrimSpace(lines[len(lines)-1]); last != "" {
+		return e.Err.Error() + ": " + last
+	}
+	return e.Err.Error()
+}
+
+func (e *GitError) Unwrap() error {
+	return e.Err
+}
+
+// Is 让 errors.Is(err, ErrTagExists) 对 git 报告标签已存在的失败成立
+func (e *GitError) Is(target error) bool {
+	return target == ErrTagExists && stderrKind(e.Stderr) == KindTagExists
+}
+
+// errLockTimeout 表示等待仓库锁超时
+var errLockTimeout = errors.New("等待仓库锁超时")
+
+// stderrSignatures 按顺序匹配 git 的标准错误输出，LC_ALL=C 保证输出为英文
+// patterns 是小写的子串，exprs 用于需要锚定到 git 原始信息的情况，例如只匹配标签而不是远程或路径已存在
+var stderrSignatures = []struct {
+	kind     ErrorKind
+	patterns []string
+	exprs    []*regexp.Regexp
+}{
+	{KindAuthFailed, []string{
+		"authentication failed", "could not read username", "could not read password",
+		"permission denied (publickey", "terminal prompts disabled", "invalid username or password",
+		"http basic: access denied", "the requested url returned error: 401", "the requested url returned error: 403",
+		// GitHub 等托管服务对无权访问的私有仓库报告仓库不存在
+		"error: repository not found", "remote: repository not found",
+	}, []*regexp.Regexp{regexp.MustCompile(`fatal: repository '[^']*' not found`)}},
+	// git tag 报告 "fatal: tag 'v1.0.0' already exists"，git push 报告 "! [rejected] v1.0.0 -> v1.0.0 (already exists)"
+	{KindTagExists, []string{"(already exists)"}, []*regexp.Regexp{regexp.MustCompile(`tag '[^']*' already exists`)}},
+	{KindNotFastForward, []string{"non-fast-forward", "fetch first", "updates were rejected"}, nil},
+	{KindRejected, []string{"[remote rejected]", "pre-receive hook declined", "protected tag", "protected branch"}, nil},
+	{KindServerError, []string{
+		"the requested url returned error: 5", "internal server error", "bad gateway",
+		"service unavailable", "gateway timeout",
+	}, nil},
+	{KindRefLocked, []string{"cannot lock ref", ".lock': file exists", "unable to lock"}, nil},
+	{KindNetworkTimeout, []string{
+		"could not resolve host", "connection timed out", "operation timed out", "connection refused",
+		"failed to connect", "the remote end hung up unexpectedly", "early eof", "rpc failed",
+		"ssl_connect", "connection reset", "network is unreachable", "could not read from remote repository",
+	}, nil},
+	{KindUnknownRevision, []string{
+		"unknown revision", "bad revision", "needed a single revision", "not a valid object name",
+		"ambiguous argument", "bad object", "not a valid tag name",
+	}, nil},
+	// git tag -d 报告 "error: tag 'v9.0.0' not found."，git fetch 指定的远程引用不存在时报告 "couldn't find remote ref"
+	{KindNotFound, []string{"couldn't find remote ref", "no names found, cannot describe anything", "no tags can describe"}, []*regexp.Regexp{regexp.MustCompile(`tag '[^']*' not found`)}},
+}
+
+// Kind classifies an error returned by this package from git's exit code and stderr, so callers
+// can decide precisely whether to retry, alert or give up.
+// @param err - 本包返回的错误
+// @return ErrorKind - 返回错误分类，err 为 nil 时返回空字符串，无法归类时返回 KindUnknown
+//
+// Example:
+//
+//	err := gittag.CreateTag("v1.2.0")
+//	switch gittag.Kind(err) {
+//	case gittag.KindAuthFailed:
+//		alert("deploy key was revoked")
+//	case gittag.KindNetworkTimeout, gittag.KindRefLocked:
+//		retryLater()
+//	}
+func Kind(err error) ErrorKind {
+	if err == nil {
+		return ""
+	}
+	var notFound *NotFoundError
+	switch {
+	case errors.Is(err, ErrSeriesFrozen):
+		return KindSeriesFrozen
+	case errors.Is(err, ErrReadOnly):
+		return KindReadOnly
+	case errors.Is(err, ErrNotPermitted):
+		return KindNotPermitted
+	case errors.As(err, &notFound):
+		return KindNotFound
+	case errors.Is(err, errLockTimeout):
+		return KindRefLocked
+	case errors.Is(err, context.DeadlineExceeded):
+		return KindNetworkTimeout
+	}
+
+	var gitErr *GitError
+	if !errors.As(err, &gitErr) {
+		return KindUnknown
+	}
+	if kind := stderrKind(gitErr.Stderr); kind != KindUnknown {
+		return kind
+	}
+	// git rev-parse --verify --quiet 和 cat-file -e 在对象不存在时只返回退出码 1
+	if gitErr.ExitCode == 1 && gitErr.Stderr == "" {
+		return KindUnknownRevision
+	}
+	return KindUnknown
+}
+
+// stderrKind 按 stderrSignatures 对 git 的标准错误输出分类，无法归类时返回 KindUnknown
+func stderrKind(stderr string) ErrorKind {
+	lower := strings.ToLower(stderr)
+	for _, sig := range stderrSignatures {
+		for _, pattern := range sig.patterns {
+			if strings.Contains(lower, pattern) {
+				return sig.kind
+			}
+		}
+		for _, expr := range sig.exprs {
+			if expr.MatchString(stderr) {
+				return sig.kind
+			}
+		}
+	}
+	return KindUnknown
+}
+
+// hints 是 guidance 之外其他错误分类的处理建议，只由 Hint 返回，不会附加到错误信息中
+var hints = map[ErrorKind]string{
+	KindRefLocked:       "其他进程正在修改标签，稍后重试或使用 WithRetry、WithLockTimeout；确认没有进程在运行后可删除残留的 .lock 文件",
+	KindNetworkTimeout:  "检查网络连接、代理和远程地址，可以使用 WithRetry 自动重试暂时性错误",
+	KindServerError:     "远程仓库暂时不可用，稍后重试，或使用 WithRetry 自动重试",
+	KindUnknownRevision: "确认提交、分支或标签存在，只在远程存在的引用需要先运行 FetchTags 或 git fetch",
+	KindNotFound:        "检查匹配模式是否正确，只在远程存在的标签需要先运行 FetchTags",
+	KindSeriesFrozen:    "该系列已通过 Freeze 冻结，确需修改时先运行 Unfreeze",
+	KindReadOnly:        "仓库以 WithReadOnly 打开，需要修改标签时去掉该选项",
+	KindNotPermitted:    "该仓库句柄通过 Restrict 限制了能力，请使用具有相应能力的句柄",
+}
+
+// Hint returns a human-readable suggestion for resolving err, for CLIs and services that show
+// errors to people: what to change, or which option or function of this package to use instead.
+// @param err - 本包返回的错误
+// @return string - 返回处理建议，err 为 nil 或没有可用的建议时返回空字符串
+//
+// Example:
+//
+//	if err := gittag.CreateTag("v1.2.0"); err != nil {
+//		fmt.Fprintln(os.Stderr, "error:", err)
+//		if hint := gittag.Hint(err); hint != "" {
+//			fmt.Fprintln(os.Stderr, "hint:", hint)
+//		}
+//	}
+func Hint(err error) string {
+	if err == nil {
+		return ""
+	}
+	var (
+		naming  *NamingError
+		replace *ReplaceError
+		preTag  *PreTagError
+	)
+	switch {
+	case errors.As(err, &naming) && naming.Suggestion != "":
+		return fmt.Sprintf("使用符合命名约定的名称 %q，可以用 FixName 自动修正", naming.Suggestion)
+	case errors.As(err, &naming):
+		return fmt.Sprintf("按命名约定 %s 修改标签名称", naming.Pattern)
+	case errors.As(err, &replace):
+		return fmt.Sprintf("删除 %s 中的本地 replace 指令，或在 WithReplaceCheck 中允许这些模块", replace.File)
+	case errors.As(err, &preTag):
+		return fmt.Sprintf("修复 %q 报告的问题后重试", preTag.Command)
+	case errors.Is(err, ErrPushUnverified):
+		return "远程可能存在复制延迟，稍后确认远程标签，或增大 WithPushVerification 的超时"
+	}
+	kind := Kind(err)
+	if hint, ok := guidance[kind]; ok {
+		return hint
+	}
+	return hints[kind]
+}
@@ -0,0 +1,91 @@
+package gittag
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// NotFoundError 表示没有标签匹配给定的模式
+type NotFoundError struct {
+	Pattern string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("未找到匹配 %q 的标签", e.Pattern)
+}
+
+// Is 让 errors.Is(err, ErrTagNotFound) 对 NotFoundError 成立
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrTagNotFound
+}
+
+// ErrTagNotFound 表示没有标签匹配给定的模式，FindOne、FindMany 等返回的 *NotFoundError 满足 errors.Is(err, ErrTagNotFound)
+var ErrTagNotFound = errors.New("未找到标签")
+
+// ErrTagExists 表示本地或远程已存在同名标签，创建或推送标签时 git 报告标签已存在的错误满足 errors.Is(err, ErrTagExists)
+var ErrTagExists = errors.New("标签已存在")
+
+// ErrSeriesFrozen 表示标签属于已通过 Freeze 冻结的系列，不能创建、移动或删除
+var ErrSeriesFrozen = errors.New("标签系列已冻结")
+
+// ErrInvalidPattern 表示标签模式无效，例如以 "-" 开头或通配符不完整
+var ErrInvalidPattern = errors.New("无效的标签模式")
+
+// ErrInvalidCursor 表示 WithAfter 指定的分页游标无法定位，例如按名称以外的顺序分页时游标标签已被删除
+var ErrInvalidCursor = errors.New("无效的分页游标")
+
+// ErrReadOnly 表示仓库以 WithReadOnly 打开，不允许修改
+var ErrReadOnly = errors.New("仓库为只读模式")
+
+// ErrNotPermitted 表示受限的仓库句柄没有执行该操作的能力，见 Restrict
+var ErrNotPermitted = errors.New("操作未被授权")
+
+// ReplaceError 表示 go.mod 中存在本地 replace 指令，见 WithReplaceCheck
+type ReplaceError struct {
+	// File 是 go.mod 相对仓库根目录的路径
+	File string
+	// Replaces 是未在允许列表中的本地替换，例如 "example.com/lib => ../lib"
+	Replaces []string
+}
+
+func (e *ReplaceError) Error() string {
+	return fmt.Sprintf("%s 包含本地 replace 指令，发布后其他模块无法使用: %s", e.File, strings.Join(e.Replaces, "; "))
+}
+
+// PreTagError 表示 WithPreTagCommand 指定的命令执行失败，标签未被创建
+type PreTagError struct {
+	Command string
+	// Output 是命令的标准输出和标准错误，已脱敏
+	Output string
+	Err    error
+}
+
+func (e *PreTagError) Error() string {
+	if e.Output == "" {
+		return fmt.Sprintf("创建标签前执行 %q 失败: %v", e.Command, e.Err)
+	}
+	return fmt.Sprintf("创建标签前执行 %q 失败: %v\n%s", e.Command, e.Err, e.Output)
+}
+
+func (e *PreTagError) Unwrap() error {
+	return e.Err
+}
+
+// ErrPushUnverified 表示推送成功后远程标签仍未指向预期的对象，见 WithPushVerification
+var ErrPushUnverified = errors.New("推送后远程标签与本地不一致")
+
+// NamingError 表示标签名称不符合 WithNamingConvention 指定的命名约定
+type NamingError struct {
+	Tag     string
+	Pattern string
+	// Suggestion 是按约定修正后的名称，无法修正时为空
+	Suggestion string
+}
+
+func (e *NamingError) Error() string {
+	if e.Suggestion == "" {
+		return fmt.Sprintf("标签 %q 不符合命名约定 %s", e.Tag, e.Pattern)
+	}
+	return fmt.Sprintf("标签 %q 不符合命名约定 %s，建议使用 %q", e.Tag, e.Pattern, e.Suggestion)
+}
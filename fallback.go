@@ -0,0 +1,102 @@
+package gittag
+
+import (
+	"context"
+	"encoding/base64"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// httpsFallback 是 SSH 推送失败时改用的 HTTPS 地址和凭据
+type httpsFallback struct {
+	url      string
+	username string
+	token    string
+}
+
+// WithHTTPSFallback 在通过 SSH 推送因网络或认证错误失败时（例如防火墙拦截了 22 端口），
+// 用 token 通过 HTTPS 地址 remoteURL 重新推送一次；改用 HTTPS 的命令在 Trace 中的 Transport 为 "https"
+// token 通过 HTTP Basic 认证发送，用户名默认为 x-access-token，可以写在 remoteURL 中，例如 "https://oauth2@gitlab.com/org/app.git"
+// 只影响推送，WithPushVerification 等读取远程标签的操作仍使用原来的地址
+//
+// Example:
+//
+//	repo, err := gittag.Open(".", gittag.WithHTTPSFallback("https://github.com/acme/app.git", os.Getenv("GITHUB_TOKEN")))
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	err = repo.CreateTag("v1.2.0") // git@github.com:acme/app.git, or HTTPS if SSH is blocked
+func WithHTTPSFallback(remoteURL, token string) Option {
+	return func(r *Repository) {
+		f := &httpsFallback{url: remoteURL, username: "x-access-token", token: token}
+		if u, err := url.Parse(remoteURL); err == nil && u.User != nil {
+			f.username = u.User.Username()
+			u.User = nil
+			f.url = u.String()
+		}
+		r.httpsFallback = f
+	}
+}
+
+// fallbackRepository 在 SSH 推送因网络或认证错误失败且配置了 WithHTTPSFallback 时，
+// 返回改用 HTTPS 推送的仓库副本和替换了远程地址的参数，否则返回 nil
+func (r *Repository) fallbackRepository(ctx context.Context, args []string, err error) (*Repository, []string) {
+	if r.httpsFallback == nil || r.transport != "" || len(args) == 0 || args[0] != "push" {
+		return nil, nil
+	}
+	if kind := Kind(err); kind != KindNetworkTimeout && kind != KindAuthFailed {
+		return nil, nil
+	}
+	i := pushRemoteIndex(args)
+	if i < 0 || !isSSHURL(r.pushURL(ctx, args[i])) {
+		return nil, nil
+	}
+
+	f := r.httpsFallback
+	credentials := base64.StdEncoding.EncodeToString([]byte(f.username + ":" + f.token))
+	fallback := *r
+	fallback.config = append(slices.Clip(r.config), "http."+f.url+".extraHeader=Authorization: Basic "+credentials)
+	fallback.transport = "https"
+	fallbackArgs := slices.Clone(args)
+	fallbackArgs[i] = f.url
+	return &fallback, fallbackArgs
+}
+
+// pushRemoteIndex 返回 git push 参数中远程名称或地址的位置，没有时返回 -1
+func pushRemoteIndex(args []string) int {
+	for i := 1; i < len(args); i++ {
+		switch {
+		case args[i] == "--":
+			if i+1 < len(args) {
+				return i + 1
+			}
+			return -1
+		case !strings.HasPrefix(args[i], "-"):
+			return i
+		}
+	}
+	return -1
+}
+
+// pushURL 返回远程的推送地址，remote 不是远程名称时原样返回
+func (r *Repository) pushURL(ctx context.Context, remote string) string {
+	output, err := r.output(ctx, "remote", "get-url", "--push", remote)
+	if err != nil {
+		return remote
+	}
+	first, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+	return first
+}
+
+// isSSHURL 报告 remote 是否为 ssh:// 地址或 scp 风格的 user@host:path 地址
+func isSSHURL(remote string) bool {
+	if strings.HasPrefix(remote, "ssh://") || strings.HasPrefix(remote, "git+ssh://") {
+		return true
+	}
+	if strings.Contains(remote, "://") {
+		return false
+	}
+	host, _, ok := strings.Cut(remote, ":")
+	return ok && host != "" && !strings.Contains(host, "/")
+}
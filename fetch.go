@@ -0,0 +1,31 @@
+package gittag
+
+import (
+	"fmt"
+)
+
+// tagRefspec 将远程的所有标签映射到本地同名标签
+const tagRefspec = "+refs/tags/*:refs/tags/*"
+
+// FetchTags fetches all tags from the remote repository, overwriting local tags that differ.
+// In partial clones the configured filter (e.g. blob:none) is reused, so only commit and tag
+// objects are downloaded.
+// @return error - 如果获取过程中出现错误，返回相应的错误信息
+//
+// Example:
+//
+//	// Refresh local tags before computing the next version
+//	if err := gittag.FetchTags(); err != nil {
+//		log.Fatal(err)
+//	}
+func FetchTags() error {
+	return Default().FetchTags()
+}
+
+// FetchTags 获取仓库远程的所有标签，参见包级函数 FetchTags
+func (r *Repository) FetchTags() error {
+	if err := r.run(r.baseContext(), "fetch", "--quiet", r.remoteName(), tagRefspec); err != nil {
+		return fmt.Errorf("获取远程标签失败: %w", err)
+	}
+	return nil
+}
@@ -1,14 +1,19 @@
 package gittag
 
 import (
+	"errors"
 	"fmt"
-	"os/exec"
+	"regexp"
+	"sort"
 	"strings"
 )
 
 // FindOne searches for and returns a single Git tag matching the given pattern.
+// By default the first tag in git's lexical order is returned; use WithStrategy to pick
+// the highest version, the most recently created tag or an exact match instead.
 // @param pattern - The pattern to match tags against, e.g., "v1.*" matches all tags starting with "v1."
-// @return (string, error) - Returns the first matching tag and any error that occurred
+// @param opts - Optional settings, e.g. WithStrategy(HighestVersion)
+// @return (string, error) - Returns the selected tag, or a *NotFoundError (errors.Is ErrTagNotFound) when nothing matches
 //
 // Example:
 //
@@ -18,24 +23,102 @@ import (
 //		log.Fatal(err)
 //	}
 //	fmt.Printf("Found tag: %s\n", tag)
-func FindOne(pattern string) (string, error) {
-	cmd := exec.Command("git", "tag", "-l", pattern)
-	output, err := cmd.Output()
-	if err != nil {
-		return "", fmt.Errorf("查找标签失败: %v", err)
+//
+//	// Find the highest v1 release (v1.10.0 rather than v1.9.0)
+//	tag, err = gittag.FindOne("v1.*", gittag.WithStrategy(gittag.HighestVersion))
+//	if errors.Is(err, gittag.ErrTagNotFound) {
+//		fmt.Println("no v1 release yet")
+//	}
+func FindOne(pattern string, opts ...FindOption) (string, error) {
+	return Default().FindOne(pattern, opts...)
+}
+
+// FindOne returns a single tag in the repository matching pattern, see the package-level FindOne.
+func (r *Repository) FindOne(pattern string, opts ...FindOption) (string, error) {
+	cfg := &findConfig{scheme: r.scheme}
+	for _, opt := range opts {
+		opt(cfg)
 	}
 
-	tags := strings.Split(strings.TrimSpace(string(output)), "\n")
-	if len(tags) == 0 || (len(tags) == 1 && tags[0] == "") {
-		return "", fmt.Errorf("未找到匹配的标签")
+	switch cfg.strategy {
+	case MostRecent:
+		infos, err := r.ListInfo(pattern)
+		if err != nil {
+			return "", err
+		}
+		if len(infos) == 0 {
+			return "", &NotFoundError{Pattern: pattern}
+		}
+		latest := infos[0]
+		for _, info := range infos[1:] {
+			if info.Date.After(latest.Date) {
+				latest = info
+			}
+		}
+		return latest.Name, nil
 	}
 
+	tags, err := r.FindMany(pattern, opts...)
+	if err != nil {
+		return "", err
+	}
+	// WithAfter 越过最后一个标签时 FindMany 返回空页
+	if len(tags) == 0 {
+		return "", &NotFoundError{Pattern: pattern}
+	}
+	switch cfg.strategy {
+	case HighestVersion:
+		scheme := cfg.versionScheme()
+		best := ""
+		for _, tag := range tags {
+			if scheme.Match(tag) && (best == "" || scheme.Compare(tag, best) > 0) {
+				best = tag
+			}
+		}
+		if best == "" {
+			return "", &NotFoundError{Pattern: pattern}
+		}
+		return best, nil
+	case ExactOnly:
+		for _, tag := range tags {
+			if tag == pattern {
+				return tag, nil
+			}
+		}
+		return "", &NotFoundError{Pattern: pattern}
+	}
 	return tags[0], nil
 }
 
+// FindLatest returns the highest semantic version among the tags matching pattern, so v1.10.0
+// wins over v1.9.0 regardless of git's alphabetical order. Tags that are not semantic versions
+// are ignored; repositories opened with WithVersionScheme use that scheme instead. It is
+// shorthand for FindOne(pattern, WithStrategy(HighestVersion)).
+// @param pattern - 标签的匹配模式，例如："v1.*"
+// @return (string, error) - 返回最高的版本，没有符合版本方案的标签时返回 *NotFoundError
+//
+// Example:
+//
+//	latest, err := gittag.FindLatest("v*")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	fmt.Println(latest) // v1.10.0
+func FindLatest(pattern string) (string, error) {
+	return Default().FindLatest(pattern)
+}
+
+// FindLatest returns the highest version in the repository matching pattern, see the package-level FindLatest.
+func (r *Repository) FindLatest(pattern string) (string, error) {
+	return r.FindOne(pattern, WithStrategy(HighestVersion))
+}
+
 // FindMany searches for and returns all Git tags matching the given pattern.
+// Tags are sorted by name unless WithSort asks for version or creation order; WithLimit and
+// WithAfter return the result one page at a time.
 // @param pattern - The pattern to match tags against, e.g., "v1.*" matches all tags starting with "v1."
-// @return ([]string, error) - Returns all matching tags and any error that occurred
+// @param opts - Optional settings, e.g. WithSort(SortByVersionDesc), WithLimit(100), WithAfter("v1.9.0")
+// @return ([]string, error) - Returns all matching tags, or a *NotFoundError (errors.Is ErrTagNotFound) when nothing matches
 //
 // Example:
 //
@@ -47,17 +130,232 @@ func FindOne(pattern string) (string, error) {
 //	for _, tag := range tags {
 //		fmt.Printf("Found tag: %s\n", tag)
 //	}
-func FindMany(pattern string) ([]string, error) {
-	cmd := exec.Command("git", "tag", "-l", pattern)
-	output, err := cmd.Output()
+//
+//	// Newest version first: v1.10.0, v1.9.0, v1.2.0
+//	tags, err = gittag.FindMany("v1.*", gittag.WithSort(gittag.SortByVersionDesc))
+func FindMany(pattern string, opts ...FindOption) ([]string, error) {
+	return Default().FindMany(pattern, opts...)
+}
+
+// FindMany returns all tags in the repository matching pattern, see the package-level FindMany.
+func (r *Repository) FindMany(pattern string, opts ...FindOption) ([]string, error) {
+	cfg := &findConfig{scheme: r.scheme}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	normalized, err := normalizePattern(pattern)
 	if err != nil {
-		return nil, fmt.Errorf("查找标签失败: %v", err)
+		return nil, err
+	}
+	args := append([]string{"tag", "-l"}, cfg.sortArgs()...)
+	output, err := r.output(r.baseContext(), append(args, "--", r.tagName(normalized))...)
+	if err != nil {
+		return nil, fmt.Errorf("查找标签失败: %w", err)
 	}
 
 	tags := strings.Split(strings.TrimSpace(string(output)), "\n")
 	if len(tags) == 0 || (len(tags) == 1 && tags[0] == "") {
-		return nil, fmt.Errorf("未找到匹配的标签")
+		return nil, &NotFoundError{Pattern: pattern}
+	}
+
+	for i, tag := range tags {
+		tags[i] = r.displayName(tag)
+	}
+	return cfg.page(cfg.order(tags))
+}
+
+// sortArgs 返回 git tag -l 的排序参数，显式指定排序，不受 tag.sort、versionsort.suffix 等配置影响
+func (c *findConfig) sortArgs() []string {
+	switch c.sort {
+	case SortByCreated:
+		return []string{"--sort=creatordate"}
+	case SortByCreatedDesc:
+		return []string{"--sort=-creatordate"}
+	}
+	// 按版本排序时不符合方案的标签保持名称顺序
+	return []string{"--sort=refname"}
+}
+
+// order 对 git 按 sortArgs 排好的标签应用 git 无法完成的版本排序
+func (c *findConfig) order(tags []string) []string {
+	if c.sort == SortByVersion || c.sort == SortByVersionDesc {
+		return sortByVersion(tags, c.versionScheme(), c.sort == SortByVersionDesc)
 	}
+	return tags
+}
 
+// page 返回 WithAfter 和 WithLimit 选出的一页标签，超出末尾时返回空切片而不是 *NotFoundError
+func (c *findConfig) page(tags []string) ([]string, error) {
+	if c.after != "" {
+		start := -1
+		if c.sort == SortByName {
+			// git 按字节序排列标签名，游标标签被删除后仍能定位
+			start = sort.Search(len(tags), func(i int) bool { return tags[i] > c.after })
+		} else {
+			for i, tag := range tags {
+				if tag == c.after {
+					start = i + 1
+					break
+				}
+			}
+		}
+		if start < 0 {
+			return nil, fmt.Errorf("%w: %s 不存在，按名称以外的顺序分页时游标标签不能被删除", ErrInvalidCursor, c.after)
+		}
+		tags = tags[start:]
+	}
+	if c.limit > 0 && len(tags) > c.limit {
+		tags = tags[:c.limit]
+	}
 	return tags, nil
-}
\ No newline at end of file
+}
+
+// FindManyMulti looks up several patterns with a single git command and returns the matching
+// tags per pattern, for tools that check many release series at once and would otherwise spawn
+// one git process per FindMany. Each tag is put in the bucket of every pattern it matches.
+// Patterns without matches map to an empty slice instead of a *NotFoundError.
+// @param patterns - 标签的匹配模式，例如：[]string{"v1.*", "v2.*"}
+// @param opts - 可选配置，作用于每个模式的结果，例如 WithSort(SortByVersionDesc)、WithLimit(10)
+// @return (map[string][]string, error) - 返回每个模式匹配的标签，键为传入的模式
+//
+// Example:
+//
+//	series, err := gittag.FindManyMulti([]string{"v1.*", "v2.*", "v3.*"})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	for _, pattern := range []string{"v1.*", "v2.*", "v3.*"} {
+//		fmt.Printf("%s: %d releases\n", pattern, len(series[pattern]))
+//	}
+func FindManyMulti(patterns []string, opts ...FindOption) (map[string][]string, error) {
+	return Default().FindManyMulti(patterns, opts...)
+}
+
+// FindManyMulti returns the repository's tags for several patterns, see the package-level FindManyMulti.
+func (r *Repository) FindManyMulti(patterns []string, opts ...FindOption) (map[string][]string, error) {
+	cfg := &findConfig{scheme: r.scheme}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	result := make(map[string][]string, len(patterns))
+	if len(patterns) == 0 {
+		return result, nil
+	}
+	matchers := make([]*regexp.Regexp, len(patterns))
+	args := append(append([]string{"tag", "-l"}, cfg.sortArgs()...), "--")
+	for i, pattern := range patterns {
+		normalized, err := normalizePattern(pattern)
+		if err != nil {
+			return nil, err
+		}
+		if matchers[i], err = globRegexp(r.tagName(normalized)); err != nil {
+			return nil, fmt.Errorf("%w %q: %w", ErrInvalidPattern, pattern, err)
+		}
+		args = append(args, r.tagName(normalized))
+		result[pattern] = []string{}
+	}
+	output, err := r.output(r.baseContext(), args...)
+	if err != nil {
+		return nil, fmt.Errorf("查找标签失败: %w", err)
+	}
+
+	for _, tag := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if tag == "" {
+			continue
+		}
+		for i, pattern := range patterns {
+			if matchers[i].MatchString(tag) {
+				result[pattern] = append(result[pattern], r.displayName(tag))
+			}
+		}
+	}
+	for pattern, tags := range result {
+		if len(tags) == 0 {
+			continue
+		}
+		page, err := cfg.page(cfg.order(tags))
+		if err != nil {
+			return nil, fmt.Errorf("模式 %s: %w", pattern, err)
+		}
+		result[pattern] = page
+	}
+	return result, nil
+}
+
+// CompilePattern compiles a tag pattern into a regular expression with the same semantics as
+// FindMany: git's wildmatch without WM_PATHNAME, so * and ? also match "/", and the pattern must
+// match the whole tag name. It lets other backends and in-memory filters match tags like git does.
+// @param pattern - 标签模式，例如 "v1.*"、"release/*"，可以带 "refs/tags/" 前缀
+// @return (*regexp.Regexp, error) - 返回匹配完整标签名称的正则表达式，模式无效时返回满足 errors.Is(err, ErrInvalidPattern) 的错误
+//
+// Example:
+//
+//	match, err := gittag.CompilePattern("release/*")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	fmt.Println(match.MatchString("release/2024/v1.0.0")) // true
+func CompilePattern(pattern string) (*regexp.Regexp, error) {
+	normalized, err := normalizePattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+	match, err := globRegexp(normalized)
+	if err != nil {
+		return nil, fmt.Errorf("%w %q: %w", ErrInvalidPattern, pattern, err)
+	}
+	return match, nil
+}
+
+// globRegexp 将 git tag -l 的通配符模式转换为正则表达式，与 git 一样 * 和 ? 可以匹配 "/"
+func globRegexp(pattern string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				return nil, errors.New("缺少 ]")
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end + 1
+		default:
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+// versionScheme 返回 WithScheme 指定的版本方案，默认为 SemVer
+func (c *findConfig) versionScheme() Scheme {
+	if c.scheme == nil {
+		return SemVer
+	}
+	return c.scheme
+}
+
+// sortByVersion 按 scheme 排序 tags，不符合方案的标签保持原有顺序排在最后
+func sortByVersion(tags []string, scheme Scheme, desc bool) []string {
+	versions := SortVersions(tags, scheme)
+	if desc {
+		for i, j := 0, len(versions)-1; i < j; i, j = i+1, j-1 {
+			versions[i], versions[j] = versions[j], versions[i]
+		}
+	}
+	for _, tag := range tags {
+		if !scheme.Match(tag) {
+			versions = append(versions, tag)
+		}
+	}
+	return versions
+}
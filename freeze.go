@@ -0,0 +1,117 @@
+package gittag
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path"
+	"strings"
+)
+
+// frozenConfigKey 是记录冻结系列的 git 配置项，可以有多个值
+const frozenConfigKey = "gittag.frozen"
+
+// Freeze marks a release series as frozen, e.g. "v1.*" after it reaches end of life. Until
+// Unfreeze is called, creating, moving or deleting a matching tag fails with ErrSeriesFrozen.
+// The series is recorded in the repository's local git config (gittag.frozen).
+// @param pattern - 系列的匹配模式，语法同 path.Match，例如："v1.*"
+// @return error - 如果模式无效或写入配置失败，返回相应的错误信息
+//
+// Example:
+//
+//	// v1 has reached end of life
+//	if err := gittag.Freeze("v1.*"); err != nil {
+//		log.Fatal(err)
+//	}
+//	err := gittag.CreateTag("v1.9.0") // errors.Is(err, gittag.ErrSeriesFrozen) == true
+func Freeze(pattern string) error {
+	return Default().Freeze(pattern)
+}
+
+// Freeze freezes a tag series in the repository, see the package-level Freeze.
+func (r *Repository) Freeze(pattern string) error {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("无效的系列模式 %q: %w", pattern, err)
+	}
+	return r.withLock("Freeze", func() error {
+		frozen, err := r.Frozen()
+		if err != nil {
+			return err
+		}
+		for _, p := range frozen {
+			if p == pattern {
+				return nil
+			}
+		}
+		if err := r.run(r.baseContext(), "config", "--local", "--add", frozenConfigKey, pattern); err != nil {
+			return fmt.Errorf("冻结系列 %s 失败: %w", pattern, err)
+		}
+		return nil
+	})
+}
+
+// Unfreeze 解除 Freeze 对系列的冻结，pattern 必须与冻结时使用的模式完全相同
+func Unfreeze(pattern string) error {
+	return Default().Unfreeze(pattern)
+}
+
+// Unfreeze unfreezes a tag series in the repository, see the package-level Unfreeze.
+func (r *Repository) Unfreeze(pattern string) error {
+	return r.withLock("Unfreeze", func() error {
+		err := r.run(r.baseContext(), "config", "--local", "--fixed-value", "--unset-all", frozenConfigKey, pattern)
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 5 {
+			return nil // 该系列没有被冻结
+		}
+		if err != nil {
+			return fmt.Errorf("解冻系列 %s 失败: %w", pattern, err)
+		}
+		return nil
+	})
+}
+
+// Frozen 返回所有已冻结的系列模式
+func Frozen() ([]string, error) {
+	return Default().Frozen()
+}
+
+// Frozen lists the frozen series of the repository, see the package-level Frozen.
+func (r *Repository) Frozen() ([]string, error) {
+	output, err := r.output(r.baseContext(), "config", "--get-all", frozenConfigKey)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return nil, nil // 没有任何冻结的系列
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取冻结系列失败: %w", err)
+	}
+	return strings.Fields(string(output)), nil
+}
+
+// checkFrozen 在 tagName 属于已冻结的系列时返回 ErrSeriesFrozen，批量操作应使用 loadFrozen 只读取一次配置
+func (r *Repository) checkFrozen(tagName string) error {
+	frozen, err := r.loadFrozen()
+	if err != nil {
+		return err
+	}
+	return frozen.check(tagName)
+}
+
+// frozenSeries 是一次操作开始时读取的冻结系列
+type frozenSeries []string
+
+// loadFrozen 读取冻结系列，用于在批量操作中检查多个标签
+func (r *Repository) loadFrozen() (frozenSeries, error) {
+	frozen, err := r.Frozen()
+	return frozenSeries(frozen), err
+}
+
+// check 在 tagName 属于其中的系列时返回 ErrSeriesFrozen
+func (f frozenSeries) check(tagName string) error {
+	for _, pattern := range f {
+		if ok, _ := path.Match(pattern, tagName); ok {
+			return fmt.Errorf("%w: 标签 %s 属于系列 %q，请先调用 Unfreeze", ErrSeriesFrozen, tagName, pattern)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package gittag
+
+import "context"
+
+// Git runs an arbitrary git command in the default repository (see SetDefault), see (*Repository).Git.
+// @param ctx - 用于取消命令的上下文
+// @param args - git 子命令及参数，例如："notes", "show", "v1.0.0"
+// @return (stdout, stderr []byte, err error) - 返回命令的标准输出、标准错误以及错误信息
+//
+// Example:
+//
+//	stdout, stderr, err := gittag.Git(ctx, "notes", "--ref=release", "show", "v1.0.0")
+//	if err != nil {
+//		log.Fatalf("%v: %s", err, stderr)
+//	}
+//	fmt.Printf("%s", stdout)
+func Git(ctx context.Context, args ...string) (stdout, stderr []byte, err error) {
+	return Default().Git(ctx, args...)
+}
+
+// Git runs an arbitrary git command in the repository using the same runner as every other
+// operation, so repository options such as WithEnv and WithGitConfig apply and the returned
+// error is redacted. stdout and stderr are returned unmodified.
+func (r *Repository) Git(ctx context.Context, args ...string) (stdout, stderr []byte, err error) {
+	return r.exec(ctx, args...)
+}
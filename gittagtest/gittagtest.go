@@ -0,0 +1,223 @@
+// Package gittagtest 提供用于测试的临时 Git 仓库，可以在不依赖网络的情况下验证 gittag 的完整流程
+package gittagtest
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/afeiship/gittag"
+)
+
+// Repo 是一个测试用的临时 Git 仓库，测试结束后自动删除
+type Repo struct {
+	// Dir 是工作区目录
+	Dir string
+	// RemoteDir 是作为 origin 的本地裸仓库目录，未启用 WithRemote 时为空
+	RemoteDir string
+	// MirrorDirs 是 WithMirror 创建的裸仓库目录，键为远程名称
+	MirrorDirs map[string]string
+
+	t       testing.TB
+	ticks   int
+	mirrors []string
+}
+
+// baseDate 是第一个提交的时间，之后每次提交或打标签时间递增一分钟
+// 固定的时间使同样的操作在每次运行时得到相同的 SHA，递增使标签的先后顺序可预测
+var baseDate = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+type config struct {
+	commits int
+	tags    []string
+	remote  bool
+	mirrors []string
+}
+
+// Option 用于配置 NewRepo 创建的仓库
+type Option func(*config)
+
+// WithCommits 在仓库中创建 n 个空提交，默认为 1
+func WithCommits(n int) Option {
+	return func(c *config) {
+		c.commits = n
+	}
+}
+
+// WithTags 为每个标签创建一个新提交并打上附注标签，按传入顺序创建
+func WithTags(tags ...string) Option {
+	return func(c *config) {
+		c.tags = append(c.tags, tags...)
+	}
+}
+
+// WithRemote 创建一个本地裸仓库并将其配置为 origin，初始提交和标签会被推送过去
+func WithRemote() Option {
+	return func(c *config) {
+		c.remote = true
+	}
+}
+
+// WithMirror 为每个名称创建一个空的本地裸仓库并配置为同名远程，用于测试 PushToRemotes 等多远程流程
+func WithMirror(names ...string) Option {
+	return func(c *config) {
+		c.mirrors = append(c.mirrors, names...)
+	}
+}
+
+// NewRepo creates a temporary git repository for tests.
+// @param t - 当前测试，用于报告错误和注册清理函数
+// @param opts - 可选配置，例如 WithCommits、WithTags、WithRemote、WithMirror
+// @return *Repo - 返回测试仓库
+//
+// Example:
+//
+//	func TestRelease(t *testing.T) {
+//		repo := gittagtest.NewRepo(t, gittagtest.WithTags("v1.0.0"), gittagtest.WithRemote())
+//		if err := repo.Open().CreateTag("v1.1.0"); err != nil {
+//			t.Fatal(err)
+//		}
+//	}
+func NewRepo(t testing.TB, opts ...Option) *Repo {
+	t.Helper()
+	cfg := &config{commits: 1}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	root := t.TempDir()
+	r := &Repo{Dir: filepath.Join(root, "work"), t: t}
+	if err := os.Mkdir(r.Dir, 0o755); err != nil {
+		t.Fatalf("gittagtest: %v", err)
+	}
+	r.Git("init", "--quiet")
+	r.Git("config", "user.name", "gittagtest")
+	r.Git("config", "user.email", "gittagtest@example.com")
+	r.Git("config", "commit.gpgsign", "false")
+	r.Git("config", "tag.gpgsign", "false")
+
+	for i := 0; i < cfg.commits; i++ {
+		r.Commit(fmt.Sprintf("commit %d", i+1))
+	}
+	for _, tag := range cfg.tags {
+		r.Commit("release " + tag)
+		r.Tag(tag)
+	}
+
+	if cfg.remote {
+		r.RemoteDir = filepath.Join(root, "remote.git")
+		r.run(root, "init", "--quiet", "--bare", r.RemoteDir)
+		r.Git("remote", "add", "origin", r.RemoteDir)
+		r.Git("push", "--quiet", "origin", "HEAD", "--tags")
+	}
+	for _, name := range cfg.mirrors {
+		if r.MirrorDirs == nil {
+			r.MirrorDirs = map[string]string{}
+		}
+		dir := filepath.Join(root, name+".git")
+		r.run(root, "init", "--quiet", "--bare", dir)
+		r.Git("remote", "add", name, dir)
+		r.MirrorDirs[name] = dir
+		r.mirrors = append(r.mirrors, name)
+	}
+	return r
+}
+
+// Open 返回指向该测试仓库的 gittag.Repository
+func (r *Repo) Open() *gittag.Repository {
+	r.t.Helper()
+	repo, err := gittag.Open(r.Dir)
+	if err != nil {
+		r.t.Fatalf("gittagtest: %v", err)
+	}
+	return repo
+}
+
+// Git 在工作区中执行 git 命令并返回去除首尾空白的标准输出，失败时终止测试
+func (r *Repo) Git(args ...string) string {
+	r.t.Helper()
+	return r.run(r.Dir, args...)
+}
+
+// RemoteGit 在 origin 裸仓库中执行 git 命令，未启用 WithRemote 时终止测试
+func (r *Repo) RemoteGit(args ...string) string {
+	r.t.Helper()
+	if r.RemoteDir == "" {
+		r.t.Fatalf("gittagtest: repository has no remote, use WithRemote")
+	}
+	return r.run(r.RemoteDir, args...)
+}
+
+// Commit 创建一个空提交并返回其 SHA
+func (r *Repo) Commit(message string) string {
+	r.t.Helper()
+	r.ticks++
+	r.Git("commit", "--quiet", "--allow-empty", "-m", message)
+	return r.Git("rev-parse", "HEAD")
+}
+
+// Tag 在 HEAD 上创建一个附注标签
+func (r *Repo) Tag(name string) {
+	r.t.Helper()
+	r.ticks++
+	r.Git("tag", "-a", name, "-m", "release "+name)
+}
+
+// Tags 返回工作区中的所有本地标签
+func (r *Repo) Tags() []string {
+	r.t.Helper()
+	return splitLines(r.Git("tag", "-l"))
+}
+
+// RemoteTags 返回 origin 裸仓库中的所有标签
+func (r *Repo) RemoteTags() []string {
+	r.t.Helper()
+	return splitLines(r.RemoteGit("tag", "-l"))
+}
+
+// MirrorTags 返回 WithMirror 创建的远程 name 中的所有标签，name 不存在时终止测试
+func (r *Repo) MirrorTags(name string) []string {
+	r.t.Helper()
+	return splitLines(r.run(r.mirrorDir(name), "tag", "-l"))
+}
+
+func (r *Repo) mirrorDir(name string) string {
+	r.t.Helper()
+	dir, ok := r.MirrorDirs[name]
+	if !ok {
+		r.t.Fatalf("gittagtest: repository has no mirror %q, use WithMirror", name)
+	}
+	return dir
+}
+
+func (r *Repo) run(dir string, args ...string) string {
+	r.t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	date := baseDate.Add(time.Duration(r.ticks) * time.Minute).Format(time.RFC3339)
+	cmd.Env = append(os.Environ(),
+		"GIT_CONFIG_NOSYSTEM=1",
+		"GIT_AUTHOR_NAME=gittagtest",
+		"GIT_AUTHOR_EMAIL=gittagtest@example.com",
+		"GIT_COMMITTER_NAME=gittagtest",
+		"GIT_COMMITTER_EMAIL=gittagtest@example.com",
+		"GIT_AUTHOR_DATE="+date,
+		"GIT_COMMITTER_DATE="+date,
+	)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		r.t.Fatalf("gittagtest: git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
@@ -0,0 +1,64 @@
+package gittagtest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// UpdateEnv 是用于重新生成 golden 文件的环境变量，设置为 1 时 Golden 会覆盖期望文件
+const UpdateEnv = "GITTAG_UPDATE_GOLDEN"
+
+// stateFormat 输出标签名、对象类型以及标签最终指向的提交标题，不依赖随时间变化的 SHA
+const stateFormat = "%(refname:short) %(objecttype) %(if)%(*subject)%(then)%(*subject)%(else)%(subject)%(end)"
+
+// State 以文本形式描述工作区、origin 和 WithMirror 创建的远程中的标签，用于与 golden 文件比较
+//
+// Example:
+//
+//	gittagtest.Golden(t, "testdata/create_remote.golden", repo.State())
+func (r *Repo) State() string {
+	r.t.Helper()
+	var b strings.Builder
+	b.WriteString("[local]\n")
+	writeLines(&b, r.Git("for-each-ref", "--format="+stateFormat, "refs/tags"))
+	if r.RemoteDir != "" {
+		b.WriteString("[origin]\n")
+		writeLines(&b, r.RemoteGit("for-each-ref", "--format="+stateFormat, "refs/tags"))
+	}
+	for _, name := range r.mirrors {
+		b.WriteString("[" + name + "]\n")
+		writeLines(&b, r.run(r.mirrorDir(name), "for-each-ref", "--format="+stateFormat, "refs/tags"))
+	}
+	return b.String()
+}
+
+// Golden 将 got 与 path 处的 golden 文件比较，不一致时报告测试失败
+// 当环境变量 GITTAG_UPDATE_GOLDEN=1 时改为写入 golden 文件
+func Golden(t testing.TB, path string, got string) {
+	t.Helper()
+	if os.Getenv(UpdateEnv) == "1" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("gittagtest: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("gittagtest: %v", err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("gittagtest: %v (run with %s=1 to create it)", err, UpdateEnv)
+	}
+	if got != string(want) {
+		t.Errorf("%s mismatch:\n--- got\n%s--- want\n%s", path, got, want)
+	}
+}
+
+func writeLines(b *strings.Builder, s string) {
+	for _, line := range splitLines(s) {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+}
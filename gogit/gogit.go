@@ -0,0 +1,222 @@
+// Package gogit 提供基于 go-git 的纯 Go 标签后端，不依赖 git 可执行文件，
+// 适用于没有安装 git 的精简容器；方法与 gittag.Repository 的同名方法行为一致
+package gogit
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+
+	"github.com/afeiship/gittag"
+)
+
+// defaultMessage 与 gittag 的默认标签信息一致
+var defaultMessage = template.Must(template.New("message").Parse(gittag.DefaultMessageTemplate))
+
+// Backend 在一个仓库中通过 go-git 创建、删除和查找标签
+// 只支持 gittag.Repository 的基本操作，沙箱、追踪、重试、锁和签名等功能需要使用 git 可执行文件
+type Backend struct {
+	repo   *git.Repository
+	remote string
+	auth   transport.AuthMethod
+}
+
+// Option 用于配置 Backend
+type Option func(*Backend)
+
+// WithRemote 指定推送和删除远程标签使用的远程名称，默认为 "origin"
+func WithRemote(name string) Option {
+	return func(b *Backend) {
+		b.remote = name
+	}
+}
+
+// WithAuth 指定访问远程仓库的凭据，go-git 不会调用 git 的 credential helper
+//
+// Example:
+//
+//	backend, err := gogit.NewBackend(".", gogit.WithAuth(&http.BasicAuth{
+//		Username: "x-access-token",
+//		Password: os.Getenv("GITHUB_TOKEN"),
+//	}))
+func WithAuth(auth transport.AuthMethod) Option {
+	return func(b *Backend) {
+		b.auth = auth
+	}
+}
+
+// NewBackend opens the repository at path with go-git instead of the git binary, for minimal
+// containers and systems where git is not installed.
+// @param path - 仓库路径，可以是工作区内的任意目录
+// @param opts - 可选配置，例如 WithRemote、WithAuth
+// @return (*Backend, error) - 返回标签后端，如果 path 不在 Git 仓库中则返回错误
+//
+// Example:
+//
+//	backend, err := gogit.NewBackend("/srv/app")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	if err := backend.CreateLocal("v1.2.0"); err != nil {
+//		log.Fatal(err)
+//	}
+//	tags, err := backend.FindMany("v1.*")
+func NewBackend(path string, opts ...Option) (*Backend, error) {
+	repo, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, fmt.Errorf("打开仓库失败: %s 不是 Git 仓库: %w", path, err)
+	}
+	b := &Backend{repo: repo, remote: "origin"}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b, nil
+}
+
+// CreateLocal 在 HEAD 上创建一个本地附注标签，参数同 gittag.CreateLocal，标签已存在时满足 errors.Is(err, gittag.ErrTagExists)
+func (b *Backend) CreateLocal(tagName string, message ...string) error {
+	head, err := b.repo.Head()
+	if err != nil {
+		return fmt.Errorf("无法解析 HEAD: %w", err)
+	}
+	msg, err := tagMessage(tagName, message)
+	if err != nil {
+		return err
+	}
+	opts := &git.CreateTagOptions{Message: msg}
+	if tagger := envTagger(); tagger != nil {
+		opts.Tagger = tagger
+	}
+	if _, err := b.repo.CreateTag(tagName, head.Hash(), opts); err != nil {
+		if errors.Is(err, git.ErrTagExists) {
+			return fmt.Errorf("创建本地标签失败: %w: %s", gittag.ErrTagExists, tagName)
+		}
+		return fmt.Errorf("创建本地标签失败: %w", err)
+	}
+	return nil
+}
+
+// CreateRemote 将本地标签推送到远程仓库，参数同 gittag.CreateRemote
+func (b *Backend) CreateRemote(tagName string) error {
+	ref := plumbing.NewTagReferenceName(tagName)
+	if err := b.push(config.RefSpec(ref + ":" + ref)); err != nil {
+		return fmt.Errorf("推送标签到远程仓库失败: %w", err)
+	}
+	return nil
+}
+
+// CreateTag 创建本地标签并推送到远程仓库，参数同 gittag.CreateTag
+func (b *Backend) CreateTag(tagName string, message ...string) error {
+	if err := b.CreateLocal(tagName, message...); err != nil {
+		return err
+	}
+	return b.CreateRemote(tagName)
+}
+
+// DeleteLocal 删除本地标签，参数同 gittag.DeleteLocal，标签不存在时返回 *gittag.NotFoundError
+func (b *Backend) DeleteLocal(tagName string) error {
+	if err := b.repo.DeleteTag(tagName); err != nil {
+		if errors.Is(err, git.ErrTagNotFound) {
+			return &gittag.NotFoundError{Pattern: tagName}
+		}
+		return fmt.Errorf("删除本地标签失败: %w", err)
+	}
+	return nil
+}
+
+// DeleteRemote 删除远程标签，参数同 gittag.DeleteRemote
+func (b *Backend) DeleteRemote(tagName string) error {
+	if err := b.push(config.RefSpec(":" + plumbing.NewTagReferenceName(tagName))); err != nil {
+		return fmt.Errorf("删除远程标签失败: %w", err)
+	}
+	return nil
+}
+
+// DeleteTag 同时删除本地和远程标签，参数同 gittag.DeleteTag
+func (b *Backend) DeleteTag(tagName string) error {
+	if err := b.DeleteLocal(tagName); err != nil {
+		return err
+	}
+	return b.DeleteRemote(tagName)
+}
+
+// FindMany 返回匹配 pattern 的所有本地标签，按名称排序，没有匹配时返回 *gittag.NotFoundError
+func (b *Backend) FindMany(pattern string) ([]string, error) {
+	match, err := gittag.CompilePattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+	iter, err := b.repo.Tags()
+	if err != nil {
+		return nil, fmt.Errorf("查找标签失败: %w", err)
+	}
+	var tags []string
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		if name := ref.Name().Short(); match.MatchString(name) {
+			tags = append(tags, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("查找标签失败: %w", err)
+	}
+	if len(tags) == 0 {
+		return nil, &gittag.NotFoundError{Pattern: pattern}
+	}
+	sort.Strings(tags)
+	return tags, nil
+}
+
+// FindOne 返回匹配 pattern 的第一个标签（按名称排序），没有匹配时返回 *gittag.NotFoundError
+// 需要最高版本时对 FindMany 的结果使用 gittag.SortVersions
+func (b *Backend) FindOne(pattern string) (string, error) {
+	tags, err := b.FindMany(pattern)
+	if err != nil {
+		return "", err
+	}
+	return tags[0], nil
+}
+
+// push 推送 refspec 到远程仓库，远程已是最新时不视为错误
+func (b *Backend) push(refspec config.RefSpec) error {
+	err := b.repo.Push(&git.PushOptions{
+		RemoteName: b.remote,
+		RefSpecs:   []config.RefSpec{refspec},
+		Auth:       b.auth,
+	})
+	if errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return nil
+	}
+	return err
+}
+
+// tagMessage 返回标签信息，未指定时使用 gittag.DefaultMessageTemplate 生成
+func tagMessage(tagName string, message []string) (string, error) {
+	if len(message) > 0 && message[0] != "" {
+		return message[0], nil
+	}
+	var b strings.Builder
+	if err := defaultMessage.Execute(&b, gittag.MessageData{Tag: tagName, Version: tagName}); err != nil {
+		return "", fmt.Errorf("生成标签信息失败: %w", err)
+	}
+	return b.String(), nil
+}
+
+// envTagger 与 git 一样优先使用 GIT_COMMITTER_NAME 和 GIT_COMMITTER_EMAIL，未设置时由 go-git 读取 user.name 和 user.email
+func envTagger() *object.Signature {
+	name, email := os.Getenv("GIT_COMMITTER_NAME"), os.Getenv("GIT_COMMITTER_EMAIL")
+	if name == "" || email == "" {
+		return nil
+	}
+	return &object.Signature{Name: name, Email: email, When: time.Now()}
+}
@@ -0,0 +1,79 @@
+package gittag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// RequireHeadEquals returns an error unless HEAD is exactly the commit sha, so a CI job can assert
+// that the checkout has not moved since the pipeline started before it tags anything.
+// @param sha - 期望的提交，可以是完整或缩写的 SHA，也可以是其他能解析为提交的引用
+// @return error - HEAD 不等于 sha 时返回说明实际 HEAD 的错误
+//
+// Example:
+//
+//	if err := gittag.RequireHeadEquals(os.Getenv("GITHUB_SHA")); err != nil {
+//		log.Fatal(err)
+//	}
+//	err := gittag.CreateTag("v1.2.0")
+func RequireHeadEquals(sha string) error {
+	return Default().RequireHeadEquals(sha)
+}
+
+// RequireHeadEquals checks HEAD of the repository, see the package-level RequireHeadEquals.
+func (r *Repository) RequireHeadEquals(sha string) error {
+	ctx := r.baseContext()
+	want, err := r.revParse(ctx, sha+"^{commit}")
+	if err != nil {
+		return fmt.Errorf("无法解析提交 %s: %w", sha, err)
+	}
+	head, err := r.revParse(ctx, "HEAD^{commit}")
+	if err != nil {
+		return fmt.Errorf("无法解析 HEAD: %w", err)
+	}
+	if head != want {
+		return fmt.Errorf("HEAD 已从期望的 %s 移动到 %s，拒绝继续以免标记错误的提交", want, head)
+	}
+	return nil
+}
+
+// IsDetached 返回 HEAD 是否处于分离状态
+// git symbolic-ref --quiet 只在 HEAD 不是符号引用时以退出码 1 静默失败，其他失败（例如无法运行 git）作为错误返回
+func (r *Repository) IsDetached() (bool, error) {
+	_, _, err := r.exec(r.baseContext(), "symbolic-ref", "--quiet", "HEAD")
+	if err == nil {
+		return false, nil
+	}
+	var gitErr *GitError
+	if !errors.As(err, &gitErr) || gitErr.ExitCode != 1 || strings.TrimSpace(gitErr.Stderr) != "" {
+		return false, fmt.Errorf("无法读取 HEAD: %w", err)
+	}
+	if _, err := r.revParse(r.baseContext(), "HEAD"); err != nil {
+		return false, fmt.Errorf("无法解析 HEAD: %w", err)
+	}
+	return true, nil
+}
+
+// requireAttachedHead 在分离 HEAD 时返回错误，提示调用方显式指定目标
+func (r *Repository) requireAttachedHead() error {
+	detached, err := r.IsDetached()
+	if err != nil {
+		return err
+	}
+	if detached {
+		head, _ := r.revParse(r.baseContext(), "HEAD")
+		return fmt.Errorf("HEAD 处于分离状态（%s），请使用 WithTarget 指定要标记的提交，或使用 WithAllowDetached 明确标记当前 HEAD", head)
+	}
+	return nil
+}
+
+// revParse 将 rev 解析为完整的对象 SHA
+func (r *Repository) revParse(ctx context.Context, rev string) (string, error) {
+	output, err := r.output(ctx, "rev-parse", "--verify", "--quiet", "--end-of-options", rev)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
@@ -0,0 +1,74 @@
+package gittag
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// WithPreTagCommand 在创建标签前依次在仓库目录中通过 shell 执行 commands，可多次使用，按添加顺序执行
+// 命令以非零状态退出时不会创建标签，返回的 *PreTagError 包含命令的输出
+// 命令在获取仓库锁之前执行，耗时的命令不会阻塞其他进程；任意 shell 命令不受 Restrict 的限制，因此只有拥有 CapAll 的句柄可以使用
+// 命令可以通过环境变量 GITTAG_TAG 和 GITTAG_TARGET 读取要创建的标签和目标，例如 "go test ./..."
+func WithPreTagCommand(commands ...string) CreateOption {
+	return func(c *createConfig) {
+		c.preTagCommands = append(c.preTagCommands, commands...)
+	}
+}
+
+// preTag 在获取仓库锁之前执行 cfg 中创建标签前的命令，返回不再包含这些命令的配置副本
+func (r *Repository) preTag(tagName string, cfg *createConfig) (*createConfig, error) {
+	if len(cfg.preTagCommands) == 0 {
+		return cfg, nil
+	}
+	if err := r.checkWritable("修改标签"); err != nil {
+		return nil, err
+	}
+	// 名称不符合约定时不必先跑完耗时的命令
+	if err := r.checkNaming(tagName); err != nil {
+		return nil, err
+	}
+	target := cfg.target
+	if target == "" {
+		target = "HEAD"
+	}
+	if err := r.runPreTagCommands(r.baseContext(), tagName, target, cfg.preTagCommands); err != nil {
+		return nil, err
+	}
+	ran := *cfg
+	ran.preTagCommands = nil
+	return &ran, nil
+}
+
+// runPreTagCommands 依次执行创建标签前的命令，遇到失败立即停止
+func (r *Repository) runPreTagCommands(ctx context.Context, tagName, target string, commands []string) error {
+	if len(commands) == 0 {
+		return nil
+	}
+	// shell 命令可以执行任意 git 操作，受限的句柄不能借此绕过能力检查
+	if r.capabilities() != CapAll {
+		return fmt.Errorf("%w: 执行创建标签前的命令需要所有能力，该句柄只有 %s", ErrNotPermitted, r.capabilities())
+	}
+	hook := *r
+	hook.env = append(append([]string(nil), r.env...), "GITTAG_TAG="+tagName, "GITTAG_TARGET="+target)
+	for _, command := range commands {
+		if r.skipDryRunStep(append(slices.Clone(shell), shellQuote(command))...) {
+			continue
+		}
+		output, err := hook.tool(ctx, shell[0], append(shell[1:], command)...)
+		if err != nil {
+			return &PreTagError{Command: command, Output: Redact(strings.TrimSpace(string(output))), Err: err}
+		}
+	}
+	return nil
+}
+
+// shell 是执行 WithPreTagCommand 命令的 shell
+var shell = func() []string {
+	if runtime.GOOS == "windows" {
+		return []string{"cmd", "/C"}
+	}
+	return []string{"sh", "-c"}
+}()
@@ -0,0 +1,122 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/afeiship/gittag"
+)
+
+const (
+	// defaultPageSize 是未指定 limit 时每页的标签数量
+	defaultPageSize = 100
+	// defaultMaxPageSize 是未设置 TagList.MaxLimit 时每页的标签数量上限
+	defaultMaxPageSize = 1000
+)
+
+// sortOrders 是 sort 查询参数的取值
+var sortOrders = map[string]gittag.SortOrder{
+	"":             gittag.SortByName,
+	"name":         gittag.SortByName,
+	"version":      gittag.SortByVersion,
+	"version-desc": gittag.SortByVersionDesc,
+	"created":      gittag.SortByCreated,
+	"created-desc": gittag.SortByCreatedDesc,
+}
+
+// TagPage 是 TagList 返回的一页标签
+type TagPage struct {
+	Tags []string `json:"tags"`
+	// Next 是下一页的游标，作为 after 参数传回，为空表示已是最后一页
+	Next string `json:"next,omitempty"`
+}
+
+// TagList is an http.Handler that serves a repository's tags as JSON pages, so clients can page
+// through repositories with very large tag counts. GET parameters:
+//
+//   - pattern: 标签的匹配模式，默认为 "*"
+//   - sort: name（默认）、version、version-desc、created 或 created-desc
+//   - limit: 每页数量，默认 100，不超过 MaxLimit
+//   - after: 上一页响应中的 next 游标
+//
+// Pages are built with gittag.WithLimit and gittag.WithAfter, so name-ordered pages stay
+// consistent while tags are created or deleted between requests. A pattern without matches
+// yields an empty page.
+//
+// Example:
+//
+//	repo, err := gittag.Open("/srv/app")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	http.Handle("/tags", &httpapi.TagList{Repository: repo})
+//	// GET /tags?pattern=v1.*&sort=version-desc&limit=50
+//	// {"tags":["v1.10.0","v1.9.0",...],"next":"v1.0.3"}
+type TagList struct {
+	// Repository 是提供标签的仓库，为 nil 时使用 gittag.Default()
+	Repository *gittag.Repository
+	// MaxLimit 是每页数量的上限，默认为 1000
+	MaxLimit int
+}
+
+// ServeHTTP 实现 http.Handler
+func (l *TagList) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	query := req.URL.Query()
+	pattern := query.Get("pattern")
+	if pattern == "" {
+		pattern = "*"
+	}
+	order, ok := sortOrders[query.Get("sort")]
+	if !ok {
+		http.Error(w, "invalid sort", http.StatusBadRequest)
+		return
+	}
+	maxLimit := l.MaxLimit
+	if maxLimit <= 0 {
+		maxLimit = defaultMaxPageSize
+	}
+	limit := min(defaultPageSize, maxLimit)
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = min(n, maxLimit)
+	}
+
+	repo := l.Repository
+	if repo == nil {
+		repo = gittag.Default()
+	}
+	// 多取一个标签判断是否还有下一页
+	tags, err := repo.WithContext(req.Context()).FindMany(pattern,
+		gittag.WithSort(order), gittag.WithLimit(limit+1), gittag.WithAfter(query.Get("after")))
+	var notFound *gittag.NotFoundError
+	switch {
+	case errors.As(err, &notFound):
+		tags = []string{}
+	case errors.Is(err, gittag.ErrInvalidPattern), errors.Is(err, gittag.ErrInvalidCursor):
+		http.Error(w, gittag.Redact(err.Error()), http.StatusBadRequest)
+		return
+	case err != nil:
+		// git 执行失败、仓库不可读等是服务端错误
+		http.Error(w, gittag.Redact(err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	page := TagPage{Tags: tags}
+	if len(tags) > limit {
+		page.Tags = tags[:limit]
+		page.Next = tags[limit-1]
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
@@ -0,0 +1,216 @@
+// Package httpapi 提供与标签相关的 HTTP 接口，例如接收代码托管平台的标签推送事件
+package httpapi
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/afeiship/gittag"
+)
+
+// maxPayloadSize 限制 webhook 请求体的大小，GitHub 的上限是 25MB
+const maxPayloadSize = 25 << 20
+
+// zeroSHA 是托管平台用来表示标签不存在的对象 SHA
+var zeroSHA = strings.Repeat("0", 40)
+
+// Provider 表示发送 webhook 的代码托管平台
+type Provider string
+
+const (
+	GitHub Provider = "github"
+	GitLab Provider = "gitlab"
+)
+
+// Event 是从 webhook 中解析出的标签事件
+type Event struct {
+	gittag.TagEvent
+	Provider Provider `json:"provider"`
+	// Repository 是仓库全名，例如："afeiship/go-git-tag"
+	Repository string `json:"repository"`
+	// Sender 是推送标签的用户名
+	Sender string `json:"sender,omitempty"`
+}
+
+// Handler 处理一个标签事件，返回错误时 webhook 响应 500，托管平台会据此重试
+type Handler func(ctx context.Context, event Event) error
+
+// TagWebhook is an http.Handler that receives GitHub and GitLab tag push webhooks, verifies
+// them with the shared secret and calls the registered handlers with the parsed TagEvent.
+// Requests from a provider whose secret is not configured are rejected. Non-tag events
+// (e.g. branch pushes) are acknowledged with 204 and ignored.
+//
+// Example:
+//
+//	hook := &httpapi.TagWebhook{GitHubSecret: os.Getenv("WEBHOOK_SECRET")}
+//	hook.Handle(func(ctx context.Context, e httpapi.Event) error {
+//		log.Printf("%s %s on %s", e.Type, e.Name, e.Repository)
+//		return nil
+//	})
+//	http.Handle("/hooks/tags", hook)
+type TagWebhook struct {
+	// GitHubSecret 是 GitHub webhook 的 secret，用于校验 X-Hub-Signature-256
+	GitHubSecret string
+	// GitLabToken 是 GitLab webhook 的 secret token，用于校验 X-Gitlab-Token
+	GitLabToken string
+
+	mu       sync.RWMutex
+	handlers []Handler
+}
+
+// Handle 注册一个处理函数，每个事件按注册顺序依次调用，遇到错误即停止
+func (h *TagWebhook) Handle(fn Handler) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.handlers = append(h.handlers, fn)
+}
+
+// ServeHTTP 实现 http.Handler
+func (h *TagWebhook) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(req.Body, maxPayloadSize))
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	var event *Event
+	switch {
+	case req.Header.Get("X-GitHub-Event") != "":
+		if !h.validGitHub(req, body) {
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+		if req.Header.Get("X-GitHub-Event") == "push" {
+			event, err = parseGitHub(body)
+		}
+	case req.Header.Get("X-Gitlab-Event") != "":
+		if !h.validGitLab(req) {
+			http.Error(w, "invalid token", http.StatusUnauthorized)
+			return
+		}
+		if req.Header.Get("X-Gitlab-Event") == "Tag Push Hook" {
+			event, err = parseGitLab(body)
+		}
+	default:
+		http.Error(w, "unsupported webhook provider", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if event == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	h.mu.RLock()
+	handlers := append([]Handler(nil), h.handlers...)
+	h.mu.RUnlock()
+	for _, fn := range handlers {
+		if err := fn(req.Context(), *event); err != nil {
+			http.Error(w, gittag.Redact(err.Error()), http.StatusInternalServerError)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// validGitHub 校验 X-Hub-Signature-256 是否为请求体的 HMAC-SHA256
+func (h *TagWebhook) validGitHub(req *http.Request, body []byte) bool {
+	if h.GitHubSecret == "" {
+		return false
+	}
+	signature, ok := strings.CutPrefix(req.Header.Get("X-Hub-Signature-256"), "sha256=")
+	if !ok {
+		return false
+	}
+	got, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(h.GitHubSecret))
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+// validGitLab 校验 X-Gitlab-Token 是否与配置的 token 相同
+func (h *TagWebhook) validGitLab(req *http.Request) bool {
+	if h.GitLabToken == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(req.Header.Get("X-Gitlab-Token")), []byte(h.GitLabToken)) == 1
+}
+
+// parseGitHub 解析 GitHub 的 push 事件，分支推送返回 nil
+func parseGitHub(body []byte) (*Event, error) {
+	var payload struct {
+		Ref        string `json:"ref"`
+		Before     string `json:"before"`
+		After      string `json:"after"`
+		Repository struct {
+			FullName string `json:"full_name"`
+		} `json:"repository"`
+		Sender struct {
+			Login string `json:"login"`
+		} `json:"sender"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("invalid GitHub payload: %w", err)
+	}
+	return newEvent(GitHub, payload.Ref, payload.Before, payload.After, payload.Repository.FullName, payload.Sender.Login), nil
+}
+
+// parseGitLab 解析 GitLab 的 Tag Push Hook 事件
+func parseGitLab(body []byte) (*Event, error) {
+	var payload struct {
+		Ref     string `json:"ref"`
+		Before  string `json:"before"`
+		After   string `json:"after"`
+		Project struct {
+			PathWithNamespace string `json:"path_with_namespace"`
+		} `json:"project"`
+		UserUsername string `json:"user_username"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("invalid GitLab payload: %w", err)
+	}
+	return newEvent(GitLab, payload.Ref, payload.Before, payload.After, payload.Project.PathWithNamespace, payload.UserUsername), nil
+}
+
+// newEvent 根据推送前后的 SHA 判断事件类型，ref 不是标签时返回 nil
+func newEvent(provider Provider, ref, before, after, repository, sender string) *Event {
+	name, ok := strings.CutPrefix(ref, "refs/tags/")
+	if !ok {
+		return nil
+	}
+	event := &Event{Provider: provider, Repository: repository, Sender: sender}
+	event.Name = name
+	switch {
+	case isZero(before):
+		event.Type, event.Object = gittag.TagCreated, after
+	case isZero(after):
+		event.Type, event.OldObject = gittag.TagDeleted, before
+	default:
+		event.Type, event.Object, event.OldObject = gittag.TagMoved, after, before
+	}
+	return event
+}
+
+func isZero(sha string) bool {
+	return sha == "" || sha == zeroSHA
+}
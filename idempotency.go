@@ -0,0 +1,183 @@
+package gittag
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"time"
+)
+
+// idempotencyTTL 是已完成的幂等键的保留时间，覆盖 CI 重试和 webhook 重新投递的时间窗口
+const idempotencyTTL = 30 * 24 * time.Hour
+
+// WithIdempotencyKey returns a copy of the repository whose mutating operations (Create*,
+// Delete*, Bump*, Release, RemapTags, ...) are recorded under key once they succeed, together
+// with the operation and its result. Running the same operation again with a key that has
+// already completed does nothing and returns the recorded result (e.g. the tag BumpPatch
+// created), so a retried CI step or a redelivered webhook cannot create or delete a tag twice.
+// The key applies to the operation as a whole: the steps of a Release are not recorded
+// separately. Using a completed key for a different operation is an error. The journal also
+// keeps the actor (WithActor or ContextWithActor) that completed the operation. Keys are kept in the
+// repository's journal (.git/gittag/idempotency.json, or the Store set with WithStore) for 30
+// days; use one key per logical operation.
+// @param key - 幂等键，例如 CI 的流水线 id 或 webhook 的投递 id
+// @return *Repository - 返回绑定了幂等键的仓库副本
+//
+// Example:
+//
+//	delivery := r.Header.Get("X-GitHub-Delivery")
+//	if err := repo.WithIdempotencyKey(delivery).CreateTag("v1.2.0"); err != nil {
+//		log.Fatal(err)
+//	}
+func (r *Repository) WithIdempotencyKey(key string) *Repository {
+	bound := *r
+	bound.idempotencyKey = key
+	return &bound
+}
+
+// idempotencyEntry 是一个已完成的幂等键的记录
+type idempotencyEntry struct {
+	// Operation 是使用该键的操作，例如 "CreateTag"
+	Operation string `json:"operation"`
+	// Result 是操作的返回值（例如创建的标签名称），重放时原样返回
+	Result json.RawMessage `json:"result,omitempty"`
+	// Actor 是完成操作的操作者，没有指定操作者时为空
+	Actor     *Actor    `json:"actor,omitempty"`
+	Completed time.Time `json:"completed"`
+}
+
+// idempotencyJournal 是已完成的幂等键到其记录的映射
+type idempotencyJournal map[string]idempotencyEntry
+
+// idempotencyStoreKey 是幂等记录在 Store 中的键
+const idempotencyStoreKey = "idempotency.json"
+
+// loadIdempotency 读取幂等记录，记录不存在时返回空记录，调用方需持有仓库锁
+func (r *Repository) loadIdempotency(store Store) (idempotencyJournal, error) {
+	journal := idempotencyJournal{}
+	data, err := store.Get(r.baseContext(), idempotencyStoreKey)
+	if errors.Is(err, fs.ErrNotExist) {
+		return journal, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取幂等记录失败: %w", err)
+	}
+	if err := json.Unmarshal(data, &journal); err != nil {
+		return nil, fmt.Errorf("幂等记录已损坏: %w", err)
+	}
+	return journal, nil
+}
+
+// completedIdempotency 返回幂等键已完成的记录，未完成时返回 nil
+// 键已被其他操作使用时返回错误，调用方需持有仓库锁
+func (r *Repository) completedIdempotency(store Store, op string) (*idempotencyEntry, error) {
+	journal, err := r.loadIdempotency(store)
+	if err != nil {
+		return nil, err
+	}
+	entry, done := journal[r.idempotencyKey]
+	if !done || time.Since(entry.Completed) > idempotencyTTL {
+		return nil, nil
+	}
+	if entry.Operation != op {
+		return nil, fmt.Errorf("幂等键 %s 已用于操作 %s，不能用于 %s", r.idempotencyKey, entry.Operation, op)
+	}
+	return &entry, nil
+}
+
+// recordIdempotency 记录幂等键已完成、操作的返回值和 ctx 中的操作者，并清理过期的键，调用方需持有仓库锁
+func (r *Repository) recordIdempotency(ctx context.Context, store Store, op string, result any) error {
+	journal, err := r.loadIdempotency(store)
+	if err != nil {
+		return err
+	}
+	entry := idempotencyEntry{Operation: op, Actor: r.actorFor(ctx), Completed: time.Now()}
+	if data, err := json.Marshal(result); err == nil && string(data) != "null" {
+		entry.Result = data
+	}
+	for key, e := range journal {
+		if entry.Completed.Sub(e.Completed) > idempotencyTTL {
+			delete(journal, key)
+		}
+	}
+	journal[r.idempotencyKey] = entry
+	data, err := json.MarshalIndent(journal, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := store.Put(r.baseContext(), idempotencyStoreKey, data); err != nil {
+		return fmt.Errorf("记录幂等键 %s 失败: %w", r.idempotencyKey, err)
+	}
+	return nil
+}
+
+// replay 返回已完成的操作记录的返回值
+func replay[T any](entry *idempotencyEntry) (T, error) {
+	var result T
+	if len(entry.Result) > 0 {
+		if err := json.Unmarshal(entry.Result, &result); err != nil {
+			return result, fmt.Errorf("幂等记录已损坏: %w", err)
+		}
+	}
+	return result, nil
+}
+
+// idempotent 在幂等键已被 op 完成时跳过 fn 并返回记录的结果，否则执行 fn 并在成功后记录，调用方需持有仓库锁
+func idempotent[T any](r *Repository, op string, fn func() (T, error)) (T, error) {
+	if r.idempotencyKey == "" || r.dryRun != nil {
+		return fn()
+	}
+	var zero T
+	store, err := r.stateStore()
+	if err != nil {
+		return zero, err
+	}
+	entry, err := r.completedIdempotency(store, op)
+	if err != nil {
+		return zero, err
+	}
+	if entry != nil {
+		return replay[T](entry)
+	}
+	result, err := fn()
+	if err != nil {
+		return result, err
+	}
+	return result, r.recordIdempotency(r.baseContext(), store, op, result)
+}
+
+// once 对由多个加锁步骤组成的操作（例如 Release）只应用一次幂等键：
+// 幂等键已被 op 完成时返回记录的结果，否则在清除了幂等键的副本上执行 fn，成功后连同 ctx 中的操作者一起记录
+func once[T any](ctx context.Context, r *Repository, op string, fn func(inner *Repository) (T, error)) (T, error) {
+	if r.idempotencyKey == "" || r.dryRun != nil {
+		return fn(r)
+	}
+	var zero T
+	store, err := r.stateStore()
+	if err != nil {
+		return zero, err
+	}
+	var entry *idempotencyEntry
+	err = r.locked(func() error {
+		entry, err = r.completedIdempotency(store, op)
+		return err
+	})
+	if err != nil {
+		return zero, err
+	}
+	if entry != nil {
+		return replay[T](entry)
+	}
+
+	inner := *r
+	inner.idempotencyKey = ""
+	result, err := fn(&inner)
+	if err != nil {
+		return result, err
+	}
+	return result, r.locked(func() error {
+		return r.recordIdempotency(ctx, store, op, result)
+	})
+}
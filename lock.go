@@ -0,0 +1,109 @@
+package gittag
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// defaultLockTimeout 是等待其他进程释放仓库锁的默认时长
+const defaultLockTimeout = time.Minute
+
+// lockPollInterval 是重试获取仓库锁的间隔
+const lockPollInterval = 100 * time.Millisecond
+
+// WithLockTimeout 设置修改标签前等待仓库锁的最长时间，默认为 1 分钟
+func WithLockTimeout(timeout time.Duration) Option {
+	return func(r *Repository) {
+		r.lockTimeout = timeout
+	}
+}
+
+// withLock 在持有 .git/gittag.lock 的情况下执行公开操作 op 的 fn，并应用仓库绑定的幂等键
+// 该锁是跨进程的建议锁，只约束同样通过本包修改标签的进程
+func (r *Repository) withLock(op string, fn func() error) error {
+	// 没有返回值的操作记录为 null，不写入幂等记录
+	_, err := withLockResult(r, op, func() (*struct{}, error) {
+		return nil, fn()
+	})
+	return err
+}
+
+// withTagLock 同 withLock，并将操作的标签名称记录在幂等记录中
+func (r *Repository) withTagLock(op, tagName string, fn func() error) error {
+	_, err := withLockResult(r, op, func() (string, error) {
+		return tagName, fn()
+	})
+	return err
+}
+
+// withLockResult 同 withLock，fn 的返回值会记录在幂等记录中，重放时原样返回
+func withLockResult[T any](r *Repository, op string, fn func() (T, error)) (T, error) {
+	var result T
+	err := r.locked(func() error {
+		var err error
+		result, err = idempotent(r, op, fn)
+		return err
+	})
+	return result, err
+}
+
+// locked 在持有 .git/gittag.lock 的情况下执行 fn，不应用幂等键
+func (r *Repository) locked(fn func() error) error {
+	if err := r.checkWritable("修改标签"); err != nil {
+		return err
+	}
+	timeout := r.lockTimeout
+	if timeout == 0 {
+		timeout = defaultLockTimeout
+	}
+	ctx, cancel := context.WithTimeout(r.baseContext(), timeout)
+	defer cancel()
+
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return fn()
+}
+
+// lock 获取仓库锁，直到成功或 ctx 结束
+func (r *Repository) lock(ctx context.Context) (func(), error) {
+	gitDir, err := r.gitCommonDir()
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(gitDir, "gittag.lock")
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("打开仓库锁失败: %w", err)
+	}
+
+	ticker := time.NewTicker(lockPollInterval)
+	defer ticker.Stop()
+	for {
+		locked, err := tryLockFile(f)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("获取仓库锁失败: %w", err)
+		}
+		if locked {
+			return func() {
+				unlockFile(f)
+				f.Close()
+			}, nil
+		}
+		select {
+		case <-ctx.Done():
+			f.Close()
+			if err := r.baseContext().Err(); err != nil {
+				return nil, fmt.Errorf("等待仓库锁 %s 时被取消: %w", path, err)
+			}
+			return nil, fmt.Errorf("%w: %s，可能有其他任务正在修改标签: %v", errLockTimeout, path, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,23 @@
+//go:build unix
+
+package gittag
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// tryLockFile 尝试以非阻塞方式获取文件的排他锁
+func tryLockFile(f *os.File) (bool, error) {
+	err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if errors.Is(err, syscall.EWOULDBLOCK) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// unlockFile 释放文件锁
+func unlockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
@@ -0,0 +1,207 @@
+package gittag
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// manifestHeader 是 tags.lock 的第一行，格式变化时递增版本号
+const manifestHeader = "# gittag manifest v2"
+
+// manifestFooter 是 tags.lock 最后一行的前缀，后跟条目数，用于发现被截断的清单
+const manifestFooter = "# entries "
+
+// ManifestEntry 是 tags.lock 中的一行
+type ManifestEntry struct {
+	Name string
+	// Object 是标签引用指向的对象 SHA，附注标签被重建（即使信息相同）也会变化
+	Object string
+	// Commit 是标签最终指向的提交 SHA
+	Commit string
+	// MessageHash 是标签信息（不含签名）的 SHA-256
+	MessageHash string
+	// Signature 是签名格式，未签名时为 "unsigned"
+	Signature string
+}
+
+// String 返回 tags.lock 中的一行，字段以制表符分隔
+func (e ManifestEntry) String() string {
+	return strings.Join([]string{e.Name, e.Object, e.Commit, e.MessageHash, e.Signature}, "\t")
+}
+
+// manifestEntry 根据标签信息生成清单条目
+func manifestEntry(info TagInfo) ManifestEntry {
+	sum := sha256.Sum256([]byte(info.Message))
+	signature := info.SignatureFormat
+	if signature == "" {
+		signature = "unsigned"
+	}
+	return ManifestEntry{
+		Name:        info.Name,
+		Object:      info.Object,
+		Commit:      info.Commit,
+		MessageHash: "sha256:" + hex.EncodeToString(sum[:]),
+		Signature:   signature,
+	}
+}
+
+// ManifestError 描述 VerifyManifest 发现的所有差异
+type ManifestError struct {
+	Path       string
+	Mismatches []string
+}
+
+func (e *ManifestError) Error() string {
+	return fmt.Sprintf("标签清单 %s 校验失败:\n  %s", e.Path, strings.Join(e.Mismatches, "\n  "))
+}
+
+// WriteManifest records the tags matching pattern in a manifest file (conventionally tags.lock),
+// one line per tag with its object SHA, commit SHA, message hash and signature status, followed
+// by the entry count so that VerifyManifest rejects an empty or truncated file.
+// @param path - 清单文件路径，例如："tags.lock"
+// @param pattern - 标签匹配模式，例如："v*"
+// @return error - 如果读取标签或写入文件失败，返回相应的错误信息
+//
+// Example:
+//
+//	// Pin the release tags a build depends on
+//	if err := gittag.WriteManifest("tags.lock", "v*"); err != nil {
+//		log.Fatal(err)
+//	}
+func WriteManifest(path, pattern string) error {
+	return Default().WriteManifest(path, pattern)
+}
+
+// WriteManifest writes a manifest of the repository's tags, see the package-level WriteManifest.
+func (r *Repository) WriteManifest(path, pattern string) error {
+	infos, err := r.ListInfo(pattern)
+	if err != nil {
+		return err
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+	var b strings.Builder
+	b.WriteString(manifestHeader + "\n")
+	for _, info := range infos {
+		b.WriteString(manifestEntry(info).String() + "\n")
+	}
+	fmt.Fprintf(&b, "%s%d\n", manifestFooter, len(infos))
+	if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+		return fmt.Errorf("写入标签清单失败: %w", err)
+	}
+	return nil
+}
+
+// VerifyManifest checks that every tag recorded in a manifest still exists with the same object,
+// commit, message and signature status, detecting tags that were deleted, moved or rewritten.
+// @param path - 清单文件路径，例如："tags.lock"
+// @return error - 发现差异时返回 *ManifestError，列出所有差异
+//
+// Example:
+//
+//	if err := gittag.VerifyManifest("tags.lock"); err != nil {
+//		log.Fatalf("release tags were tampered with: %v", err)
+//	}
+func VerifyManifest(path string) error {
+	return Default().VerifyManifest(path)
+}
+
+// VerifyManifest verifies the repository's tags against a manifest, see the package-level VerifyManifest.
+func (r *Repository) VerifyManifest(path string) error {
+	want, err := readManifest(path)
+	if err != nil {
+		return err
+	}
+	infos, err := r.ListInfo("*")
+	if err != nil {
+		return err
+	}
+	current := make(map[string]ManifestEntry, len(infos))
+	for _, info := range infos {
+		current[info.Name] = manifestEntry(info)
+	}
+
+	var mismatches []string
+	for _, w := range want {
+		got, ok := current[w.Name]
+		switch {
+		case !ok:
+			mismatches = append(mismatches, fmt.Sprintf("%s: 标签已被删除", w.Name))
+		case got.Commit != w.Commit:
+			mismatches = append(mismatches, fmt.Sprintf("%s: 指向的提交从 %s 变为 %s", w.Name, w.Commit, got.Commit))
+		case got.MessageHash != w.MessageHash:
+			mismatches = append(mismatches, fmt.Sprintf("%s: 标签信息已被修改", w.Name))
+		case got.Signature != w.Signature:
+			mismatches = append(mismatches, fmt.Sprintf("%s: 签名状态从 %s 变为 %s", w.Name, w.Signature, got.Signature))
+		case got.Object != w.Object:
+			mismatches = append(mismatches, fmt.Sprintf("%s: 标签对象已被重建（%s -> %s）", w.Name, w.Object, got.Object))
+		}
+	}
+	if len(mismatches) > 0 {
+		return &ManifestError{Path: path, Mismatches: mismatches}
+	}
+	return nil
+}
+
+// readManifest 解析 tags.lock，缺少文件头或末尾的条目数（例如文件为空或被截断）时返回错误
+func readManifest(path string) ([]ManifestEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取标签清单失败: %w", err)
+	}
+	defer f.Close()
+
+	var entries []ManifestEntry
+	count := -1
+	scanner := bufio.NewScanner(f)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		if line == 1 {
+			if text != manifestHeader {
+				return nil, fmt.Errorf("标签清单 %s 格式不受支持: %q", path, text)
+			}
+			continue
+		}
+		if text == "" {
+			continue
+		}
+		if count >= 0 {
+			return nil, fmt.Errorf("标签清单 %s 第 %d 行位于条目数之后", path, line)
+		}
+		if n, ok := strings.CutPrefix(text, manifestFooter); ok {
+			if count, err = strconv.Atoi(n); err != nil || count < 0 {
+				return nil, fmt.Errorf("标签清单 %s 第 %d 行条目数无效: %q", path, line, text)
+			}
+			continue
+		}
+		if strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Split(text, "\t")
+		if len(fields) != 5 {
+			return nil, fmt.Errorf("标签清单 %s 第 %d 行格式错误", path, line)
+		}
+		entries = append(entries, ManifestEntry{fields[0], fields[1], fields[2], fields[3], fields[4]})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取标签清单失败: %w", err)
+	}
+	switch {
+	case line == 0:
+		return nil, fmt.Errorf("标签清单 %s 为空", path)
+	case count < 0:
+		return nil, fmt.Errorf("标签清单 %s 缺少条目数，文件可能被截断", path)
+	case count != len(entries):
+		return nil, fmt.Errorf("标签清单 %s 记录了 %d 个条目，实际读取到 %d 个，文件可能被截断", path, count, len(entries))
+	}
+	return entries, nil
+}
@@ -0,0 +1,99 @@
+package gittag
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// DefaultMessageTemplate 是未指定标签信息时使用的默认模板
+const DefaultMessageTemplate = "chore(release): {{.Tag}}"
+
+// MessageData 是默认标签信息模板可以使用的字段
+type MessageData struct {
+	// Tag 是标签名称，例如："api/v1.2.3"
+	Tag string
+	// Prefix 是版本号之前的部分，例如："api/v"，标签不以语义化版本结尾时为空
+	Prefix string
+	// Version 是不带前缀的版本号，例如："1.2.3"，标签不以语义化版本结尾时等于 Tag
+	Version string
+}
+
+var (
+	messageTemplateMu sync.RWMutex
+	messageTemplate   = template.Must(parseMessageTemplate(DefaultMessageTemplate))
+)
+
+// SetMessageTemplate sets the package-wide template for tag messages used whenever no explicit
+// message is given, replacing the "chore(release): <tag>" default. Repositories opened with
+// WithMessageTemplate use their own template instead.
+// @param tmpl - text/template 模板，可用字段见 MessageData，例如："release {{.Version}}"
+// @return error - 如果模板无法解析，返回相应的错误信息
+//
+// Example:
+//
+//	// Follow a "Release 1.2.3" convention instead of conventional commits
+//	if err := gittag.SetMessageTemplate("Release {{.Version}}"); err != nil {
+//		log.Fatal(err)
+//	}
+//	gittag.CreateTag("v1.2.3") // message: "Release 1.2.3"
+func SetMessageTemplate(tmpl string) error {
+	t, err := parseMessageTemplate(tmpl)
+	if err != nil {
+		return err
+	}
+	messageTemplateMu.Lock()
+	defer messageTemplateMu.Unlock()
+	messageTemplate = t
+	return nil
+}
+
+// WithMessageTemplate 设置该仓库未指定标签信息时使用的模板，可用字段见 MessageData
+// 模板在 Open 时解析，无法解析时 Open 返回错误
+func WithMessageTemplate(tmpl string) Option {
+	return func(r *Repository) {
+		r.messageTemplate = tmpl
+	}
+}
+
+// parseMessageTemplate 解析标签信息模板，并用示例数据试运行以尽早发现字段错误
+func parseMessageTemplate(tmpl string) (*template.Template, error) {
+	t, err := template.New("message").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("无效的标签信息模板 %q: %w", tmpl, err)
+	}
+	if err := t.Execute(new(strings.Builder), newMessageData("v1.0.0")); err != nil {
+		return nil, fmt.Errorf("无效的标签信息模板 %q: %w", tmpl, err)
+	}
+	return t, nil
+}
+
+func newMessageData(tagName string) MessageData {
+	data := MessageData{Tag: tagName, Version: tagName}
+	if m := versionTagPattern.FindStringSubmatch(tagName); m != nil {
+		data.Prefix, data.Version = m[1], m[2]
+	}
+	return data
+}
+
+// defaultMessage 根据仓库或包级模板生成标签的默认信息
+func (r *Repository) defaultMessage(tagName string) (string, error) {
+	var t *template.Template
+	if r.messageTemplate != "" {
+		parsed, err := parseMessageTemplate(r.messageTemplate)
+		if err != nil {
+			return "", err
+		}
+		t = parsed
+	} else {
+		messageTemplateMu.RLock()
+		t = messageTemplate
+		messageTemplateMu.RUnlock()
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, newMessageData(tagName)); err != nil {
+		return "", fmt.Errorf("生成标签信息失败: %w", err)
+	}
+	return b.String(), nil
+}
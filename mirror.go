@@ -0,0 +1,207 @@
+package gittag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// SuccessPolicy 决定推送到多个远程时整体是否成功
+type SuccessPolicy struct {
+	// quorum 是至少需要成功的远程数量，0 表示全部，-1 表示尽力而为
+	quorum int
+}
+
+// RequireAll 要求所有远程都推送成功，这是默认策略
+func RequireAll() SuccessPolicy {
+	return SuccessPolicy{}
+}
+
+// RequireQuorum 要求至少 n 个远程推送成功
+func RequireQuorum(n int) SuccessPolicy {
+	return SuccessPolicy{quorum: max(n, 1)}
+}
+
+// BestEffort 只要没有因为其他原因失败就视为成功，推送失败的远程只记录在结果中
+func BestEffort() SuccessPolicy {
+	return SuccessPolicy{quorum: -1}
+}
+
+// required 返回 total 个远程中至少需要成功的数量
+func (p SuccessPolicy) required(total int) int {
+	switch {
+	case p.quorum < 0:
+		return 0
+	case p.quorum == 0:
+		return total
+	}
+	return min(p.quorum, total)
+}
+
+type fanOutConfig struct {
+	policy  SuccessPolicy
+	retries int
+	backoff time.Duration
+}
+
+// FanOutOption 用于配置 PushToRemotes 的行为
+type FanOutOption func(*fanOutConfig)
+
+// WithSuccessPolicy 指定整体成功的条件，例如 RequireQuorum(2)，默认为 RequireAll
+func WithSuccessPolicy(policy SuccessPolicy) FanOutOption {
+	return func(c *fanOutConfig) {
+		c.policy = policy
+	}
+}
+
+// WithConvergeRetries 对推送失败的远程最多再重试 rounds 轮，每轮之间等待 backoff 并逐轮加倍
+func WithConvergeRetries(rounds int, backoff time.Duration) FanOutOption {
+	return func(c *fanOutConfig) {
+		c.retries = rounds
+		c.backoff = backoff
+	}
+}
+
+// FanOutResult 是推送到多个远程的结果
+type FanOutResult struct {
+	// Succeeded 是推送成功的远程，按名称排序
+	Succeeded []string
+	// Failed 是最终仍然失败的远程及其最后一次错误
+	Failed map[string]error
+}
+
+// ErrQuorumNotReached 表示推送成功的远程数量不满足 SuccessPolicy
+var ErrQuorumNotReached = errors.New("推送成功的远程数量不足")
+
+// PushToRemotes pushes a local tag to several remotes in parallel, for setups that mirror releases
+// to more than one server. Remotes that fail are retried for WithConvergeRetries rounds, then the
+// SuccessPolicy decides whether the operation as a whole failed; the result always reports which
+// remotes are still missing the tag so they can be converged later.
+// @param ctx - 用于取消推送和重试的上下文
+// @param tagName - 要推送的本地标签名称，例如："v1.0.0"
+// @param remotes - 远程名称或地址，例如：[]string{"origin", "mirror"}，重复的远程只推送一次，计入策略时也只算一个
+// @param opts - 可选配置，例如 WithSuccessPolicy、WithConvergeRetries
+// @return (*FanOutResult, error) - 返回各远程的推送结果，不满足策略时错误包装 ErrQuorumNotReached
+//
+// Example:
+//
+//	res, err := gittag.PushToRemotes(ctx, "v1.2.0", []string{"origin", "gitee", "backup"},
+//		gittag.WithSuccessPolicy(gittag.RequireQuorum(2)),
+//		gittag.WithConvergeRetries(3, time.Second))
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	for remote, err := range res.Failed {
+//		log.Printf("%s is behind: %v", remote, err)
+//	}
+func PushToRemotes(ctx context.Context, tagName string, remotes []string, opts ...FanOutOption) (*FanOutResult, error) {
+	return Default().PushToRemotes(ctx, tagName, remotes, opts...)
+}
+
+// PushToRemotes pushes a tag of the repository to several remotes, see the package-level PushToRemotes.
+func (r *Repository) PushToRemotes(ctx context.Context, tagName string, remotes []string, opts ...FanOutOption) (*FanOutResult, error) {
+	cfg := &fanOutConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if len(remotes) == 0 {
+		return nil, fmt.Errorf("至少需要一个远程")
+	}
+	// 重复的远程会被并发推送两次，并让成功数超过实际的远程数量
+	seen := make(map[string]bool, len(remotes))
+	unique := make([]string, 0, len(remotes))
+	for _, remote := range remotes {
+		if !seen[remote] {
+			seen[remote] = true
+			unique = append(unique, remote)
+		}
+	}
+	remotes = unique
+
+	res := &FanOutResult{Failed: map[string]error{}}
+	push := func() error {
+		ref := tagRef(r.tagName(tagName))
+		if _, err := r.revParse(ctx, ref); err != nil {
+			return &NotFoundError{Pattern: tagName}
+		}
+		pending := remotes
+		backoff := cfg.backoff
+		for round := 0; ; round++ {
+			for remote, err := range r.pushParallel(ctx, ref, pending) {
+				if err == nil {
+					res.Succeeded = append(res.Succeeded, remote)
+					delete(res.Failed, remote)
+				} else {
+					res.Failed[remote] = err
+				}
+			}
+			if len(res.Failed) == 0 || round >= cfg.retries {
+				return nil
+			}
+			pending = pending[:0:0]
+			for remote := range res.Failed {
+				pending = append(pending, remote)
+			}
+			timer := time.NewTimer(backoff)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+			backoff *= 2
+		}
+	}
+	// 幂等键重放时 res 只包含记录的成功远程
+	succeeded, err := withLockResult(r, "PushToRemotes", func() ([]string, error) {
+		err := push()
+		sort.Strings(res.Succeeded)
+		return res.Succeeded, err
+	})
+	res.Succeeded = succeeded
+	if err != nil {
+		return res, err
+	}
+	if need := cfg.policy.required(len(remotes)); len(res.Succeeded) < need {
+		errs := []error{fmt.Errorf("%w: %d/%d 个远程成功，需要 %d 个", ErrQuorumNotReached, len(res.Succeeded), len(remotes), need)}
+		for _, remote := range sortedKeys(res.Failed) {
+			errs = append(errs, fmt.Errorf("%s: %w", Redact(remote), res.Failed[remote]))
+		}
+		return res, errors.Join(errs...)
+	}
+	return res, nil
+}
+
+// pushParallel 同时将 ref 推送到多个远程，返回每个远程的错误
+func (r *Repository) pushParallel(ctx context.Context, ref string, remotes []string) map[string]error {
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = make(map[string]error, len(remotes))
+	)
+	for _, remote := range remotes {
+		wg.Add(1)
+		go func(remote string) {
+			defer wg.Done()
+			err := r.run(ctx, "push", "--", remote, ref)
+			mu.Lock()
+			results[remote] = err
+			mu.Unlock()
+		}(remote)
+	}
+	wg.Wait()
+	return results
+}
+
+// sortedKeys 返回 map 中按字典序排序的键
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,143 @@
+package gittag
+
+import (
+	"context"
+	"path"
+	"strings"
+)
+
+// goModFile 是从 go.mod 中解析出的模块路径、依赖和替换
+type goModFile struct {
+	module   string
+	requires map[string]string
+	replaces []goModReplace
+}
+
+// goModReplace 是一条 replace 指令，newVersion 为空表示替换为本地目录
+type goModReplace struct {
+	old, oldVersion string
+	new, newVersion string
+}
+
+// local 判断替换目标是否为本地目录
+func (r goModReplace) local() bool {
+	return r.newVersion == ""
+}
+
+func (r goModReplace) String() string {
+	old, new := r.old, r.new
+	if r.oldVersion != "" {
+		old += " " + r.oldVersion
+	}
+	if r.newVersion != "" {
+		new += " " + r.newVersion
+	}
+	return old + " => " + new
+}
+
+// parseGoMod 解析 go.mod 中的 module、require 和 replace 指令，忽略注释以及其他指令
+func parseGoMod(data []byte) goModFile {
+	mod := goModFile{requires: map[string]string{}}
+	inRequire, inReplace := false, false
+	for _, line := range strings.Split(string(data), "\n") {
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+		case (inRequire || inReplace) && fields[0] == ")":
+			inRequire, inReplace = false, false
+		case inRequire && len(fields) >= 2:
+			mod.requires[unquoteModPath(fields[0])] = fields[1]
+		case inReplace:
+			if replace, ok := parseReplace(fields); ok {
+				mod.replaces = append(mod.replaces, replace)
+			}
+		case fields[0] == "module" && len(fields) >= 2:
+			mod.module = unquoteModPath(fields[1])
+		case fields[0] == "require" && len(fields) == 2 && fields[1] == "(":
+			inRequire = true
+		case fields[0] == "require" && len(fields) >= 3:
+			mod.requires[unquoteModPath(fields[1])] = fields[2]
+		case fields[0] == "replace" && len(fields) == 2 && fields[1] == "(":
+			inReplace = true
+		case fields[0] == "replace":
+			if replace, ok := parseReplace(fields[1:]); ok {
+				mod.replaces = append(mod.replaces, replace)
+			}
+		}
+	}
+	return mod
+}
+
+// parseReplace 解析 "old [version] => new [version]" 形式的替换
+func parseReplace(fields []string) (goModReplace, bool) {
+	var replace goModReplace
+	switch {
+	case len(fields) >= 3 && fields[1] == "=>":
+		replace.old, fields = unquoteModPath(fields[0]), fields[2:]
+	case len(fields) >= 4 && fields[2] == "=>":
+		replace.old, replace.oldVersion, fields = unquoteModPath(fields[0]), fields[1], fields[3:]
+	default:
+		return replace, false
+	}
+	replace.new = unquoteModPath(fields[0])
+	if len(fields) >= 2 {
+		replace.newVersion = fields[1]
+	}
+	return replace, true
+}
+
+// unquoteModPath 去掉模块路径两侧的引号
+func unquoteModPath(path string) string {
+	return strings.Trim(path, "\"`")
+}
+
+// fileAt 返回 rev 中 path 文件的内容，文件不存在时返回 false
+func (r *Repository) fileAt(ctx context.Context, rev, path string) ([]byte, bool) {
+	output, err := r.output(ctx, "cat-file", "blob", rev+":"+path)
+	if err != nil {
+		return nil, false
+	}
+	return output, true
+}
+
+// moduleGoMod 返回标签对应模块的 go.mod 路径，"a/b/v1.2.0" 对应 a/b/go.mod，找不到时使用根目录的 go.mod
+func (r *Repository) moduleGoMod(ctx context.Context, rev, tagName string) ([]byte, string, bool) {
+	if dir := path.Dir(tagName); dir != "." {
+		if data, ok := r.fileAt(ctx, rev, dir+"/go.mod"); ok {
+			return data, dir + "/go.mod", true
+		}
+	}
+	data, ok := r.fileAt(ctx, rev, "go.mod")
+	return data, "go.mod", ok
+}
+
+// checkReplaces 在 rev 中标签对应模块的 go.mod 包含不在 allow 中的本地 replace 指令时返回 *ReplaceError
+func (r *Repository) checkReplaces(ctx context.Context, tagName, rev string, allow []string) error {
+	data, file, ok := r.moduleGoMod(ctx, rev, tagName)
+	if !ok {
+		return nil
+	}
+	var local []string
+	for _, replace := range parseGoMod(data).replaces {
+		if replace.local() && !matchAny(allow, replace.old) {
+			local = append(local, replace.String())
+		}
+	}
+	if len(local) > 0 {
+		return &ReplaceError{File: file, Replaces: local}
+	}
+	return nil
+}
+
+// matchAny 判断 name 是否匹配任意一个 path.Match 模式
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
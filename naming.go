@@ -0,0 +1,75 @@
+package gittag
+
+import "regexp"
+
+// NamingConvention 描述团队约定的标签命名规则，见 WithNamingConvention
+type NamingConvention struct {
+	// Pattern 是合法的标签名称必须匹配的正则表达式，通常以 ^ 和 $ 锚定
+	Pattern *regexp.Regexp
+	// Canonical 将不规范的输入转换为规范形式，例如补上前导 v；为 nil 时 FixName 只做检查
+	Canonical func(name string) (string, error)
+}
+
+// SemverNaming 要求标签为带 v 前缀的语义化版本，例如 "v1.2.3"、"v2.0.0-rc.1"，
+// 并通过 Normalize 修正 "1.2"、"release-1.2.3" 等常见写法
+var SemverNaming = NamingConvention{
+	Pattern: regexp.MustCompile(`^v(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(?:-[0-9A-Za-z.-]+)?(?:\+[0-9A-Za-z.-]+)?$`),
+	Canonical: func(name string) (string, error) {
+		return Normalize(name)
+	},
+}
+
+// WithNamingConvention 让仓库创建的每个标签都必须符合 c，不符合时返回 *NamingError 并附上 FixName 的建议
+func WithNamingConvention(c NamingConvention) Option {
+	return func(r *Repository) {
+		r.naming = &c
+	}
+}
+
+// FixName returns the canonical form of name under the repository's naming convention (see
+// WithNamingConvention), so release scripts can accept what people type and tag what the team
+// agreed on. Names that already conform are returned unchanged; without a convention every
+// name is returned unchanged.
+// @param name - 用户输入的标签名称，例如："1.2"
+// @return (string, error) - 返回规范的标签名称，例如："v1.2.0"；无法修正时返回 *NamingError
+//
+// Example:
+//
+//	repo, _ := gittag.Open(".", gittag.WithNamingConvention(gittag.SemverNaming))
+//	tag, err := repo.FixName(os.Args[1]) // "1.2" -> "v1.2.0"
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	err = repo.CreateTag(tag)
+func FixName(name string) (string, error) {
+	return Default().FixName(name)
+}
+
+// FixName fixes name under the repository's naming convention, see the package-level FixName.
+func (r *Repository) FixName(name string) (string, error) {
+	c := r.naming
+	if c == nil || c.Pattern.MatchString(name) {
+		return name, nil
+	}
+	err := &NamingError{Tag: name, Pattern: c.Pattern.String()}
+	if c.Canonical == nil {
+		return "", err
+	}
+	fixed, ferr := c.Canonical(name)
+	if ferr != nil || !c.Pattern.MatchString(fixed) {
+		return "", err
+	}
+	return fixed, nil
+}
+
+// checkNaming 在标签名称不符合命名约定时返回带修正建议的 *NamingError
+func (r *Repository) checkNaming(name string) error {
+	if r.naming == nil || r.naming.Pattern.MatchString(name) {
+		return nil
+	}
+	fixed, err := r.FixName(name)
+	if err != nil {
+		return err
+	}
+	return &NamingError{Tag: name, Pattern: r.naming.Pattern.String(), Suggestion: fixed}
+}
@@ -0,0 +1,108 @@
+package gittag
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// defaultVersionPrefixes 是 Normalize 默认去掉的前缀，不区分大小写，按顺序只去掉第一个匹配的前缀
+var defaultVersionPrefixes = []string{"release-", "release/", "release_", "rel-", "version-", "v"}
+
+// looseVersionPattern 匹配 1 到 3 段数字组成的版本，以及可选的预发布和构建元数据
+var looseVersionPattern = regexp.MustCompile(`^(\d+)(?:\.(\d+))?(?:\.(\d+))?(?:-([0-9A-Za-z.-]+))?(?:\+([0-9A-Za-z.-]+))?$`)
+
+type normalizeConfig struct {
+	prefixes  []string
+	canonical string
+}
+
+// NormalizeOption 用于配置 Normalize 的行为
+type NormalizeOption func(*normalizeConfig)
+
+// WithVersionPrefixes 指定额外需要去掉的前缀，例如 "RELEASE_"，在默认前缀之前尝试
+func WithVersionPrefixes(prefixes ...string) NormalizeOption {
+	return func(c *normalizeConfig) {
+		c.prefixes = append(append([]string(nil), prefixes...), c.prefixes...)
+	}
+}
+
+// WithCanonicalPrefix 指定规范化结果的前缀，默认为 "v"，传入空字符串得到不带前缀的版本
+func WithCanonicalPrefix(prefix string) NormalizeOption {
+	return func(c *normalizeConfig) {
+		c.canonical = prefix
+	}
+}
+
+// Normalize maps a loosely written version to its canonical semantic version, e.g. "1.2",
+// "v01.2.3" and "release-1.2.3" all become "v1.2.3". Missing minor and patch numbers become 0,
+// leading zeros are dropped and prerelease and build suffixes are kept, so legacy tag sets can be
+// cleaned up and compared. BumpPatch and the SemVer scheme's Compare read tags through it too.
+// @param tag - 要规范化的标签或版本号，例如："release-1.2"
+// @param opts - 可选配置，例如 WithVersionPrefixes、WithCanonicalPrefix
+// @return (string, error) - 返回规范化的版本，例如："v1.2.0"，无法识别为版本时返回错误
+//
+// Example:
+//
+//	for _, tag := range []string{"1.2", "v01.2.3", "release-1.2.3", "RELEASE_2.0"} {
+//		canonical, err := gittag.Normalize(tag, gittag.WithVersionPrefixes("RELEASE_"))
+//		if err != nil {
+//			log.Printf("skip %s: %v", tag, err)
+//			continue
+//		}
+//		fmt.Println(tag, "->", canonical) // v1.2.0, v1.2.3, v1.2.3, v2.0.0
+//	}
+func Normalize(tag string, opts ...NormalizeOption) (string, error) {
+	cfg := &normalizeConfig{prefixes: defaultVersionPrefixes, canonical: "v"}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	s := strings.TrimSpace(tag)
+	for _, prefix := range cfg.prefixes {
+		if len(s) > len(prefix) && strings.EqualFold(s[:len(prefix)], prefix) {
+			s = s[len(prefix):]
+			break
+		}
+	}
+	m := looseVersionPattern.FindStringSubmatch(s)
+	if m == nil {
+		return "", fmt.Errorf("无法将 %q 识别为版本号", tag)
+	}
+
+	var b strings.Builder
+	b.WriteString(cfg.canonical)
+	for i, part := range m[1:4] {
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		n := 0
+		if part != "" {
+			var err error
+			if n, err = strconv.Atoi(part); err != nil {
+				return "", fmt.Errorf("版本号 %q 的数字过大: %w", tag, err)
+			}
+		}
+		b.WriteString(strconv.Itoa(n))
+	}
+	if m[4] != "" {
+		ids := strings.Split(m[4], ".")
+		for i, id := range ids {
+			// 语义化版本不允许数字标识符有前导零
+			if n, err := strconv.Atoi(id); err == nil && id != "" {
+				ids[i] = strconv.Itoa(n)
+			}
+		}
+		b.WriteString("-" + strings.Join(ids, "."))
+	}
+	if m[5] != "" {
+		b.WriteString("+" + m[5])
+	}
+
+	canonical := b.String()
+	if _, ok := parseSemver(strings.TrimPrefix(canonical, cfg.canonical)); !ok {
+		return "", fmt.Errorf("无法将 %q 识别为版本号", tag)
+	}
+	return canonical, nil
+}
@@ -0,0 +1,134 @@
+package gittag
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Orphans returns the tags whose commits are not reachable from any local or remote-tracking
+// branch, typically leftovers from deleted branches or rewritten history.
+// @return ([]TagInfo, error) - 返回孤立标签的信息，按名称排序
+//
+// Example:
+//
+//	orphans, err := gittag.Orphans()
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	for _, info := range orphans {
+//		fmt.Printf("%s -> %s\n", info.Name, info.Commit[:7])
+//	}
+func Orphans() ([]TagInfo, error) {
+	return Default().Orphans()
+}
+
+// Orphans lists the repository's tags unreachable from branches, see the package-level Orphans.
+func (r *Repository) Orphans() ([]TagInfo, error) {
+	infos, err := r.ListInfo("*")
+	if err != nil {
+		return nil, err
+	}
+	var commits strings.Builder
+	for _, info := range infos {
+		if info.Commit != "" {
+			commits.WriteString(info.Commit + "\n")
+		}
+	}
+	if commits.Len() == 0 {
+		return nil, nil
+	}
+
+	// 列出从标签可达、但从任何分支都不可达的提交，标签本身的提交在其中即为孤立标签
+	output, _, err := r.execInput(r.baseContext(), strings.NewReader(commits.String()),
+		"rev-list", "--stdin", "--not", "--branches", "--remotes")
+	if err != nil {
+		return nil, fmt.Errorf("计算提交可达性失败: %w", err)
+	}
+	unreachable := make(map[string]bool)
+	for _, sha := range strings.Fields(string(output)) {
+		unreachable[sha] = true
+	}
+
+	var orphans []TagInfo
+	for _, info := range infos {
+		if unreachable[info.Commit] {
+			orphans = append(orphans, info)
+		}
+	}
+	return orphans, nil
+}
+
+// DeleteOrphans 删除 Orphans 返回的所有本地标签，返回被删除的标签名称，远程标签不受影响
+func DeleteOrphans() ([]string, error) {
+	return Default().DeleteOrphans()
+}
+
+// DeleteOrphans deletes the repository's orphaned local tags, see the package-level DeleteOrphans.
+func (r *Repository) DeleteOrphans() ([]string, error) {
+	deleted, err := withLockResult(r, "DeleteOrphans", func() ([]string, error) {
+		orphans, err := r.Orphans()
+		if err != nil {
+			return nil, err
+		}
+		var deleted []string
+		for _, info := range orphans {
+			if err := r.deleteLocal(info.Name); err != nil {
+				return deleted, fmt.Errorf("删除标签 %s 失败: %w", info.Name, err)
+			}
+			deleted = append(deleted, info.Name)
+		}
+		return deleted, nil
+	})
+	return deleted, err
+}
+
+// RewriteImpact reports the tags that point into the history a planned rebase or force-push of
+// the current branch onto newBase would rewrite, i.e. the commits in newBase..HEAD. After the
+// rewrite these tags keep pointing at the old commits and will no longer be on the branch.
+// @param newBase - 变基的目标，例如："origin/main"，或 git rebase -i 的起点 "HEAD~5"
+// @return ([]TagInfo, error) - 返回受影响的标签信息
+//
+// Example:
+//
+//	impacted, err := gittag.RewriteImpact("origin/main")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	for _, info := range impacted {
+//		fmt.Printf("%s will dangle (points at %s)\n", info.Name, info.Commit[:7])
+//	}
+func RewriteImpact(newBase string) ([]TagInfo, error) {
+	return Default().RewriteImpact(newBase)
+}
+
+// RewriteImpact lists the repository's tags affected by a history rewrite, see the package-level RewriteImpact.
+func (r *Repository) RewriteImpact(newBase string) ([]TagInfo, error) {
+	ctx := r.baseContext()
+	base, err := r.revParse(ctx, newBase+"^{commit}")
+	if err != nil {
+		return nil, fmt.Errorf("无法解析提交 %s: %w", newBase, err)
+	}
+	output, err := r.output(ctx, "rev-list", "--end-of-options", base+"..HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("读取将被改写的提交失败: %w", err)
+	}
+	rewritten := make(map[string]bool)
+	for _, sha := range strings.Fields(string(output)) {
+		rewritten[sha] = true
+	}
+	if len(rewritten) == 0 {
+		return nil, nil
+	}
+
+	infos, err := r.ListInfo("*")
+	if err != nil {
+		return nil, err
+	}
+	var impacted []TagInfo
+	for _, info := range infos {
+		if rewritten[info.Commit] {
+			impacted = append(impacted, info)
+		}
+	}
+	return impacted, nil
+}
@@ -0,0 +1,58 @@
+package gittag
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// pingTimeout 是检查远程仓库可达性的超时时间
+const pingTimeout = 10 * time.Second
+
+// PingRemote checks that remote can be reached with the current credentials by listing a single
+// ref with a short timeout, so an unreachable server or a missing token is reported in seconds
+// instead of after a local tag has already been created.
+// @param remote - 远程名称或地址，例如："origin"
+// @return error - 如果无法连接远程仓库，返回说明可能原因（网络或认证）的错误
+//
+// Example:
+//
+//	if err := gittag.PingRemote("origin"); err != nil {
+//		log.Fatal(err) // 无法连接远程仓库 origin（认证失败）: ...
+//	}
+func PingRemote(remote string) error {
+	return Default().PingRemote(remote)
+}
+
+// PingRemote checks that a remote of the repository is reachable, see the package-level PingRemote.
+func (r *Repository) PingRemote(remote string) error {
+	if err := r.pingRemote(r.baseContext(), remote); err != nil {
+		reason := "网络或地址错误？"
+		if Kind(err) == KindAuthFailed {
+			reason = "认证失败"
+		}
+		return fmt.Errorf("无法连接远程仓库 %s（%s）: %w", remote, reason, err)
+	}
+	return nil
+}
+
+// WithRemotePreflight 让 CreateTag 以及带 WithPush 的 Create 在创建本地标签前先调用 PingRemote，
+// 远程不可达时直接失败，不会留下未推送的本地标签
+func WithRemotePreflight() Option {
+	return func(r *Repository) {
+		r.remotePreflight = true
+	}
+}
+
+// pingRemote 在 pingTimeout 内通过 ls-remote 读取远程仓库的单个引用，确认远程可以访问
+func (r *Repository) pingRemote(ctx context.Context, remote string) error {
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+	if err := r.run(ctx, "ls-remote", remote, "HEAD"); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("%s 内没有响应: %w", pingTimeout, err)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package gittag
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// versionTagPattern 将标签拆分为系列前缀和末尾的语义化版本，例如 "api/v1.2.3" -> "api/v" + "1.2.3"
+var versionTagPattern = regexp.MustCompile(`^(.*?)(\d+\.\d+\.\d+(?:-[0-9A-Za-z.-]+)?(?:\+[0-9A-Za-z.-]+)?)$`)
+
+// splitVersionTag 返回标签的系列前缀和版本，不以语义化版本结尾时返回 false
+func splitVersionTag(tag string) (string, semver, bool) {
+	m := versionTagPattern.FindStringSubmatch(tag)
+	if m == nil {
+		return "", semver{}, false
+	}
+	v, ok := parseSemver(m[2])
+	return m[1], v, ok
+}
+
+type previousConfig struct {
+	prereleases bool
+	channel     string
+}
+
+// PreviousOption 用于配置 PreviousTag 的行为
+type PreviousOption func(*previousConfig)
+
+// WithPrereleases 将所有预发布版本也视为候选，默认只考虑正式版本
+func WithPrereleases() PreviousOption {
+	return func(c *previousConfig) {
+		c.prereleases = true
+	}
+}
+
+// WithChannel 除正式版本外，还考虑指定渠道的预发布版本，例如 "beta" 匹配 v1.2.0-beta.3
+func WithChannel(channel string) PreviousOption {
+	return func(c *previousConfig) {
+		c.channel = channel
+	}
+}
+
+// PreviousTag returns the release immediately preceding tag in the same series.
+// A series is the part of the name before the version, so the previous tag of "api/v1.3.0" is
+// the highest "api/v*" release lower than 1.3.0. Only stable releases are considered unless
+// WithPrereleases or WithChannel is given.
+// @param tag - 参照标签，例如："v1.3.0"，不要求该标签已存在
+// @param opts - 可选配置，例如 WithPrereleases、WithChannel
+// @return (string, error) - 返回上一个版本，如果不存在则返回 *NotFoundError
+//
+// Example:
+//
+//	// Build a compare URL for the release notes
+//	prev, err := gittag.PreviousTag("v1.3.0")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	fmt.Printf("https://github.com/org/repo/compare/%s...v1.3.0\n", prev)
+func PreviousTag(tag string, opts ...PreviousOption) (string, error) {
+	return Default().PreviousTag(tag, opts...)
+}
+
+// PreviousTag returns the preceding release in the repository, see the package-level PreviousTag.
+func (r *Repository) PreviousTag(tag string, opts ...PreviousOption) (string, error) {
+	cfg := &previousConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	series, current, ok := splitVersionTag(tag)
+	if !ok {
+		return "", fmt.Errorf("标签 %q 不是语义化版本", tag)
+	}
+
+	candidates, err := r.FindMany(series + "*")
+	if err != nil {
+		return "", err
+	}
+	best, bestVersion := "", semver{}
+	for _, candidate := range candidates {
+		prefix, v, ok := splitVersionTag(candidate)
+		if !ok || prefix != series || compareSemver(v, current) >= 0 || !cfg.accepts(v) {
+			continue
+		}
+		if best == "" || compareSemver(v, bestVersion) > 0 {
+			best, bestVersion = candidate, v
+		}
+	}
+	if best == "" {
+		return "", &NotFoundError{Pattern: series + "*"}
+	}
+	return best, nil
+}
+
+// accepts 判断版本是否满足预发布过滤条件
+func (c *previousConfig) accepts(v semver) bool {
+	switch {
+	case len(v.prerelease) == 0 || c.prereleases:
+		return true
+	case c.channel != "":
+		return v.prerelease[0] == c.channel
+	}
+	return false
+}
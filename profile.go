@@ -0,0 +1,196 @@
+package gittag
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Profile 将一组仓库配置（远程地址、凭据、签名、策略、通知）以名称保存，用于 OpenWithProfile
+// 适合平台团队用同一个服务管理多个规则不同的仓库
+type Profile struct {
+	Name string `json:"name"`
+	// RemoteURL 覆盖远程仓库（默认 origin，见 WithRemote）的地址，不修改仓库的 git 配置
+	RemoteURL string `json:"remoteURL,omitempty"`
+	// Env 是执行 git 命令时追加的环境变量，通常用于凭据，例如 "GIT_SSH_COMMAND=ssh -i /keys/prod"
+	Env []string `json:"env,omitempty"`
+	// GitConfig 是追加的 -c 配置，例如 {"credential.helper": "store"}
+	GitConfig map[string]string `json:"gitConfig,omitempty"`
+	// SigningKey、SigningFormat 对应 user.signingkey 和 gpg.format
+	SigningKey    string `json:"signingKey,omitempty"`
+	SigningFormat string `json:"signingFormat,omitempty"`
+	// Sign 为 true 时创建的附注标签都会签名（tag.gpgSign）
+	Sign bool `json:"sign,omitempty"`
+	// MessageTemplate 见 WithMessageTemplate
+	MessageTemplate string        `json:"messageTemplate,omitempty"`
+	LockTimeout     time.Duration `json:"lockTimeout,omitempty"`
+	RetryAttempts   int           `json:"retryAttempts,omitempty"`
+	// Notify 是发布通知的目标（例如 webhook 地址），本包只负责保存，由 WithNotify 的实现读取
+	Notify []string `json:"notify,omitempty"`
+	// Options 是额外的仓库配置，只能通过 RegisterProfile 设置
+	Options []Option `json:"-"`
+}
+
+var (
+	profilesMu sync.RWMutex
+	profiles   = make(map[string]Profile)
+)
+
+// RegisterProfile 注册一个进程内的配置，同名配置会被覆盖，优先于 git 配置中的 [gittag-profile "<name>"]
+func RegisterProfile(p Profile) {
+	profilesMu.Lock()
+	defer profilesMu.Unlock()
+	profiles[p.Name] = p
+}
+
+// OpenWithProfile opens the repository at path with the settings of a named profile. Profiles
+// registered with RegisterProfile take precedence; otherwise the profile is read from git config
+// (repository, global or system), e.g.
+//
+//	[gittag-profile "prod"]
+//		remoteurl = git@github.com:acme/app.git
+//		env = GIT_SSH_COMMAND=ssh -i /keys/prod
+//		sign = true
+//		signingformat = ssh
+//		signingkey = /keys/prod.pub
+//		notify = https://hooks.slack.com/services/...
+//
+// @param path - 仓库路径
+// @param name - 配置名称，例如："prod"
+// @param opts - 额外配置，在配置之后应用，可以覆盖配置中的设置
+// @return (*Repository, error) - 返回仓库实例，如果配置不存在或无效则返回错误
+//
+// Example:
+//
+//	repo, err := gittag.OpenWithProfile("/srv/repos/acme", "prod")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	err = repo.CreateTag("v1.2.0")
+func OpenWithProfile(path, name string, opts ...Option) (*Repository, error) {
+	repo, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+	profilesMu.RLock()
+	profile, ok := profiles[name]
+	profilesMu.RUnlock()
+	if !ok {
+		if profile, err = repo.configProfile(name); err != nil {
+			return nil, err
+		}
+	}
+	return Open(path, append(repo.profileOptions(profile), opts...)...)
+}
+
+// Profile 返回打开仓库时使用的配置，未使用 OpenWithProfile 时返回 nil
+func (r *Repository) Profile() *Profile {
+	return r.profile
+}
+
+// configProfile 从 git 配置的 [gittag-profile "<name>"] 中读取配置
+func (r *Repository) configProfile(name string) (Profile, error) {
+	prefix := "gittag-profile." + name + "."
+	output, err := r.output(r.baseContext(), "config", "--get-regexp", "^"+regexp.QuoteMeta(prefix))
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return Profile{}, fmt.Errorf("配置 %q 不存在，请使用 RegisterProfile 注册或在 git 配置中添加 [gittag-profile %q]", name, name)
+	}
+	if err != nil {
+		return Profile{}, fmt.Errorf("读取配置 %q 失败: %w", name, err)
+	}
+
+	p := Profile{Name: name}
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		key, value, _ := strings.Cut(strings.TrimPrefix(line, prefix), " ")
+		switch key {
+		case "remoteurl":
+			p.RemoteURL = value
+		case "env":
+			p.Env = append(p.Env, value)
+		case "config":
+			k, v, ok := strings.Cut(value, "=")
+			if !ok {
+				return Profile{}, fmt.Errorf("配置 %q 的 config 项 %q 应为 key=value", name, value)
+			}
+			if p.GitConfig == nil {
+				p.GitConfig = make(map[string]string)
+			}
+			p.GitConfig[k] = v
+		case "signingkey":
+			p.SigningKey = value
+		case "signingformat":
+			p.SigningFormat = value
+		case "sign":
+			p.Sign = value == "true" || value == "yes" || value == "on" || value == "1"
+		case "messagetemplate":
+			p.MessageTemplate = value
+		case "locktimeout":
+			if p.LockTimeout, err = time.ParseDuration(value); err != nil {
+				return Profile{}, fmt.Errorf("配置 %q 的 locktimeout 无效: %w", name, err)
+			}
+		case "retryattempts":
+			if p.RetryAttempts, err = strconv.Atoi(value); err != nil {
+				return Profile{}, fmt.Errorf("配置 %q 的 retryattempts 无效: %w", name, err)
+			}
+		case "notify":
+			p.Notify = append(p.Notify, value)
+		default:
+			return Profile{}, fmt.Errorf("配置 %q 包含未知的设置 %q", name, key)
+		}
+	}
+	return p, nil
+}
+
+// profileOptions 将配置转换为仓库配置
+func (r *Repository) profileOptions(p Profile) []Option {
+	opts := []Option{func(repo *Repository) {
+		profile := p
+		repo.profile = &profile
+	}}
+	if p.RemoteURL != "" {
+		// 直接追加 remote.<name>.url 会让远程有多个地址，推送时会推送到所有地址，因此改写已有地址
+		key := "remote." + r.remoteName() + ".url"
+		if current := r.configValue(r.baseContext(), key); current != "" && current != p.RemoteURL {
+			opts = append(opts, WithURLRewrite(current, p.RemoteURL))
+		} else if current == "" {
+			opts = append(opts, WithGitConfig(key, p.RemoteURL))
+		}
+	}
+	if len(p.Env) > 0 {
+		opts = append(opts, WithEnv(p.Env...))
+	}
+	keys := make([]string, 0, len(p.GitConfig))
+	for k := range p.GitConfig {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		opts = append(opts, WithGitConfig(k, p.GitConfig[k]))
+	}
+	if p.SigningKey != "" {
+		opts = append(opts, WithGitConfig("user.signingkey", p.SigningKey))
+	}
+	if p.SigningFormat != "" {
+		opts = append(opts, WithGitConfig("gpg.format", p.SigningFormat))
+	}
+	if p.Sign {
+		opts = append(opts, WithGitConfig("tag.gpgSign", "true"))
+	}
+	if p.MessageTemplate != "" {
+		opts = append(opts, WithMessageTemplate(p.MessageTemplate))
+	}
+	if p.LockTimeout > 0 {
+		opts = append(opts, WithLockTimeout(p.LockTimeout))
+	}
+	if p.RetryAttempts > 0 {
+		opts = append(opts, WithRetry(p.RetryAttempts, time.Second))
+	}
+	return append(opts, p.Options...)
+}
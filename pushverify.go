@@ -0,0 +1,70 @@
+package gittag
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// pushVerifyMaxInterval 是推送后重新查询远程的最大间隔
+const pushVerifyMaxInterval = 2 * time.Second
+
+// WithPushVerification 在每次推送标签后通过 ls-remote 确认远程标签指向预期的对象
+// 可以发现服务器端钩子异步改写标签或副本同步延迟等问题，在 timeout 内间隔递增地重试，仍不一致时返回 ErrPushUnverified
+func WithPushVerification(timeout time.Duration) Option {
+	return func(r *Repository) {
+		r.pushVerifyTimeout = timeout
+	}
+}
+
+// verifyPush 确认远程标签与 expected 一致，expected 为实际标签名称到对象 SHA 的映射
+// 未启用 WithPushVerification 时直接返回
+func (r *Repository) verifyPush(ctx context.Context, expected map[string]string) error {
+	if r.pushVerifyTimeout <= 0 || len(expected) == 0 || r.dryRun != nil {
+		return nil
+	}
+	refs := make([]string, 0, len(expected))
+	for name := range expected {
+		refs = append(refs, tagRef(name))
+	}
+	sort.Strings(refs)
+
+	deadline := time.Now().Add(r.pushVerifyTimeout)
+	interval := 100 * time.Millisecond
+	for {
+		var mismatches []string
+		for start := 0; start < len(refs); start += pushBatchSize {
+			batch := refs[start:min(start+pushBatchSize, len(refs))]
+			remote, err := r.remoteTags(ctx, batch...)
+			if err != nil {
+				return err
+			}
+			for _, ref := range batch {
+				name := strings.TrimPrefix(ref, "refs/tags/")
+				if got := remote[name]; got != expected[name] {
+					if got == "" {
+						got = "不存在"
+					}
+					mismatches = append(mismatches, fmt.Sprintf("%s: 远程为 %s，预期 %s", r.displayName(name), got, expected[name]))
+				}
+			}
+		}
+		if len(mismatches) == 0 {
+			return nil
+		}
+		if time.Now().Add(interval).After(deadline) {
+			return fmt.Errorf("%w: %s", ErrPushUnverified, strings.Join(mismatches, "; "))
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		interval = min(interval*2, pushVerifyMaxInterval)
+	}
+}
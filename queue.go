@@ -0,0 +1,243 @@
+package gittag
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// queueHistory 是 Queue 保留的已结束操作数量上限，超过后最早结束的操作不能再通过 Status 查询
+const queueHistory = 1000
+
+// Operation 是 Queue 执行的操作，repo 已通过 WithContext 绑定到 ctx，ctx 在 Queue 停止时取消
+type Operation func(ctx context.Context, repo *Repository) error
+
+// OperationState 是排队操作的状态
+type OperationState string
+
+const (
+	OperationQueued    OperationState = "queued"
+	OperationRunning   OperationState = "running"
+	OperationSucceeded OperationState = "succeeded"
+	OperationFailed    OperationState = "failed"
+)
+
+// OperationStatus 是排队操作的当前状态
+type OperationStatus struct {
+	ID string `json:"id"`
+	// Repository 是操作所在仓库的目录
+	Repository string         `json:"repository"`
+	State      OperationState `json:"state"`
+	Enqueued   time.Time      `json:"enqueued"`
+	Started    time.Time      `json:"started,omitempty"`
+	Finished   time.Time      `json:"finished,omitempty"`
+	// Error 是失败原因，已脱敏
+	Error string `json:"error,omitempty"`
+}
+
+type queuedOperation struct {
+	op     Operation
+	repo   *Repository
+	status OperationStatus
+	err    error
+	done   chan struct{}
+}
+
+// Queue runs operations asynchronously on a pool of workers, so web handlers and bots can start
+// long bulk operations and report progress instead of blocking. Operations on the same
+// repository run one at a time in the order they were enqueued; operations on different
+// repositories run in parallel up to the number of workers.
+//
+// Example:
+//
+//	q := gittag.NewQueue(4)
+//	go q.Run(ctx)
+//
+//	id := q.Enqueue(repo, func(ctx context.Context, repo *gittag.Repository) error {
+//		return repo.DeleteRemoteAll("nightly-*")
+//	})
+//	fmt.Println(q.Status(id)) // {op-1 /srv/app running ...}
+//	if err := q.Wait(ctx, id); err != nil {
+//		log.Print(err)
+//	}
+type Queue struct {
+	workers int
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	seq     int
+	ops     map[string]*queuedOperation
+	lanes   map[string][]*queuedOperation
+	busy    map[string]bool
+	ready   []string
+	history []string
+	running bool
+}
+
+// NewQueue 创建一个使用 workers 个工作 goroutine 的 Queue，workers 小于 1 时视为 1
+func NewQueue(workers int) *Queue {
+	q := &Queue{
+		workers: max(workers, 1),
+		ops:     make(map[string]*queuedOperation),
+		lanes:   make(map[string][]*queuedOperation),
+		busy:    make(map[string]bool),
+	}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// Enqueue 将 op 加入 repo 的队列并返回操作 id，repo 为 nil 时使用 Default()
+// 可以在 Run 之前调用，操作在 Run 开始后执行
+func (q *Queue) Enqueue(repo *Repository, op Operation) string {
+	if repo == nil {
+		repo = Default()
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.seq++
+	o := &queuedOperation{
+		op:   op,
+		repo: repo,
+		status: OperationStatus{
+			ID:         "op-" + strconv.Itoa(q.seq),
+			Repository: repo.Dir(),
+			State:      OperationQueued,
+			Enqueued:   time.Now(),
+		},
+		done: make(chan struct{}),
+	}
+	q.ops[o.status.ID] = o
+	key := repo.Dir()
+	q.lanes[key] = append(q.lanes[key], o)
+	if !q.busy[key] && len(q.lanes[key]) == 1 {
+		q.ready = append(q.ready, key)
+		q.cond.Signal()
+	}
+	return o.status.ID
+}
+
+// Status 返回操作的当前状态，id 不存在（或已超出保留的历史）时返回 false
+func (q *Queue) Status(id string) (OperationStatus, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	o, ok := q.ops[id]
+	if !ok {
+		return OperationStatus{}, false
+	}
+	return o.status, true
+}
+
+// Wait 等待操作结束并返回它的错误，ctx 先结束时返回 ctx 的错误
+func (q *Queue) Wait(ctx context.Context, id string) error {
+	q.mu.Lock()
+	o, ok := q.ops[id]
+	q.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("操作 %s 不存在", id)
+	}
+	select {
+	case <-o.done:
+		return o.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Run 启动工作 goroutine 执行排队的操作，直到 ctx 结束，并等待正在执行的操作返回
+// 尚未开始的操作保持排队状态，可以再次调用 Run 继续执行
+func (q *Queue) Run(ctx context.Context) error {
+	q.mu.Lock()
+	if q.running {
+		q.mu.Unlock()
+		return fmt.Errorf("队列已在运行")
+	}
+	q.running = true
+	q.mu.Unlock()
+
+	stop := context.AfterFunc(ctx, func() {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		q.cond.Broadcast()
+	})
+	defer stop()
+
+	var wg sync.WaitGroup
+	for i := 0; i < q.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q.work(ctx)
+		}()
+	}
+	wg.Wait()
+
+	q.mu.Lock()
+	q.running = false
+	q.mu.Unlock()
+	return ctx.Err()
+}
+
+// work 依次取出就绪仓库队列中的第一个操作执行，直到 ctx 结束
+func (q *Queue) work(ctx context.Context) {
+	for {
+		q.mu.Lock()
+		for len(q.ready) == 0 && ctx.Err() == nil {
+			q.cond.Wait()
+		}
+		if ctx.Err() != nil {
+			q.mu.Unlock()
+			return
+		}
+		key := q.ready[0]
+		q.ready = q.ready[1:]
+		o := q.lanes[key][0]
+		q.lanes[key] = q.lanes[key][1:]
+		q.busy[key] = true
+		o.status.State = OperationRunning
+		o.status.Started = time.Now()
+		q.mu.Unlock()
+
+		err := o.run(ctx)
+
+		q.mu.Lock()
+		o.err = err
+		o.status.Finished = time.Now()
+		o.status.State = OperationSucceeded
+		if err != nil {
+			o.status.State = OperationFailed
+			o.status.Error = Redact(err.Error())
+		}
+		close(o.done)
+		q.forget(o.status.ID)
+		q.busy[key] = false
+		if len(q.lanes[key]) > 0 {
+			q.ready = append(q.ready, key)
+			q.cond.Signal()
+		} else {
+			delete(q.lanes, key)
+			delete(q.busy, key)
+		}
+		q.mu.Unlock()
+	}
+}
+
+// forget 记录已结束的操作，超过 queueHistory 时丢弃最早结束的操作，调用方需持有 q.mu
+func (q *Queue) forget(id string) {
+	q.history = append(q.history, id)
+	if len(q.history) > queueHistory {
+		delete(q.ops, q.history[0])
+		q.history = q.history[1:]
+	}
+}
+
+// run 执行操作，操作中的 panic 会被记录为失败
+func (o *queuedOperation) run(ctx context.Context) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("操作 panic: %v", p)
+		}
+	}()
+	return o.op(ctx, o.repo.WithContext(ctx))
+}
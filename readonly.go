@@ -0,0 +1,79 @@
+package gittag
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WithReadOnly 禁止通过该仓库修改任何内容，所有修改操作（包括 Git 执行的写命令）都返回 ErrReadOnly
+// 适用于审计、报表等只需要读取标签的服务
+func WithReadOnly() Option {
+	return func(r *Repository) {
+		r.readOnly = true
+	}
+}
+
+// readOnlyCommands 是不会修改仓库的 git 子命令
+var readOnlyCommands = map[string]bool{
+	"rev-parse": true, "rev-list": true, "log": true, "show": true, "cat-file": true,
+	"for-each-ref": true, "show-ref": true, "ls-remote": true, "ls-tree": true, "ls-files": true,
+	"describe": true, "name-rev": true, "merge-base": true, "diff": true, "status": true,
+	"var": true, "verify-tag": true, "verify-commit": true, "check-ref-format": true,
+	"grep": true, "blame": true, "shortlog": true, "count-objects": true,
+}
+
+// checkWritable 在只读模式下返回 ErrReadOnly
+func (r *Repository) checkWritable(operation string) error {
+	if r.readOnly {
+		return fmt.Errorf("%w: 不允许%s", ErrReadOnly, operation)
+	}
+	return nil
+}
+
+// checkCommand 在只读模式下拒绝可能修改仓库的 git 命令，所有 git 命令执行前都会经过这里
+func (r *Repository) checkCommand(args []string) error {
+	if !r.readOnly || len(args) == 0 || isReadOnlyCommand(args) {
+		return nil
+	}
+	return fmt.Errorf("%w: 不允许执行 git %s", ErrReadOnly, args[0])
+}
+
+// isReadOnlyCommand 判断 git 命令是否只读，无法确定时视为写命令
+func isReadOnlyCommand(args []string) bool {
+	sub, rest := args[0], args[1:]
+	if readOnlyCommands[sub] {
+		return true
+	}
+	has := func(flags ...string) bool {
+		for _, arg := range rest {
+			if arg == "--" {
+				return false
+			}
+			for _, flag := range flags {
+				if arg == flag || strings.HasPrefix(arg, flag+"=") {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	var operands []string
+	for _, arg := range rest {
+		if !strings.HasPrefix(arg, "-") {
+			operands = append(operands, arg)
+		}
+	}
+
+	switch sub {
+	case "config":
+		return has("--get", "--get-all", "--get-regexp", "--list", "-l")
+	case "tag":
+		return has("-l", "--list", "-v", "--verify") &&
+			!has("-d", "--delete", "-f", "--force", "-a", "--annotate", "-s", "--sign", "-u", "--local-user", "-m", "--message", "-F", "--file")
+	case "symbolic-ref":
+		return len(operands) <= 1 && !has("-d", "--delete")
+	case "notes":
+		return len(operands) > 0 && (operands[0] == "show" || operands[0] == "list")
+	}
+	return false
+}
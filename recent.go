@@ -0,0 +1,55 @@
+package gittag
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Recent returns the n most recently created tags matching pattern, newest first, in a single git call.
+// @param n - 返回的最大数量
+// @param pattern - 标签匹配模式，语法同 FindMany（* 可以匹配 "/"），例如："v*"，为空或 "*" 时匹配所有标签
+// @return ([]TagInfo, error) - 返回按创建时间倒序排列的标签信息
+//
+// Example:
+//
+//	// Show the last five releases on a dashboard
+//	infos, err := gittag.Recent(5, "v*")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	for _, info := range infos {
+//		fmt.Printf("%s\t%s\n", info.Date.Format("2006-01-02"), info.Name)
+//	}
+func Recent(n int, pattern string) ([]TagInfo, error) {
+	return Default().Recent(n, pattern)
+}
+
+// Recent returns the n newest tags in the repository, see the package-level Recent.
+func (r *Repository) Recent(n int, pattern string) ([]TagInfo, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	if pattern == "" {
+		pattern = "*"
+	}
+	normalized, err := normalizePattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	// 与 FindMany 一样使用 git tag -l 的通配符语义，而不是 for-each-ref 的前缀匹配
+	output, err := r.output(r.baseContext(), "tag", "-l",
+		"--sort=-creatordate", "--format="+tagInfoFormat, "--", r.tagName(normalized))
+	if err != nil {
+		return nil, fmt.Errorf("读取最近的标签失败: %w", err)
+	}
+	records := strings.SplitAfterN(string(output), "\x1e", n+1)
+	if len(records) > n {
+		records = records[:n]
+	}
+	infos, err := r.parseTagInfo(strings.Join(records, ""))
+	for i := range infos {
+		infos[i].Name = r.displayName(infos[i].Name)
+	}
+	return infos, err
+}
@@ -0,0 +1,367 @@
+package gittag
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ReleaseStep 是发布流程中已完成的最后一步
+type ReleaseStep string
+
+const (
+	// ReleasePending 表示发布尚未开始
+	ReleasePending ReleaseStep = ""
+	// ReleaseValidated 表示目标提交已确定，校验已通过
+	ReleaseValidated ReleaseStep = "validated"
+	// ReleaseVersioned 表示 WithVersionFiles 指定的版本文件已同步并提交，未指定时直接跳过
+	ReleaseVersioned ReleaseStep = "versioned"
+	// ReleaseTagged 表示本地标签已创建
+	ReleaseTagged ReleaseStep = "tagged"
+	// ReleasePushed 表示标签已推送到远程仓库
+	ReleasePushed ReleaseStep = "pushed"
+	// ReleasePublished 表示 WithPublish 指定的发布操作已完成
+	ReleasePublished ReleaseStep = "published"
+	// ReleaseNotified 表示 WithNotify 指定的通知已发送，发布完成
+	ReleaseNotified ReleaseStep = "notified"
+)
+
+// releaseSteps 是发布流程的步骤顺序
+var releaseSteps = []ReleaseStep{ReleaseValidated, ReleaseVersioned, ReleaseTagged, ReleasePushed, ReleasePublished, ReleaseNotified}
+
+// ReleaseState 是持久化在 .git/gittag/releases/<id>.json（或 WithStore 指定的 Store）中的发布进度
+type ReleaseState struct {
+	ID  string `json:"id"`
+	Tag string `json:"tag"`
+	// Commit 在校验步骤中确定，恢复时始终标记同一个提交
+	Commit  string      `json:"commit,omitempty"`
+	Message string      `json:"message"`
+	Step    ReleaseStep `json:"step"`
+	// Actor 是发起发布的操作者，见 WithActor 和 ContextWithActor
+	Actor *Actor `json:"actor,omitempty"`
+	// Error 是最近一次失败的错误信息，成功完成一步后清空
+	Error     string    `json:"error,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Done 报告发布的所有步骤是否都已完成
+func (s *ReleaseState) Done() bool {
+	return s.Step == ReleaseNotified
+}
+
+// ReleaseHook 是发布流程中由调用方提供的步骤，例如创建 GitHub Release 或发送通知
+type ReleaseHook func(ctx context.Context, state ReleaseState) error
+
+type releaseConfig struct {
+	createOpts   []CreateOption
+	versionFiles []VersionFile
+	validate     ReleaseHook
+	publish      ReleaseHook
+	notify       ReleaseHook
+}
+
+// ReleaseOption 用于配置 Release 和 ResumeRelease
+type ReleaseOption func(*releaseConfig)
+
+// WithCreateOptions 指定创建标签时使用的选项，例如 WithMessage、WithTarget
+// 只在首次运行时生效，恢复时使用已保存的提交和信息
+func WithCreateOptions(opts ...CreateOption) ReleaseOption {
+	return func(c *releaseConfig) {
+		c.createOpts = append(c.createOpts, opts...)
+	}
+}
+
+// WithVersionFiles 在打标签前将版本文件同步为新版本并提交，标签会创建在该提交上
+// 不能与 WithTarget 同时使用，见 SyncVersionFiles
+func WithVersionFiles(files ...VersionFile) ReleaseOption {
+	return func(c *releaseConfig) {
+		c.versionFiles = append(c.versionFiles, files...)
+	}
+}
+
+// WithValidate 指定打标签前的额外校验，例如检查 CI 状态
+func WithValidate(hook ReleaseHook) ReleaseOption {
+	return func(c *releaseConfig) {
+		c.validate = hook
+	}
+}
+
+// WithPublish 指定标签推送后的发布操作，例如创建 GitHub Release
+func WithPublish(hook ReleaseHook) ReleaseOption {
+	return func(c *releaseConfig) {
+		c.publish = hook
+	}
+}
+
+// WithNotify 指定发布完成后的通知操作，例如发送聊天消息
+func WithNotify(hook ReleaseHook) ReleaseOption {
+	return func(c *releaseConfig) {
+		c.notify = hook
+	}
+}
+
+// Release runs the release pipeline validated → versioned → tagged → pushed → published → notified and
+// records the progress under id after every step. If the run crashes or ctx is cancelled, call
+// ResumeRelease with the same id to continue from the last completed step without re-tagging.
+// @param ctx - 用于取消发布的上下文，会传给各个 ReleaseHook
+// @param id - 发布的唯一标识，例如 CI 的运行 id
+// @param tagName - 标签名称，例如："v1.2.0"
+// @param opts - 可选配置，例如 WithCreateOptions、WithPublish、WithNotify
+// @return (*ReleaseState, error) - 返回发布进度，失败时进度停留在最后完成的步骤
+//
+// Example:
+//
+//	publish := gittag.WithPublish(func(ctx context.Context, s gittag.ReleaseState) error {
+//		return createGitHubRelease(ctx, s.Tag, s.Message)
+//	})
+//	state, err := gittag.Release(ctx, runID, "v1.2.0", publish)
+//	if err != nil {
+//		log.Printf("release stopped after %q: %v", state.Step, err)
+//		// later, in a retry job:
+//		state, err = gittag.ResumeRelease(ctx, runID, publish)
+//	}
+func Release(ctx context.Context, id, tagName string, opts ...ReleaseOption) (*ReleaseState, error) {
+	return Default().Release(ctx, id, tagName, opts...)
+}
+
+// Release runs a resumable release in the repository, see the package-level Release.
+func (r *Repository) Release(ctx context.Context, id, tagName string, opts ...ReleaseOption) (*ReleaseState, error) {
+	if err := r.checkWritable("发布"); err != nil {
+		return nil, err
+	}
+	// 幂等键只应用于整个发布，各个步骤在清除了幂等键的副本上执行
+	return once(ctx, r, "Release", func(r *Repository) (*ReleaseState, error) {
+		store, key, err := r.releaseKey(id)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := store.Get(ctx, key); err == nil {
+			return nil, fmt.Errorf("发布 %s 已存在，请使用 ResumeRelease 继续", id)
+		}
+		state := &ReleaseState{ID: id, Tag: tagName, Actor: r.actorFor(ctx)}
+		return state, r.runRelease(ctx, state, opts)
+	})
+}
+
+// ResumeRelease 从最后完成的步骤继续 id 对应的发布，opts 应与 Release 时相同，已完成的发布直接返回
+func ResumeRelease(ctx context.Context, id string, opts ...ReleaseOption) (*ReleaseState, error) {
+	return Default().ResumeRelease(ctx, id, opts...)
+}
+
+// ResumeRelease resumes a release in the repository, see the package-level ResumeRelease.
+func (r *Repository) ResumeRelease(ctx context.Context, id string, opts ...ReleaseOption) (*ReleaseState, error) {
+	if err := r.checkWritable("发布"); err != nil {
+		return nil, err
+	}
+	return once(ctx, r, "ResumeRelease", func(r *Repository) (*ReleaseState, error) {
+		state, err := r.loadRelease(id)
+		if err != nil {
+			return nil, err
+		}
+		return state, r.runRelease(ctx, state, opts)
+	})
+}
+
+// runRelease 依次执行尚未完成的步骤，每完成一步就保存进度
+func (r *Repository) runRelease(ctx context.Context, state *ReleaseState, opts []ReleaseOption) error {
+	cfg := &releaseConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	// 恢复的发布仍以发起者的身份创建标签
+	if state.Actor != nil {
+		ctx = ContextWithActor(ctx, *state.Actor)
+	}
+	// 各步骤的 git 命令使用 ctx，取消时停止正在进行的推送；进度仍通过 r 保存，取消后也能记录失败的步骤
+	steps := r.WithContext(ctx)
+	for _, step := range releaseSteps {
+		if stepIndex(state.Step) >= stepIndex(step) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := steps.runReleaseStep(ctx, state, step, cfg); err != nil {
+			state.Error = Redact(err.Error())
+			if saveErr := r.saveRelease(state); saveErr != nil {
+				return errors.Join(err, saveErr)
+			}
+			return fmt.Errorf("发布 %s 在 %s 步骤失败: %w", state.ID, step, err)
+		}
+		state.Step, state.Error = step, ""
+		if err := r.saveRelease(state); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Repository) runReleaseStep(ctx context.Context, state *ReleaseState, step ReleaseStep, cfg *releaseConfig) error {
+	switch step {
+	case ReleaseValidated:
+		commit, message, err := r.resolveRelease(ctx, state.Tag, cfg)
+		if err != nil {
+			return err
+		}
+		state.Commit, state.Message = commit, message
+		if err := r.checkRelease(state.Tag, commit, cfg); err != nil {
+			return err
+		}
+		if cfg.validate != nil {
+			return cfg.validate(ctx, *state)
+		}
+	case ReleaseVersioned:
+		if len(cfg.versionFiles) == 0 {
+			return nil
+		}
+		// 上次运行可能在提交后、保存进度前中断，此时文件已是最新，直接使用 HEAD
+		commit, err := r.SyncVersionFiles(state.Tag, cfg.versionFiles...)
+		if err != nil {
+			return err
+		}
+		state.Commit = commit
+	case ReleaseTagged:
+		// 上次运行可能在创建标签后、保存进度前中断
+		if existing, err := r.revParse(ctx, tagRef(r.tagName(state.Tag))+"^{commit}"); err == nil {
+			if existing != state.Commit {
+				return fmt.Errorf("标签 %s 已存在且指向 %s，而不是 %s", state.Tag, existing, state.Commit)
+			}
+			return nil
+		}
+		// 其他创建选项（签名、强制、replace 检查等）照常生效，信息和目标使用已保存的值
+		createCfg := newCreateConfig(cfg.createOpts...)
+		createCfg.message, createCfg.messageFile, createCfg.target = state.Message, "", state.Commit
+		if createCfg.lightweight {
+			createCfg.message = ""
+		}
+		createCfg, err := r.preTag(state.Tag, createCfg)
+		if err != nil {
+			return err
+		}
+		return r.locked(func() error {
+			return r.create(state.Tag, createCfg)
+		})
+	case ReleasePushed:
+		// dry-run 时上一步没有真正创建标签，直接输出推送命令
+		if r.dryRun == nil {
+			local, err := r.revParse(ctx, tagRef(r.tagName(state.Tag)))
+			if err != nil {
+				return fmt.Errorf("读取标签 %s 失败: %w", state.Tag, err)
+			}
+			remote, err := r.remoteTags(ctx)
+			if err != nil {
+				return err
+			}
+			if object, ok := remote[r.tagName(state.Tag)]; ok && object == local {
+				return nil
+			}
+		}
+		return r.locked(func() error {
+			return r.createRemote(state.Tag)
+		})
+	case ReleasePublished:
+		if cfg.publish != nil && !r.skipDryRunStep("publish", state.Tag) {
+			return cfg.publish(ctx, *state)
+		}
+	case ReleaseNotified:
+		if cfg.notify != nil && !r.skipDryRunStep("notify", state.Tag) {
+			return cfg.notify(ctx, *state)
+		}
+	}
+	return nil
+}
+
+// resolveRelease 确定发布要标记的提交和标签信息
+func (r *Repository) resolveRelease(ctx context.Context, tagName string, cfg *releaseConfig) (string, string, error) {
+	createCfg := newCreateConfig(cfg.createOpts...)
+	target := createCfg.target
+	if target != "" && len(cfg.versionFiles) > 0 {
+		return "", "", fmt.Errorf("WithVersionFiles 会在当前分支上提交，不能与 WithTarget 同时使用")
+	}
+	if target == "" {
+		if !createCfg.allowDetached {
+			if err := r.requireAttachedHead(); err != nil {
+				return "", "", err
+			}
+		}
+		target = "HEAD"
+	}
+	commit, err := r.revParse(ctx, target+"^{commit}")
+	if err != nil {
+		return "", "", fmt.Errorf("无法解析提交 %s: %w", target, err)
+	}
+	defaultMessage, err := r.defaultMessage(tagName)
+	if err != nil {
+		return "", "", err
+	}
+	message, err := createCfg.tagMessage(defaultMessage)
+	if err != nil {
+		return "", "", err
+	}
+	return commit, message, nil
+}
+
+// checkRelease 对要标记的提交执行 WithCreateOptions 中配置的创建前检查
+func (r *Repository) checkRelease(tagName, commit string, cfg *releaseConfig) error {
+	createCfg := newCreateConfig(cfg.createOpts...)
+	createCfg.target = commit
+	return r.checkCreate(tagName, createCfg)
+}
+
+func stepIndex(step ReleaseStep) int {
+	for i, s := range releaseSteps {
+		if s == step {
+			return i
+		}
+	}
+	return -1
+}
+
+// releaseKey 返回保存发布进度的 Store 和键
+func (r *Repository) releaseKey(id string) (Store, string, error) {
+	if reason := invalidRefReason(id); reason != "" {
+		return nil, "", fmt.Errorf("无效的发布 id %q: %s", id, reason)
+	}
+	store, err := r.stateStore()
+	if err != nil {
+		return nil, "", err
+	}
+	return store, "releases/" + id + ".json", nil
+}
+
+func (r *Repository) loadRelease(id string) (*ReleaseState, error) {
+	store, key, err := r.releaseKey(id)
+	if err != nil {
+		return nil, err
+	}
+	data, err := store.Get(r.baseContext(), key)
+	if err != nil {
+		return nil, fmt.Errorf("读取发布 %s 的进度失败: %w", id, err)
+	}
+	var state ReleaseState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("发布 %s 的进度文件已损坏: %w", id, err)
+	}
+	return &state, nil
+}
+
+func (r *Repository) saveRelease(state *ReleaseState) error {
+	if r.dryRun != nil {
+		return nil
+	}
+	store, key, err := r.releaseKey(state.ID)
+	if err != nil {
+		return err
+	}
+	state.UpdatedAt = time.Now()
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := store.Put(r.baseContext(), key, data); err != nil {
+		return fmt.Errorf("保存发布 %s 的进度失败: %w", state.ID, err)
+	}
+	return nil
+}
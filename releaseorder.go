@@ -0,0 +1,96 @@
+package gittag
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// ReleaseOrder computes the order in which the modules of a monorepo must be tagged: every module
+// appears in a later batch than the modules it requires, so dependents are only tagged after the
+// new versions of their dependencies exist on the module proxy. Modules in the same batch do not
+// depend on each other and can be released in parallel. Dependencies are read from the go.mod
+// files at HEAD; requires of modules outside the list are ignored.
+// @param modules - 模块目录，相对仓库根目录，例如："libs/money"，仓库根目录为 "."
+// @return ([][]string, error) - 返回按批次分组的模块目录，存在循环依赖时返回错误
+//
+// Example:
+//
+//	batches, err := gittag.ReleaseOrder([]string{"services/payments", "libs/money", "libs/log"})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	for _, batch := range batches {
+//		// [libs/log libs/money], then [services/payments]
+//		tagAndWaitForProxy(batch)
+//	}
+func ReleaseOrder(modules []string) ([][]string, error) {
+	return Default().ReleaseOrder(modules)
+}
+
+// ReleaseOrder orders the repository's modules for release, see the package-level ReleaseOrder.
+func (r *Repository) ReleaseOrder(modules []string) ([][]string, error) {
+	ctx := r.baseContext()
+	byPath := map[string]string{} // 模块路径 → 模块目录
+	mods := map[string]goModFile{}
+	for _, dir := range modules {
+		dir = path.Clean(strings.Trim(dir, "/"))
+		if _, ok := mods[dir]; ok {
+			continue
+		}
+		data, ok := r.fileAt(ctx, "HEAD", path.Join(dir, "go.mod"))
+		if !ok {
+			return nil, fmt.Errorf("模块 %s 在 HEAD 中没有 go.mod", dir)
+		}
+		mod := parseGoMod(data)
+		if mod.module == "" {
+			return nil, fmt.Errorf("模块 %s 的 go.mod 缺少 module 指令", dir)
+		}
+		if other, ok := byPath[mod.module]; ok {
+			return nil, fmt.Errorf("模块 %s 和 %s 的模块路径都是 %s", other, dir, mod.module)
+		}
+		byPath[mod.module] = dir
+		mods[dir] = mod
+	}
+
+	// pending 记录每个模块尚未发布的依赖数量
+	pending := map[string]int{}
+	dependents := map[string][]string{}
+	for dir, mod := range mods {
+		pending[dir] = 0
+		for required := range mod.requires {
+			if dep, ok := byPath[required]; ok && dep != dir {
+				pending[dir]++
+				dependents[dep] = append(dependents[dep], dir)
+			}
+		}
+	}
+
+	var batches [][]string
+	for len(pending) > 0 {
+		var batch []string
+		for dir, n := range pending {
+			if n == 0 {
+				batch = append(batch, dir)
+			}
+		}
+		if len(batch) == 0 {
+			var cycle []string
+			for dir := range pending {
+				cycle = append(cycle, dir)
+			}
+			sort.Strings(cycle)
+			return nil, fmt.Errorf("模块之间存在循环依赖: %s", strings.Join(cycle, ", "))
+		}
+		sort.Strings(batch)
+		for _, dir := range batch {
+			delete(pending, dir)
+			for _, dependent := range dependents[dir] {
+				pending[dependent]--
+			}
+		}
+		batches = append(batches, batch)
+	}
+	return batches, nil
+}
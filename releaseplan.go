@@ -0,0 +1,176 @@
+package gittag
+
+import (
+	"errors"
+	"strings"
+	"text/template"
+)
+
+// releasePlan 是 ReleasePlanMarkdown 模板使用的数据
+type releasePlan struct {
+	Tag      string
+	Commit   string
+	Message  string
+	Previous string
+	Commits  []changelogCommit
+	// RemoteName 是推送的远程名称，Remote 是它的地址（已脱敏）
+	RemoteName string
+	Remote     string
+	// LocalTag 是本地同名标签指向的提交，RemoteTag 是远程同名标签的对象 SHA，不存在时为空
+	LocalTag, RemoteTag string
+	Problems            []string
+	VersionFiles        []string
+	PreTagCommands      []string
+	Validate            bool
+	Publish             bool
+	Notify              bool
+}
+
+// releasePlanTemplate 渲染发布计划，changelogCommit 的字段未导出，通过 entry 函数渲染
+var releasePlanTemplate = template.Must(template.New("plan").Funcs(template.FuncMap{
+	"short": func(sha string) string {
+		if len(sha) > 12 {
+			return sha[:12]
+		}
+		return sha
+	},
+	"entry": func(c changelogCommit) string {
+		return c.subject + " (" + c.sha + ")"
+	},
+	"indent": func(s string) string {
+		lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+		for i, line := range lines {
+			if line != "" {
+				lines[i] = "    " + line
+			}
+		}
+		return strings.Join(lines, "\n")
+	},
+}).Parse(`## Release plan: {{.Tag}}
+
+| | |
+|---|---|
+| Tag | ` + "`{{.Tag}}`" + ` |
+| Commit | ` + "`{{short .Commit}}`" + ` |
+| Previous release | {{if .Previous}}` + "`{{.Previous}}`" + `{{else}}_none_{{end}} |
+| Remote | {{if .Remote}}` + "`{{.Remote}}`" + `{{else}}_none_{{end}} |
+{{- if .Problems}}
+
+### ⚠️ Blocking problems
+{{range .Problems}}
+- {{.}}
+{{- end}}
+{{- end}}
+
+### Steps
+
+1. Validate{{if .Validate}} (custom checks){{end}}
+{{- range .PreTagCommands}}
+1. Run ` + "`{{.}}`" + `
+{{- end}}
+{{- if .VersionFiles}}
+1. Update and commit version files:{{range .VersionFiles}} ` + "`{{.}}`" + `{{end}}
+1. Create tag ` + "`{{.Tag}}`" + ` on the version commit
+{{- else}}
+1. Create tag ` + "`{{.Tag}}`" + ` on ` + "`{{short .Commit}}`" + `{{if .LocalTag}} — already exists locally{{end}}
+{{- end}}
+1. Push tag to {{.RemoteName}}{{if .RemoteTag}} — already on remote{{end}}
+1. Publish release{{if not .Publish}} — skipped{{end}}
+1. Send notifications{{if not .Notify}} — skipped{{end}}
+
+### Tag message
+
+{{indent .Message}}
+
+### Changes since {{if .Previous}}{{.Previous}}{{else}}the first commit{{end}}
+{{if .Commits}}
+{{range .Commits}}- {{entry .}}
+{{end}}{{else}}
+_No changes._
+{{end}}`))
+
+// ReleasePlanMarkdown renders what Release would do for tagName — target commit, tag message,
+// changelog preview, existing tags that block the release and the hooks that would run — as
+// Markdown for posting to a pull request before the real run. Nothing is modified.
+// @param tagName - 计划发布的标签名称，例如："v1.2.0"
+// @param opts - 与 Release 相同的配置，例如 WithCreateOptions、WithPublish
+// @return (string, error) - 返回 Markdown 格式的发布计划
+//
+// Example:
+//
+//	plan, err := gittag.ReleasePlanMarkdown("v1.2.0", gittag.WithPublish(publish))
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	postPRComment(plan)
+func ReleasePlanMarkdown(tagName string, opts ...ReleaseOption) (string, error) {
+	return Default().ReleasePlanMarkdown(tagName, opts...)
+}
+
+// ReleasePlanMarkdown renders a release plan for the repository, see the package-level ReleasePlanMarkdown.
+func (r *Repository) ReleasePlanMarkdown(tagName string, opts ...ReleaseOption) (string, error) {
+	ctx := r.baseContext()
+	cfg := &releaseConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	commit, message, err := r.resolveRelease(ctx, tagName, cfg)
+	if err != nil {
+		return "", err
+	}
+	plan := releasePlan{
+		Tag: tagName, Commit: commit, Message: message, RemoteName: r.remoteName(),
+		Validate: cfg.validate != nil, Publish: cfg.publish != nil, Notify: cfg.notify != nil,
+	}
+	plan.PreTagCommands = newCreateConfig(cfg.createOpts...).preTagCommands
+	for _, f := range cfg.versionFiles {
+		plan.VersionFiles = append(plan.VersionFiles, f.Path)
+	}
+
+	if err := r.checkFrozen(tagName); err != nil {
+		plan.Problems = append(plan.Problems, err.Error())
+	}
+	if err := r.checkNaming(tagName); err != nil {
+		plan.Problems = append(plan.Problems, err.Error())
+	}
+	// 只做不修改仓库的检查，创建标签前的命令只列出不执行
+	if createCfg := newCreateConfig(cfg.createOpts...); createCfg.replaceCheck {
+		if err := r.checkReplaces(ctx, tagName, commit, createCfg.replaceAllow); err != nil {
+			plan.Problems = append(plan.Problems, err.Error())
+		}
+	}
+	previous, err := r.PreviousTag(tagName)
+	var notFound *NotFoundError
+	if err != nil && !errors.As(err, &notFound) {
+		return "", err
+	}
+	plan.Previous = previous
+	if plan.Commits, err = r.changelogCommits(previous, commit); err != nil {
+		return "", err
+	}
+
+	if existing, err := r.revParse(ctx, tagRef(r.tagName(tagName))+"^{commit}"); err == nil {
+		plan.LocalTag = existing
+		if existing != commit {
+			plan.Problems = append(plan.Problems, "本地标签 "+tagName+" 已存在且指向 "+existing)
+		}
+	}
+	if url := r.configValue(ctx, "remote."+plan.RemoteName+".url"); url != "" {
+		plan.Remote = Redact(url)
+		remote, err := r.remoteTags(ctx)
+		if err != nil {
+			plan.Problems = append(plan.Problems, "无法读取远程标签: "+err.Error())
+		} else if object, ok := remote[r.tagName(tagName)]; ok {
+			plan.RemoteTag = object
+			if plan.LocalTag == "" {
+				plan.Problems = append(plan.Problems, "远程标签 "+tagName+" 已存在")
+			}
+		}
+	}
+
+	var b strings.Builder
+	if err := releasePlanTemplate.Execute(&b, plan); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
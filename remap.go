@@ -0,0 +1,187 @@
+package gittag
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+type remapConfig struct {
+	resign bool
+	key    string
+	push   bool
+	// keepTagger 为 true 时保留原标签的打标签的人和时间，RemapTags 使用
+	keepTagger bool
+}
+
+// RemapOption 用于配置 RemapTags 的行为
+type RemapOption func(*remapConfig)
+
+// WithResign 用当前配置的签名密钥重新签名被重建的附注标签，否则新标签不带签名
+func WithResign() RemapOption {
+	return func(c *remapConfig) {
+		c.resign = true
+	}
+}
+
+// WithSigningKey 使用 key 签名重建的标签，而不是 user.signingkey，同时意味着 WithResign
+// key 的格式取决于 gpg.format，例如 OpenPGP 的密钥 ID 或 SSH 私钥路径
+func WithSigningKey(key string) RemapOption {
+	return func(c *remapConfig) {
+		c.resign = true
+		c.key = key
+	}
+}
+
+// WithoutPush 只重建本地标签，不强制推送到远程
+func WithoutPush() RemapOption {
+	return func(c *remapConfig) {
+		c.push = false
+	}
+}
+
+// RemapTags recreates every tag that points at a rewritten commit on its replacement, then
+// force-pushes the moved tags — the missing last step of large history rewrites. The mapping is
+// old commit SHA → new commit SHA, e.g. parsed from git-filter-repo's commit-map. Annotated tags
+// keep their message, tagger and date; signatures cannot survive a rewrite, so pass WithResign to
+// sign the new tag objects. Tags that point at other tags are left untouched.
+// @param mapping - 旧提交 SHA 到新提交 SHA 的映射，均为完整 SHA
+// @param opts - 可选配置，例如 WithResign、WithoutPush
+// @return ([]string, error) - 返回被重建的标签名称
+//
+// Example:
+//
+//	mapping, err := readCommitMap(".git/filter-repo/commit-map")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	moved, err := gittag.RemapTags(mapping, gittag.WithResign())
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	log.Printf("remapped %d tags", len(moved))
+func RemapTags(mapping map[string]string, opts ...RemapOption) ([]string, error) {
+	return Default().RemapTags(mapping, opts...)
+}
+
+// RemapTags moves the repository's tags onto rewritten commits, see the package-level RemapTags.
+func (r *Repository) RemapTags(mapping map[string]string, opts ...RemapOption) ([]string, error) {
+	cfg := &remapConfig{push: true, keepTagger: true}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	normalized := make(map[string]string, len(mapping))
+	for old, new := range mapping {
+		normalized[strings.ToLower(old)] = strings.ToLower(new)
+	}
+
+	moved, err := withLockResult(r, "RemapTags", func() ([]string, error) {
+		infos, err := r.ListInfo("*")
+		if err != nil {
+			return nil, err
+		}
+		frozen, err := r.loadFrozen()
+		if err != nil {
+			return nil, err
+		}
+		var (
+			affected []TagInfo
+			moved    []string
+		)
+		for _, info := range infos {
+			if _, ok := normalized[info.Commit]; ok && info.TargetTag == "" {
+				if err := frozen.check(info.Name); err != nil {
+					return nil, err
+				}
+				affected = append(affected, info)
+			}
+		}
+		sort.Slice(affected, func(i, j int) bool {
+			return affected[i].Name < affected[j].Name
+		})
+
+		ctx := r.baseContext()
+		signer := r.signer(cfg)
+		for _, info := range affected {
+			target := normalized[info.Commit]
+			if _, err := r.revParse(ctx, target+"^{commit}"); err != nil {
+				return moved, fmt.Errorf("标签 %s 的新提交 %s 不存在: %w", info.Name, target, err)
+			}
+			if err := signer.retag(ctx, info, target, cfg); err != nil {
+				return moved, fmt.Errorf("重建标签 %s 失败: %w", info.Name, err)
+			}
+			moved = append(moved, info.Name)
+		}
+		if cfg.push {
+			return moved, r.forcePush(ctx, moved)
+		}
+		return moved, nil
+	})
+	return moved, err
+}
+
+// signer 返回用于重建标签的仓库，指定了 WithSigningKey 时以该密钥作为 user.signingkey
+func (r *Repository) signer(cfg *remapConfig) *Repository {
+	if cfg.key == "" {
+		return r
+	}
+	signer := *r
+	signer.config = append(append([]string(nil), r.config...), "user.signingkey="+cfg.key)
+	return &signer
+}
+
+// forcePush 分批强制推送重建的标签，启用 WithPushVerification 时确认远程已更新
+func (r *Repository) forcePush(ctx context.Context, names []string) error {
+	for start := 0; start < len(names); start += pushBatchSize {
+		end := min(start+pushBatchSize, len(names))
+		args := []string{"push", r.remoteName()}
+		for _, name := range names[start:end] {
+			args = append(args, "+"+tagRef(r.tagName(name)))
+		}
+		if err := r.run(ctx, args...); err != nil {
+			return fmt.Errorf("强制推送重建的标签失败: %w", err)
+		}
+	}
+	if r.pushVerifyTimeout <= 0 || r.dryRun != nil {
+		return nil
+	}
+	expected := map[string]string{}
+	for _, name := range names {
+		object, err := r.revParse(ctx, tagRef(r.tagName(name)))
+		if err != nil {
+			return fmt.Errorf("读取标签 %s 失败: %w", name, err)
+		}
+		expected[r.tagName(name)] = object
+	}
+	return r.verifyPush(ctx, expected)
+}
+
+// retag 将标签重建在 target 上，附注标签保留信息，cfg.keepTagger 为 true 时还保留打标签的人和时间
+func (r *Repository) retag(ctx context.Context, info TagInfo, target string, cfg *remapConfig) error {
+	name := r.tagName(info.Name)
+	if !info.Annotated {
+		return r.run(ctx, "tag", "-f", "--", name, target)
+	}
+
+	tagger := r
+	if cfg.keepTagger {
+		// git tag 使用提交者身份作为打标签的人
+		copied := *r
+		copied.env = append(append([]string(nil), r.env...),
+			"GIT_COMMITTER_NAME="+info.TaggerName,
+			"GIT_COMMITTER_EMAIL="+info.TaggerEmail,
+			"GIT_COMMITTER_DATE="+info.Date.Format(time.RFC3339),
+		)
+		tagger = &copied
+	}
+	args := []string{"tag", "-f", "-a", "--cleanup=verbatim", "-F", "-"}
+	if cfg.resign {
+		args = append(args, "-s")
+	} else {
+		args = append(args, "--no-sign")
+	}
+	_, _, err := tagger.execInput(ctx, strings.NewReader(info.Message), append(args, "--", name, target)...)
+	return err
+}
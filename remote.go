@@ -0,0 +1,243 @@
+package gittag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// pushBatchSize 是一次 git push 携带的引用数量上限，避免命令行过长
+const pushBatchSize = 100
+
+// remoteTags 通过 ls-remote 读取远程的标签，返回标签名称到对象 SHA 的映射，不依赖本地标签
+// 指定 refs 时只读取这些引用
+func (r *Repository) remoteTags(ctx context.Context, refs ...string) (map[string]string, error) {
+	output, err := r.output(ctx, append([]string{"ls-remote", "--tags", "--refs", r.remoteName()}, refs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("读取远程标签失败: %w", err)
+	}
+	tags := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		sha, ref, ok := strings.Cut(line, "\t")
+		if name, isTag := strings.CutPrefix(ref, "refs/tags/"); ok && isTag {
+			tags[name] = sha
+		}
+	}
+	return tags, nil
+}
+
+// pushDelete 分批删除远程标签，names 为实际的标签名称
+func (r *Repository) pushDelete(ctx context.Context, names []string) error {
+	for start := 0; start < len(names); start += pushBatchSize {
+		end := min(start+pushBatchSize, len(names))
+		args := []string{"push", r.remoteName(), "--delete"}
+		for _, name := range names[start:end] {
+			args = append(args, tagRef(name))
+		}
+		if err := r.run(ctx, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PushAllTags pushes every local tag to the remote in a single git push --tags, instead of one
+// network round-trip per tag with CreateRemote. Tags that already exist on the remote with the
+// same object are left alone; a remote tag pointing elsewhere makes the push fail.
+// @return error - 如果推送失败（例如远程已有指向其他对象的同名标签），返回相应的错误信息
+//
+// Example:
+//
+//	// Publish tags created offline or by an import job
+//	if err := gittag.PushAllTags(); err != nil {
+//		log.Fatal(err)
+//	}
+func PushAllTags() error {
+	return Default().PushAllTags()
+}
+
+// PushAllTags pushes all local tags of the repository, see the package-level PushAllTags.
+// 使用 WithSandbox 时只推送沙箱中的标签
+func (r *Repository) PushAllTags() error {
+	if r.sandbox != "" {
+		return r.PushMany("*")
+	}
+	return r.withLock("PushAllTags", func() error {
+		ctx := r.baseContext()
+		if err := r.run(ctx, "push", r.remoteName(), "--tags"); err != nil {
+			return fmt.Errorf("推送标签到远程仓库失败: %w", err)
+		}
+		if r.pushVerifyTimeout <= 0 || r.dryRun != nil {
+			return nil
+		}
+		expected, err := r.tagRefs(ctx)
+		if err != nil {
+			return err
+		}
+		return r.verifyPush(ctx, expected)
+	})
+}
+
+// PushMany 将所有匹配指定模式的本地标签分批推送到远程，每批一次 git push，没有匹配的标签时直接返回
+// @param pattern - 标签匹配模式，例如："v1.*" 将匹配所有以 v1. 开头的标签
+// @return error - 如果推送过程中出现错误，返回相应的错误信息
+//
+// Example:
+//
+//	// Publish a whole release train at once
+//	if err := gittag.PushMany("services/*/v2.4.0"); err != nil {
+//		log.Fatal(err)
+//	}
+func PushMany(pattern string) error {
+	return Default().PushMany(pattern)
+}
+
+// PushMany 将仓库中所有匹配指定模式的本地标签推送到远程，参数同包级函数 PushMany
+func (r *Repository) PushMany(pattern string) error {
+	return r.withLock("PushMany", func() error {
+		return r.pushMany(pattern)
+	})
+}
+
+func (r *Repository) pushMany(pattern string) error {
+	tags, err := r.FindMany(pattern)
+	if errors.Is(err, ErrTagNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	ctx := r.baseContext()
+	for start := 0; start < len(tags); start += pushBatchSize {
+		end := min(start+pushBatchSize, len(tags))
+		args := []string{"push", r.remoteName()}
+		for _, tag := range tags[start:end] {
+			args = append(args, tagRef(r.tagName(tag)))
+		}
+		if err := r.run(ctx, args...); err != nil {
+			return fmt.Errorf("推送标签到远程仓库失败: %w", err)
+		}
+	}
+	if r.pushVerifyTimeout <= 0 || r.dryRun != nil {
+		return nil
+	}
+	expected := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		object, err := r.revParse(ctx, tagRef(r.tagName(tag)))
+		if err != nil {
+			return fmt.Errorf("读取标签 %s 失败: %w", tag, err)
+		}
+		expected[r.tagName(tag)] = object
+	}
+	return r.verifyPush(ctx, expected)
+}
+
+// DeleteRemoteByPattern deletes the remote tags matching pattern without consulting local tags,
+// so tags that only exist on the remote can be cleaned up. Candidates are listed with ls-remote
+// and deleted with batched pushes.
+// @param pattern - 标签匹配模式，语法同 FindMany（git 通配符，* 可以匹配 "/"），例如："v1.*"
+// @return error - 如果模式无效、匹配的标签属于冻结系列或删除失败，返回相应的错误信息
+//
+// Example:
+//
+//	// Remove release candidates pushed from other machines
+//	if err := gittag.DeleteRemoteByPattern("*-rc.*"); err != nil {
+//		log.Fatal(err)
+//	}
+func DeleteRemoteByPattern(pattern string) error {
+	return Default().DeleteRemoteByPattern(pattern)
+}
+
+// DeleteRemoteByPattern deletes matching remote tags, see the package-level DeleteRemoteByPattern.
+func (r *Repository) DeleteRemoteByPattern(pattern string) error {
+	normalized, err := normalizePattern(pattern)
+	if err != nil {
+		return err
+	}
+	matcher, err := globRegexp(r.tagName(normalized))
+	if err != nil {
+		return fmt.Errorf("%w %q: %w", ErrInvalidPattern, pattern, err)
+	}
+	return r.withLock("DeleteRemoteByPattern", func() error {
+		return r.deleteRemoteByPattern(matcher)
+	})
+}
+
+// deleteRemoteByPattern 删除名称匹配 matcher 的远程标签，matcher 与 FindMany 一样按 git 通配符匹配完整的标签名称
+func (r *Repository) deleteRemoteByPattern(matcher *regexp.Regexp) error {
+	ctx := r.baseContext()
+	tags, err := r.remoteTags(ctx)
+	if err != nil {
+		return err
+	}
+	frozen, err := r.loadFrozen()
+	if err != nil {
+		return err
+	}
+	var display []string
+	for name := range tags {
+		if matcher.MatchString(name) {
+			if err := frozen.check(r.displayName(name)); err != nil {
+				return err
+			}
+			display = append(display, r.displayName(name))
+		}
+	}
+	if display, err = r.skipSupported(display); err != nil {
+		return err
+	}
+	names := make([]string, len(display))
+	for i, name := range display {
+		names[i] = r.tagName(name)
+	}
+	sort.Strings(names)
+	if err := r.pushDelete(ctx, names); err != nil {
+		return fmt.Errorf("删除远程标签失败: %w", err)
+	}
+	return nil
+}
+
+// PushTagRef creates a lightweight tag on the remote pointing at sha without creating a local
+// tag, using git push origin <sha>:refs/tags/<tag>. The object must exist locally (e.g. in a
+// CloneTemp tags-only clone); an existing remote tag is never overwritten.
+// @param tagName - 标签名称，例如："v1.0.0"
+// @param sha - 标签指向的提交，可以是完整或缩写的 SHA
+// @return error - 如果提交不存在、远程已有同名标签或推送失败，返回相应的错误信息
+//
+// Example:
+//
+//	// Tag the commit a deployment service just built
+//	if err := gittag.PushTagRef("v1.4.0", "9fceb02d0ae598e95dc970b74767f19372d61af8"); err != nil {
+//		log.Fatal(err)
+//	}
+func PushTagRef(tagName, sha string) error {
+	return Default().PushTagRef(tagName, sha)
+}
+
+// PushTagRef pushes a lightweight tag straight to the remote, see the package-level PushTagRef.
+func (r *Repository) PushTagRef(tagName, sha string) error {
+	return r.withTagLock("PushTagRef", tagName, func() error {
+		return r.pushTagRef(tagName, sha)
+	})
+}
+
+func (r *Repository) pushTagRef(tagName, sha string) error {
+	if err := r.checkFrozen(tagName); err != nil {
+		return err
+	}
+	if err := r.checkNaming(tagName); err != nil {
+		return err
+	}
+	ctx := r.baseContext()
+	commit, err := r.revParse(ctx, sha+"^{commit}")
+	if err != nil {
+		return fmt.Errorf("找不到提交 %s: %w", sha, err)
+	}
+	if err := r.run(ctx, "push", r.remoteName(), commit+":"+tagRef(r.tagName(tagName))); err != nil {
+		return fmt.Errorf("推送标签到远程仓库失败: %w", err)
+	}
+	return r.verifyPush(ctx, map[string]string{r.tagName(tagName): commit})
+}
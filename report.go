@@ -0,0 +1,217 @@
+package gittag
+
+import (
+	"context"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// Format 是报告的输出格式
+type Format string
+
+const (
+	// FormatMarkdown 输出 Markdown，适合发布说明和拉取请求
+	FormatMarkdown Format = "markdown"
+	// FormatHTML 输出 HTML 片段，适合邮件和网页
+	FormatHTML Format = "html"
+)
+
+// compareReport 是 CompareReport 模板使用的数据
+type compareReport struct {
+	From, To     string
+	Commits      []reportCommit
+	Files        int
+	Insertions   int
+	Deletions    int
+	Contributors []reportContributor
+	Modules      []reportModule
+}
+
+type reportCommit struct {
+	SHA, Subject string
+}
+
+type reportContributor struct {
+	Name    string
+	Commits int
+}
+
+// reportModule 描述 go.mod 中一个依赖的变化，From 或 To 为空表示新增或移除
+type reportModule struct {
+	Path, From, To string
+}
+
+const compareReportMarkdown = `## {{.From}} → {{.To}}
+
+{{len .Commits}} commits, {{.Files}} files changed, +{{.Insertions}} −{{.Deletions}}
+
+### Commits
+{{if .Commits}}
+{{range .Commits}}- {{.Subject}} ({{.SHA}})
+{{end}}{{else}}
+_No changes._
+{{end}}
+### Contributors
+{{if .Contributors}}
+{{range .Contributors}}- {{.Name}} ({{.Commits}})
+{{end}}{{else}}
+_None._
+{{end}}
+{{- if .Modules}}
+### Module changes
+
+| Module | {{.From}} | {{.To}} |
+|---|---|---|
+{{range .Modules}}| ` + "`{{.Path}}`" + ` | {{or .From "—"}} | {{or .To "—"}} |
+{{end}}{{end}}`
+
+const compareReportHTML = `<h2>{{.From}} → {{.To}}</h2>
+<p>{{len .Commits}} commits, {{.Files}} files changed, +{{.Insertions}} −{{.Deletions}}</p>
+<h3>Commits</h3>
+{{if .Commits}}<ul>
+{{range .Commits}}<li>{{.Subject}} (<code>{{.SHA}}</code>)</li>
+{{end}}</ul>{{else}}<p><em>No changes.</em></p>{{end}}
+<h3>Contributors</h3>
+{{if .Contributors}}<ul>
+{{range .Contributors}}<li>{{.Name}} ({{.Commits}})</li>
+{{end}}</ul>{{else}}<p><em>None.</em></p>{{end}}
+{{- if .Modules}}
+<h3>Module changes</h3>
+<table>
+<tr><th>Module</th><th>{{.From}}</th><th>{{.To}}</th></tr>
+{{range .Modules}}<tr><td><code>{{.Path}}</code></td><td>{{or .From "—"}}</td><td>{{or .To "—"}}</td></tr>
+{{end}}</table>{{end}}
+`
+
+var (
+	compareReportMarkdownTemplate = template.Must(template.New("compare").Parse(compareReportMarkdown))
+	compareReportHTMLTemplate     = htmltemplate.Must(htmltemplate.New("compare").Parse(compareReportHTML))
+)
+
+// CompareReport renders everything that changed between two tags — commits, diffstat,
+// contributors and go.mod dependency changes — as one report that can be used directly as a
+// release announcement or management summary. HTML output is escaped and safe to embed.
+// @param fromTag - 起始标签（不包含），例如："v1.1.0"
+// @param toTag - 结束标签（包含），例如："v1.2.0"
+// @param format - 输出格式，FormatMarkdown 或 FormatHTML
+// @return (string, error) - 返回渲染后的报告
+//
+// Example:
+//
+//	report, err := gittag.CompareReport("v1.1.0", "v1.2.0", gittag.FormatHTML)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	sendMail("Release v1.2.0", report)
+func CompareReport(fromTag, toTag string, format Format) (string, error) {
+	return Default().CompareReport(fromTag, toTag, format)
+}
+
+// CompareReport renders a comparison of two tags in the repository, see the package-level CompareReport.
+func (r *Repository) CompareReport(fromTag, toTag string, format Format) (string, error) {
+	var execute func(io.Writer, any) error
+	switch format {
+	case FormatMarkdown:
+		execute = compareReportMarkdownTemplate.Execute
+	case FormatHTML:
+		execute = compareReportHTMLTemplate.Execute
+	default:
+		return "", fmt.Errorf("不支持的报告格式 %q", format)
+	}
+
+	ctx := r.baseContext()
+	from, to := tagRef(r.tagName(fromTag)), tagRef(r.tagName(toTag))
+	report := compareReport{From: fromTag, To: toTag}
+	commits, err := r.changelogCommits(from, to)
+	if err != nil {
+		return "", err
+	}
+	for _, c := range commits {
+		report.Commits = append(report.Commits, reportCommit{SHA: c.sha, Subject: c.subject})
+	}
+	if err := r.diffStat(ctx, from, to, &report); err != nil {
+		return "", err
+	}
+	if report.Contributors, err = r.contributors(ctx, from, to); err != nil {
+		return "", err
+	}
+	report.Modules = r.moduleChanges(ctx, from, to)
+
+	var b strings.Builder
+	if err := execute(&b, report); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// diffStat 统计两个版本之间修改的文件数和增删行数，二进制文件只计入文件数
+func (r *Repository) diffStat(ctx context.Context, from, to string, report *compareReport) error {
+	output, err := r.output(ctx, "diff", "--numstat", "--end-of-options", from, to, "--")
+	if err != nil {
+		return fmt.Errorf("统计差异失败: %w", err)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		report.Files++
+		added, _ := strconv.Atoi(fields[0])
+		deleted, _ := strconv.Atoi(fields[1])
+		report.Insertions += added
+		report.Deletions += deleted
+	}
+	return nil
+}
+
+// contributors 返回 from..to 之间的作者，按提交数从多到少排序
+func (r *Repository) contributors(ctx context.Context, from, to string) ([]reportContributor, error) {
+	output, err := r.output(ctx, "log", "--no-merges", "--use-mailmap", "--format=%aN", "--end-of-options", from+".."+to, "--")
+	if err != nil {
+		return nil, fmt.Errorf("读取提交记录失败: %w", err)
+	}
+	counts := map[string]int{}
+	for _, name := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if name != "" {
+			counts[name]++
+		}
+	}
+	var contributors []reportContributor
+	for name, n := range counts {
+		contributors = append(contributors, reportContributor{Name: name, Commits: n})
+	}
+	sort.Slice(contributors, func(i, j int) bool {
+		if contributors[i].Commits != contributors[j].Commits {
+			return contributors[i].Commits > contributors[j].Commits
+		}
+		return contributors[i].Name < contributors[j].Name
+	})
+	return contributors, nil
+}
+
+// moduleChanges 比较两个版本根目录下 go.mod 中的依赖，没有 go.mod 时返回空
+func (r *Repository) moduleChanges(ctx context.Context, from, to string) []reportModule {
+	before, _ := r.fileAt(ctx, from, "go.mod")
+	after, _ := r.fileAt(ctx, to, "go.mod")
+	old, cur := parseGoMod(before).requires, parseGoMod(after).requires
+	var modules []reportModule
+	for path, version := range cur {
+		if old[path] != version {
+			modules = append(modules, reportModule{Path: path, From: old[path], To: version})
+		}
+	}
+	for path, version := range old {
+		if _, ok := cur[path]; !ok {
+			modules = append(modules, reportModule{Path: path, From: version})
+		}
+	}
+	sort.Slice(modules, func(i, j int) bool {
+		return modules[i].Path < modules[j].Path
+	})
+	return modules
+}
@@ -0,0 +1,342 @@
+package gittag
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// defaultEnv 保证 git 的输出使用英文且格式稳定，并且任何操作都不会等待终端输入
+var defaultEnv = []string{
+	"LC_ALL=C",
+	"GIT_TERMINAL_PROMPT=0",
+	"GCM_INTERACTIVE=never",
+}
+
+// defaultConfig 以 -c 的形式传给每个 git 命令，保证路径和日志按 UTF-8 原样输出
+var defaultConfig = []string{
+	"core.quotePath=false",
+	"i18n.logOutputEncoding=UTF-8",
+	"advice.nestedTag=false",
+}
+
+// Repository 表示一个 Git 仓库，所有操作都在该仓库目录下执行
+type Repository struct {
+	dir    string
+	env    []string
+	config []string
+	cache  bool
+
+	// store 保存幂等记录、缓存和发布进度，为 nil 时使用 .git/gittag 目录，见 WithStore
+	store Store
+
+	sandbox string
+
+	lockTimeout time.Duration
+
+	trace   *Trace
+	verbose *verboseWriter
+	// dryRun 不为 nil 时只输出写命令而不执行，见 WithDryRun
+	dryRun *verboseWriter
+
+	retryAttempts int
+	retryBackoff  time.Duration
+
+	// remote 是推送、删除和获取标签使用的远程名称，为空时使用 origin，见 WithRemote
+	remote            string
+	pushVerifyTimeout time.Duration
+	remotePreflight   bool
+
+	// httpsFallback 是 SSH 推送失败时改用的 HTTPS 地址和 token，见 WithHTTPSFallback
+	httpsFallback *httpsFallback
+	// transport 是记录到 Trace 中的传输方式，只在改用 HTTPS 推送的副本上设置
+	transport string
+
+	messageTemplate string
+
+	// sign 为 true 时创建的附注标签都会签名，signKey 为签名密钥，见 WithSignedTags
+	sign    bool
+	signKey string
+
+	naming *NamingConvention
+
+	// scheme 是 WithVersionScheme 指定的版本方案，为 nil 时使用 SemVer
+	scheme Scheme
+
+	profile *Profile
+
+	readOnly bool
+
+	// restricted 为 true 时只允许 caps 中的操作，见 Restrict
+	restricted bool
+	caps       Capability
+
+	// idempotencyKey 是 WithIdempotencyKey 绑定的幂等键
+	idempotencyKey string
+
+	// actor 是 WithActor 指定的操作者，上下文中的操作者优先，见 ContextWithActor
+	actor *Actor
+
+	// ctx 是 WithContext 绑定的上下文，为 nil 时使用 context.Background()
+	ctx context.Context
+}
+
+// Option 用于配置 Repository
+type Option func(*Repository)
+
+// WithEnv 为该仓库执行的所有 git 命令追加环境变量，格式为 "KEY=value"
+// 同名变量会覆盖默认值，例如 WithEnv("LC_ALL=zh_CN.UTF-8")
+func WithEnv(env ...string) Option {
+	return func(r *Repository) {
+		r.env = append(r.env, env...)
+	}
+}
+
+// WithGitConfig 为该仓库执行的所有 git 命令追加 -c key=value 配置，会覆盖默认配置
+func WithGitConfig(key, value string) Option {
+	return func(r *Repository) {
+		r.config = append(r.config, key+"="+value)
+	}
+}
+
+// WithURLRewrite 让该仓库的 git 命令把以 from 开头的远程地址替换为 to 开头（-c url.<to>.insteadOf=<from>），
+// 不修改用户的 git 配置，用于在 SSH 和 HTTPS 之间切换或通过内部镜像访问远程仓库；可以多次使用
+//
+// Example:
+//
+//	// Push over HTTPS with a token even though origin is an SSH URL
+//	repo, err := gittag.Open(".", gittag.WithURLRewrite("git@github.com:", "https://github.com/"))
+func WithURLRewrite(from, to string) Option {
+	return WithGitConfig("url."+to+".insteadOf", from)
+}
+
+// WithRemote 指定推送、删除、获取和查询远程标签时使用的远程名称，默认为 "origin"
+// 例如维护 fork 时使用 WithRemote("upstream")
+func WithRemote(name string) Option {
+	return func(r *Repository) {
+		r.remote = name
+	}
+}
+
+// remoteName 返回仓库使用的远程名称
+func (r *Repository) remoteName() string {
+	if r.remote != "" {
+		return r.remote
+	}
+	return "origin"
+}
+
+// defaultRepository 是包级函数使用的仓库，默认在当前工作目录下执行 git 命令
+var defaultRepository atomic.Pointer[Repository]
+
+func init() {
+	defaultRepository.Store(&Repository{})
+}
+
+// Default 返回包级函数使用的仓库，可以与 SetDefault 并发调用
+func Default() *Repository {
+	return defaultRepository.Load()
+}
+
+// SetDefault makes the package-level functions operate on repo, so settings such as the remote,
+// trace, retry or read-only mode apply to every call. It is safe to call concurrently with
+// package-level functions; calls already in flight keep using the previous repository.
+// @param repo - 包级函数使用的仓库，传入 nil 恢复为当前工作目录
+//
+// Example:
+//
+//	repo, err := gittag.Open("/srv/app", gittag.WithRetry(3, time.Second))
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	gittag.SetDefault(repo)
+//	gittag.CreateTag("v1.2.0") // runs in /srv/app with retries
+func SetDefault(repo *Repository) {
+	if repo == nil {
+		repo = &Repository{}
+	}
+	defaultRepository.Store(repo)
+}
+
+// Open opens the Git repository located at path.
+// @param path - 仓库路径，可以是工作区内的任意目录
+// @param opts - 可选配置，例如 WithEnv、WithGitConfig
+// @return (*Repository, error) - 返回仓库实例，如果 path 不是 Git 仓库则返回错误
+//
+// Example:
+//
+//	repo, err := gittag.Open("/path/to/project")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	err = repo.CreateTag("v1.0.0")
+func Open(path string, opts ...Option) (*Repository, error) {
+	dir, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开仓库失败: %w", err)
+	}
+	repo := newRepository(dir, opts...)
+	if repo.sandbox != "" {
+		if reason := invalidRefReason(repo.sandbox); reason != "" {
+			return nil, fmt.Errorf("无效的沙箱 id %q: %s", repo.sandbox, reason)
+		}
+	}
+	if repo.remote != "" {
+		if reason := invalidRefReason(repo.remote); reason != "" {
+			return nil, fmt.Errorf("无效的远程名称 %q: %s", repo.remote, reason)
+		}
+	}
+	if repo.messageTemplate != "" {
+		if _, err := parseMessageTemplate(repo.messageTemplate); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := repo.output(repo.baseContext(), "rev-parse", "--git-dir"); err != nil {
+		return nil, fmt.Errorf("打开仓库失败: %s 不是 Git 仓库: %w", dir, err)
+	}
+	return repo, nil
+}
+
+// newRepository 创建一个仓库实例并应用配置
+func newRepository(dir string, opts ...Option) *Repository {
+	repo := &Repository{dir: dir}
+	for _, opt := range opts {
+		opt(repo)
+	}
+	return repo
+}
+
+// WithContext returns a copy of the repository whose operations run under ctx: cancelling ctx or
+// reaching its deadline kills the running git command, stops retries and lock waits, and makes the
+// operation return the context's error. Operations that take their own ctx argument use that one.
+// The returned repository shares all other settings with r.
+// @param ctx - 绑定到返回的仓库的上下文
+// @return *Repository - 返回绑定了 ctx 的仓库副本
+//
+// Example:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+//	defer cancel()
+//	if err := repo.WithContext(ctx).CreateTag("v1.2.0"); err != nil {
+//		log.Fatal(err) // errors.Is(err, context.DeadlineExceeded) on a stuck push
+//	}
+//
+//	// Package-level functions use the default repository
+//	err := gittag.Default().WithContext(ctx).DeleteRemoteAll("v0.*")
+func (r *Repository) WithContext(ctx context.Context) *Repository {
+	if ctx == nil {
+		panic("gittag: nil context")
+	}
+	bound := *r
+	bound.ctx = ctx
+	return &bound
+}
+
+// baseContext 返回 WithContext 绑定的上下文
+func (r *Repository) baseContext() context.Context {
+	if r.ctx != nil {
+		return r.ctx
+	}
+	return context.Background()
+}
+
+// Dir 返回仓库所在目录，空字符串表示当前工作目录
+func (r *Repository) Dir() string {
+	return r.dir
+}
+
+// gitCommonDir 返回仓库的 .git 目录的绝对路径，多个工作树返回同一个目录
+func (r *Repository) gitCommonDir() (string, error) {
+	output, err := r.output(r.baseContext(), "rev-parse", "--git-common-dir")
+	if err != nil {
+		return "", fmt.Errorf("定位 .git 目录失败: %w", err)
+	}
+	dir := strings.TrimSpace(string(output))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(r.dir, dir)
+	}
+	return dir, nil
+}
+
+// command 构造一个在仓库目录下执行的 git 命令
+func (r *Repository) command(ctx context.Context, args ...string) *exec.Cmd {
+	var full []string
+	for _, kv := range defaultConfig {
+		full = append(full, "-c", kv)
+	}
+	for _, kv := range r.config {
+		full = append(full, "-c", kv)
+	}
+	cmd := exec.CommandContext(ctx, "git", append(full, args...)...)
+	cmd.WaitDelay = commandWaitDelay
+	cmd.Dir = r.dir
+	cmd.Env = append(append(append(os.Environ(), defaultEnv...), actorEnv(r.actorFor(ctx))...), r.env...)
+	return cmd
+}
+
+// commandWaitDelay 是 ctx 取消、git 被终止后等待其子进程（ssh、远程 hook 等）关闭输出的最长时间，
+// 超时后不再等待，避免挂起的推送让已取消的操作无法返回
+const commandWaitDelay = time.Second
+
+// exec 执行 git 命令并分别返回标准输出和标准错误
+func (r *Repository) exec(ctx context.Context, args ...string) ([]byte, []byte, error) {
+	return r.execInput(ctx, nil, args...)
+}
+
+// execInput 以 stdin 作为标准输入执行 git 命令，所有 git 命令最终都经过这里
+// 返回的错误信息已脱敏，暂时性错误按 WithRetry 的配置重试
+func (r *Repository) execInput(ctx context.Context, stdin io.Reader, args ...string) ([]byte, []byte, error) {
+	stdout, stderr, err := r.execRetry(ctx, stdin, args...)
+	if err != nil && stdin == nil {
+		if fallback, fallbackArgs := r.fallbackRepository(ctx, args, err); fallback != nil {
+			return fallback.execRetry(ctx, nil, fallbackArgs...)
+		}
+	}
+	return stdout, stderr, err
+}
+
+// execOnce 执行一次 git 命令
+func (r *Repository) execOnce(ctx context.Context, stdin io.Reader, args ...string) ([]byte, []byte, error) {
+	if err := r.checkCommand(args); err != nil {
+		return nil, nil, err
+	}
+	if err := r.checkCapability(args); err != nil {
+		return nil, nil, err
+	}
+	if r.skipDryRun(args) {
+		return nil, nil, nil
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := r.command(ctx, args...)
+	cmd.Stdin = stdin
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	flush := r.stream(cmd)
+	start := time.Now()
+	err := cmd.Run()
+	flush()
+	r.record(cmd, start, err)
+	if err != nil {
+		err = newGitError(args, stderr.Bytes(), err)
+	}
+	return stdout.Bytes(), stderr.Bytes(), redactError(err)
+}
+
+// run 执行 git 命令并丢弃输出
+func (r *Repository) run(ctx context.Context, args ...string) error {
+	_, _, err := r.exec(ctx, args...)
+	return err
+}
+
+// output 执行 git 命令并返回标准输出
+func (r *Repository) output(ctx context.Context, args ...string) ([]byte, error) {
+	stdout, _, err := r.exec(ctx, args...)
+	return stdout, err
+}
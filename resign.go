@@ -0,0 +1,78 @@
+package gittag
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ResignAll recreates every annotated tag matching pattern as a signed tag — same target, same
+// message, with the current identity as tagger — and force-pushes them in batches, for projects
+// adopting tag signing retroactively or rotating their signing key. The signing setup is checked
+// with SigningPreflight before any tag is touched. Lightweight tags and tags pointing at other
+// tags cannot be re-signed in place and are skipped.
+// @param pattern - 标签的匹配模式，例如："v*"
+// @param opts - 可选配置，例如 WithSigningKey、WithoutPush
+// @return ([]string, error) - 返回重新签名的标签名称
+//
+// Example:
+//
+//	resigned, err := gittag.ResignAll("v*", gittag.WithSigningKey("~/.ssh/release_ed25519"))
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	log.Printf("re-signed %d tags", len(resigned))
+func ResignAll(pattern string, opts ...RemapOption) ([]string, error) {
+	return Default().ResignAll(pattern, opts...)
+}
+
+// ResignAll re-signs the repository's tags, see the package-level ResignAll.
+func (r *Repository) ResignAll(pattern string, opts ...RemapOption) ([]string, error) {
+	cfg := &remapConfig{push: true}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	cfg.resign = true
+	signer := r.signer(cfg)
+	if err := signer.SigningPreflight(); err != nil {
+		return nil, fmt.Errorf("无法签名标签: %w", err)
+	}
+
+	resigned, err := withLockResult(r, "ResignAll", func() ([]string, error) {
+		infos, err := r.ListInfo(pattern)
+		if err != nil {
+			return nil, err
+		}
+		frozen, err := r.loadFrozen()
+		if err != nil {
+			return nil, err
+		}
+		var (
+			affected []TagInfo
+			resigned []string
+		)
+		for _, info := range infos {
+			if info.Annotated && info.TargetTag == "" {
+				if err := frozen.check(info.Name); err != nil {
+					return nil, err
+				}
+				affected = append(affected, info)
+			}
+		}
+		sort.Slice(affected, func(i, j int) bool {
+			return affected[i].Name < affected[j].Name
+		})
+
+		ctx := r.baseContext()
+		for _, info := range affected {
+			if err := signer.retag(ctx, info, info.Commit, cfg); err != nil {
+				return resigned, fmt.Errorf("重新签名标签 %s 失败: %w", info.Name, err)
+			}
+			resigned = append(resigned, info.Name)
+		}
+		if cfg.push {
+			return resigned, r.forcePush(ctx, resigned)
+		}
+		return resigned, nil
+	})
+	return resigned, err
+}
@@ -0,0 +1,140 @@
+package gittag
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Resolve returns the commit SHA a tag ultimately points to, peeling nested tags.
+// @param tagName - 标签名称，例如："v1.0.0" 或嵌套标签 "verified/v1.0.0"
+// @return (string, error) - 返回提交的完整 SHA
+//
+// Example:
+//
+//	sha, err := gittag.Resolve("v1.0.0")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	fmt.Println(sha)
+func Resolve(tagName string) (string, error) {
+	return Default().Resolve(tagName)
+}
+
+// Resolve returns the commit a tag in the repository points to, see the package-level Resolve.
+func (r *Repository) Resolve(tagName string) (string, error) {
+	output, err := r.output(r.baseContext(), "rev-parse", "--verify", "--quiet", tagRef(r.tagName(tagName))+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("解析标签 %s 失败: %w", tagName, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// Describe returns the closest tag reachable from rev in git describe format, e.g. "v1.2.0-3-gabc1234".
+// Nested tags are peeled to their commit like any other annotated tag.
+// @param rev - 要描述的提交、分支或标签，为空时为 HEAD
+// @return (string, error) - 返回 git describe --tags 的结果，rev 不可达任何标签时返回满足 errors.Is(err, ErrTagNotFound) 的错误
+//
+// Example:
+//
+//	version, err := gittag.Describe("")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	fmt.Println(version)
+func Describe(rev string) (string, error) {
+	return Default().Describe(rev)
+}
+
+// Describe describes rev in the repository, see the package-level Describe.
+func (r *Repository) Describe(rev string) (string, error) {
+	if rev == "" {
+		rev = "HEAD"
+	}
+	args := []string{"describe", "--tags"}
+	if r.sandbox != "" {
+		args = append(args, "--match", sandboxPrefix(r.sandbox)+"*")
+	}
+	output, err := r.output(r.baseContext(), append(args, "--end-of-options", rev)...)
+	if Kind(err) == KindNotFound {
+		return "", fmt.Errorf("描述 %s 失败: %w", rev, &NotFoundError{Pattern: "*"})
+	}
+	if err != nil {
+		return "", fmt.Errorf("描述 %s 失败: %w", rev, err)
+	}
+	return r.displayName(strings.TrimSpace(string(output))), nil
+}
+
+// CreateNestedTag creates an annotated tag that points at another annotated tag's object rather
+// than at a commit, e.g. a "verified/v1.2.3" attestation for "v1.2.3". Like CreateLocal it checks
+// frozen series and the naming convention and records the actor. The new tag is local only;
+// push it with CreateRemote.
+// @param tagName - 新标签名称，例如："verified/v1.2.3"
+// @param targetTag - 被标记的附注标签，例如："v1.2.3"
+// @param message - 标签信息（可选），默认由 SetMessageTemplate 或 WithMessageTemplate 的模板生成
+// @return error - 如果目标不是附注标签或创建失败，返回相应的错误信息
+//
+// Example:
+//
+//	// Record that v1.2.3 passed verification
+//	err := gittag.CreateNestedTag("verified/v1.2.3", "v1.2.3", "verified by pipeline #42")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+func CreateNestedTag(tagName, targetTag string, message ...string) error {
+	return Default().CreateNestedTag(tagName, targetTag, message...)
+}
+
+// CreateNestedTag creates a tag of a tag in the repository, see the package-level CreateNestedTag.
+func (r *Repository) CreateNestedTag(tagName, targetTag string, message ...string) error {
+	return r.withTagLock("CreateNestedTag", tagName, func() error {
+		return r.createNestedTag(tagName, targetTag, message...)
+	})
+}
+
+// createNestedTag 确认目标是附注标签后走 create 的完整流程（冻结、命名约定、创建前检查、记录操作者），
+// 以标签引用作为目标时 git 标记的是标签对象本身
+func (r *Repository) createNestedTag(tagName, targetTag string, message ...string) error {
+	target := tagRef(r.tagName(targetTag))
+	output, err := r.output(r.baseContext(), "cat-file", "-t", target)
+	if err != nil {
+		return fmt.Errorf("读取标签 %s 失败: %w", targetTag, err)
+	}
+	if strings.TrimSpace(string(output)) != "tag" {
+		return fmt.Errorf("标签 %s 不是附注标签，无法创建嵌套标签", targetTag)
+	}
+	return r.create(tagName, newCreateConfig(append(messageOptions(message), WithTarget(target))...))
+}
+
+// RawTagObject returns the exact bytes of an annotated tag object (git cat-file tag), including
+// the tagger header and any signature block, for external verification tooling and archival.
+// @param tagName - 附注标签名称，例如："v1.0.0"
+// @return ([]byte, error) - 返回标签对象的原始内容，轻量标签没有标签对象，会返回错误
+//
+// Example:
+//
+//	raw, err := gittag.RawTagObject("v1.0.0")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	os.WriteFile("v1.0.0.tag", raw, 0o644)
+func RawTagObject(tagName string) ([]byte, error) {
+	return Default().RawTagObject(tagName)
+}
+
+// RawTagObject returns the raw tag object from the repository, see the package-level RawTagObject.
+func (r *Repository) RawTagObject(tagName string) ([]byte, error) {
+	ctx := r.baseContext()
+	ref := tagRef(r.tagName(tagName))
+	kind, err := r.output(ctx, "cat-file", "-t", ref)
+	if err != nil {
+		return nil, fmt.Errorf("读取标签 %s 失败: %w", tagName, err)
+	}
+	if strings.TrimSpace(string(kind)) != "tag" {
+		return nil, fmt.Errorf("标签 %s 是轻量标签，没有标签对象", tagName)
+	}
+	raw, err := r.output(ctx, "cat-file", "tag", ref)
+	if err != nil {
+		return nil, fmt.Errorf("读取标签对象 %s 失败: %w", tagName, err)
+	}
+	return raw, nil
+}
@@ -0,0 +1,83 @@
+package gittag
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+// defaultMaxBackoff 是重试间隔的上限
+const defaultMaxBackoff = 30 * time.Second
+
+// WithRetry 在 git 命令因暂时性错误（网络、锁竞争、服务端 5xx）失败时重试，最多执行 attempts 次
+// 每次重试的间隔从 backoff 开始加倍，最长 30 秒；认证失败、非快进等永久性错误不会重试
+//
+// Example:
+//
+//	repo, err := gittag.Open(".", gittag.WithRetry(4, time.Second))
+func WithRetry(attempts int, backoff time.Duration) Option {
+	return func(r *Repository) {
+		r.retryAttempts = attempts
+		r.retryBackoff = backoff
+	}
+}
+
+// IsTransient 报告 err 是否为重试后可能成功的暂时性错误
+func IsTransient(err error) bool {
+	switch Kind(err) {
+	case KindNetworkTimeout, KindRefLocked, KindServerError:
+		return true
+	}
+	return false
+}
+
+// guidance 是永久性错误的处理建议
+var guidance = map[ErrorKind]string{
+	KindAuthFailed:     "检查远程仓库的凭据（token、SSH 密钥或 credential helper）是否有效且具有推送权限",
+	KindNotFastForward: "远程已有不同的提交或标签，先运行 FetchTags 同步后再重试",
+	KindRejected:       "远程仓库的策略（受保护的标签、pre-receive hook）拒绝了该操作，请联系仓库管理员",
+	KindTagExists:      "标签已存在，请换一个版本号；如确需移动标签（例如浮动的 nightly 标签），使用 WithForce",
+}
+
+// execRetry 按仓库的重试配置执行 git 命令，stdin 会被完整读入以便重试时重放
+func (r *Repository) execRetry(ctx context.Context, stdin io.Reader, args ...string) ([]byte, []byte, error) {
+	var input []byte
+	if stdin != nil {
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return nil, nil, fmt.Errorf("读取标准输入失败: %w", err)
+		}
+		input = data
+	}
+
+	backoff := r.retryBackoff
+	for attempt := 1; ; attempt++ {
+		var in io.Reader
+		if stdin != nil {
+			in = bytes.NewReader(input)
+		}
+		stdout, stderr, err := r.execOnce(ctx, in, args...)
+		if err == nil || attempt >= r.retryAttempts || !IsTransient(err) {
+			return stdout, stderr, withGuidance(err)
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return stdout, stderr, err
+		case <-timer.C:
+		}
+		backoff = min(backoff*2, defaultMaxBackoff)
+	}
+}
+
+// withGuidance 为永久性错误附加处理建议
+func withGuidance(err error) error {
+	if hint, ok := guidance[Kind(err)]; ok {
+		return fmt.Errorf("%w\n提示: %s", err, hint)
+	}
+	return err
+}
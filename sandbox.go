@@ -0,0 +1,109 @@
+package gittag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// sandboxNamespace 是沙箱标签的公共前缀
+const sandboxNamespace = "sandbox/"
+
+// WithSandbox 启用沙箱模式，仓库创建、删除和查找的所有标签都会被透明地加上 "sandbox/<id>/" 前缀
+// 例如 CreateTag("v1.0.0") 实际创建 "sandbox/ci-run-123/v1.0.0"，FindMany 返回的名称不含前缀
+// 测试结束后使用 CleanupSandbox(id) 删除该沙箱的所有标签
+func WithSandbox(id string) Option {
+	return func(r *Repository) {
+		r.sandbox = id
+	}
+}
+
+// sandboxPrefix 返回沙箱 id 对应的标签前缀
+func sandboxPrefix(id string) string {
+	return sandboxNamespace + id + "/"
+}
+
+// tagName 将调用方使用的标签名称或模式转换为实际的标签名称
+func (r *Repository) tagName(name string) string {
+	if r.sandbox == "" {
+		return name
+	}
+	return sandboxPrefix(r.sandbox) + name
+}
+
+// displayName 去掉实际标签名称中的沙箱前缀
+func (r *Repository) displayName(name string) string {
+	if r.sandbox == "" {
+		return name
+	}
+	return strings.TrimPrefix(name, sandboxPrefix(r.sandbox))
+}
+
+// CleanupSandbox removes every tag of the sandbox id both locally and on the remote.
+// Remote tags are discovered with ls-remote, so tags pushed from other checkouts are removed too.
+// @param id - 沙箱 id，与 WithSandbox 中使用的相同，例如："ci-run-123"
+// @return error - 如果删除过程中出现错误，返回相应的错误信息；远程删除失败时本地标签仍会被删除
+//
+// Example:
+//
+//	repo, err := gittag.Open(".", gittag.WithSandbox("ci-run-"+os.Getenv("GITHUB_RUN_ID")))
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer gittag.CleanupSandbox("ci-run-" + os.Getenv("GITHUB_RUN_ID"))
+//
+//	// Really pushes sandbox/ci-run-<id>/v1.0.0
+//	err = repo.CreateTag("v1.0.0")
+func CleanupSandbox(id string) error {
+	return Default().CleanupSandbox(id)
+}
+
+// CleanupSandbox removes every tag of the sandbox id, see the package-level CleanupSandbox.
+func (r *Repository) CleanupSandbox(id string) error {
+	if reason := invalidRefReason(id); reason != "" {
+		return fmt.Errorf("无效的沙箱 id %q: %s", id, reason)
+	}
+	return r.withLock("CleanupSandbox", func() error {
+		return r.cleanupSandbox(id)
+	})
+}
+
+// cleanupSandbox 先删除本地沙箱标签，再删除远程沙箱标签，远程不可达时本地标签仍会被清理
+func (r *Repository) cleanupSandbox(id string) error {
+	ctx := r.baseContext()
+	prefix := sandboxPrefix(id)
+
+	output, err := r.output(ctx, "for-each-ref", "--format=%(refname:strip=2)", "refs/tags/"+prefix)
+	if err != nil {
+		return fmt.Errorf("读取沙箱标签失败: %w", err)
+	}
+	var localErr error
+	if local := strings.Fields(string(output)); len(local) > 0 {
+		args := append([]string{"tag", "-d", "--"}, local...)
+		if err := r.run(ctx, args...); err != nil {
+			localErr = fmt.Errorf("删除本地沙箱标签失败: %w", err)
+		}
+	}
+	return errors.Join(localErr, r.cleanupSandboxRemote(ctx, prefix))
+}
+
+// cleanupSandboxRemote 删除远程仓库中名称以 prefix 开头的标签
+func (r *Repository) cleanupSandboxRemote(ctx context.Context, prefix string) error {
+	tags, err := r.remoteTags(ctx)
+	if err != nil {
+		return fmt.Errorf("删除远程沙箱标签失败: %w", err)
+	}
+	var remote []string
+	for name := range tags {
+		if strings.HasPrefix(name, prefix) {
+			remote = append(remote, name)
+		}
+	}
+	sort.Strings(remote)
+	if err := r.pushDelete(ctx, remote); err != nil {
+		return fmt.Errorf("删除远程沙箱标签失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package gittag
+
+import (
+	"fmt"
+	"strings"
+)
+
+// tagRef 返回标签的完整引用名，推送时使用完整引用可以避免以 "-" 开头的名称被当作参数解析
+func tagRef(tagName string) string {
+	return "refs/tags/" + tagName
+}
+
+// SanitizeTagName validates a tag name that may come from user input and returns it with
+// surrounding whitespace removed. Names that git would reject or that could be mistaken for
+// command-line options (e.g. "--delete", "-d") are refused.
+// @param tagName - 待校验的标签名称，例如来自表单或命令行参数
+// @return (string, error) - 返回清理后的标签名称，如果名称不合法则返回错误
+//
+// Example:
+//
+//	// Validate a tag name received from a web form
+//	name, err := gittag.SanitizeTagName(r.FormValue("tag"))
+//	if err != nil {
+//		http.Error(w, err.Error(), http.StatusBadRequest)
+//		return
+//	}
+//	err = gittag.CreateTag(name)
+func SanitizeTagName(tagName string) (string, error) {
+	name := strings.TrimSpace(tagName)
+	if reason := invalidRefReason(name); reason != "" {
+		return "", fmt.Errorf("无效的标签名称 %q: %s", tagName, reason)
+	}
+	return name, nil
+}
+
+// invalidRefReason 按 git check-ref-format 的规则检查名称，合法时返回空字符串
+func invalidRefReason(name string) string {
+	switch {
+	case name == "":
+		return "名称为空"
+	case strings.HasPrefix(name, "-"):
+		return "不能以 \"-\" 开头"
+	case name == "@":
+		return "不能为 \"@\""
+	case strings.HasSuffix(name, "/") || strings.HasSuffix(name, "."):
+		return "不能以 \"/\" 或 \".\" 结尾"
+	case strings.HasSuffix(name, ".lock"):
+		return "不能以 \".lock\" 结尾"
+	case strings.Contains(name, ".."):
+		return "不能包含 \"..\""
+	case strings.Contains(name, "@{"):
+		return "不能包含 \"@{\""
+	case strings.Contains(name, "//"):
+		return "不能包含连续的 \"/\""
+	}
+	for _, c := range name {
+		if c < 0x20 || c == 0x7f || strings.ContainsRune(" ~^:?*[\\", c) {
+			return fmt.Sprintf("不能包含字符 %q", c)
+		}
+	}
+	for _, part := range strings.Split(name, "/") {
+		if strings.HasPrefix(part, ".") {
+			return "路径分段不能以 \".\" 开头"
+		}
+	}
+	return ""
+}
+
+// ValidatePattern checks a tag glob pattern that may come from user input before it is passed to
+// git. Empty patterns, patterns that could be parsed as options, path traversal ("..", leading
+// "/") and control characters are rejected; glob characters (*, ?, [...]) are allowed.
+// @param pattern - 待校验的匹配模式，例如："v1.*"
+// @return error - 如果模式不合法，返回说明原因的错误信息
+//
+// Example:
+//
+//	if err := gittag.ValidatePattern(r.URL.Query().Get("pattern")); err != nil {
+//		http.Error(w, err.Error(), http.StatusBadRequest)
+//		return
+//	}
+func ValidatePattern(pattern string) error {
+	_, err := normalizePattern(pattern)
+	return err
+}
+
+// normalizePattern 去掉模式两端的空白和 "refs/tags/" 前缀，并拒绝不安全的模式
+func normalizePattern(pattern string) (string, error) {
+	p := strings.TrimPrefix(strings.TrimSpace(pattern), "refs/tags/")
+	reason := ""
+	switch {
+	case p == "":
+		reason = "模式为空"
+	case strings.HasPrefix(p, "-"):
+		reason = "不能以 \"-\" 开头"
+	case strings.HasPrefix(p, "/"):
+		reason = "不能以 \"/\" 开头"
+	case strings.Contains(p, ".."):
+		reason = "不能包含 \"..\""
+	case strings.Contains(p, "//"):
+		reason = "不能包含连续的 \"/\""
+	case strings.Contains(p, "@{"):
+		reason = "不能包含 \"@{\""
+	}
+	for _, c := range p {
+		if reason == "" && (c < 0x20 || c == 0x7f || c == '\\') {
+			reason = fmt.Sprintf("不能包含字符 %q", c)
+		}
+	}
+	if reason != "" {
+		return "", fmt.Errorf("%w %q: %s", ErrInvalidPattern, pattern, reason)
+	}
+	return p, nil
+}
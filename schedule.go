@@ -0,0 +1,166 @@
+package gittag
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule 决定任务的下一次运行时间
+type Schedule interface {
+	// Next 返回 after 之后的下一次运行时间，零值表示不再运行
+	Next(after time.Time) time.Time
+}
+
+// Every 返回一个固定间隔的调度，与 time.NewTicker 一样 interval 必须为正数，否则 panic
+func Every(interval time.Duration) Schedule {
+	if interval <= 0 {
+		panic(fmt.Sprintf("gittag: Every 的间隔必须为正数: %s", interval))
+	}
+	return every(interval)
+}
+
+type every time.Duration
+
+func (e every) Next(after time.Time) time.Time {
+	return after.Add(time.Duration(e))
+}
+
+// cronSchedule 是解析后的 cron 表达式，每个字段用位集表示允许的值
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// domAny、dowAny 记录日期和星期字段是否包含所有取值（例如 "*"、"*/1"、"1-31"），两者都被限制时满足任意一个即可，与 cron 一致
+	domAny, dowAny bool
+}
+
+// cronFields 是 cron 各字段的取值范围
+var cronFields = []struct {
+	name     string
+	min, max int
+}{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+// ParseCron parses a standard 5-field cron expression (minute hour day-of-month month day-of-week)
+// supporting "*", lists, ranges and steps, plus the @hourly, @daily, @weekly and @monthly macros.
+// Times are evaluated in the location of the time passed to Next.
+// @param spec - cron 表达式，例如："*/15 * * * *" 或 "0 3 * * 1-5"
+// @return (Schedule, error) - 返回调度，如果表达式无效则返回错误
+//
+// Example:
+//
+//	nightly, err := gittag.ParseCron("0 3 * * *")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+func ParseCron(spec string) (Schedule, error) {
+	switch spec {
+	case "@hourly":
+		spec = "0 * * * *"
+	case "@daily":
+		spec = "0 0 * * *"
+	case "@weekly":
+		spec = "0 0 * * 0"
+	case "@monthly":
+		spec = "0 0 1 * *"
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("无效的 cron 表达式 %q: 需要 %d 个字段", spec, len(cronFields))
+	}
+	var bits [5]uint64
+	for i, field := range fields {
+		b, err := parseCronField(field, cronFields[i].min, cronFields[i].max)
+		if err != nil {
+			return nil, fmt.Errorf("无效的 cron 表达式 %q: %s 字段 %w", spec, cronFields[i].name, err)
+		}
+		bits[i] = b
+	}
+	if bits[4]&(1<<7) != 0 {
+		bits[4] |= 1 // 7 和 0 都表示星期日
+	}
+	return &cronSchedule{
+		minute: bits[0], hour: bits[1], dom: bits[2], month: bits[3], dow: bits[4],
+		domAny: bits[2] == cronAll(cronFields[2].min, cronFields[2].max),
+		dowAny: bits[4]&cronAll(0, 6) == cronAll(0, 6),
+	}, nil
+}
+
+// cronAll 返回包含 min 到 max 所有取值的位集
+func cronAll(min, max int) uint64 {
+	return (1<<uint(max+1) - 1) &^ (1<<uint(min) - 1)
+}
+
+// parseCronField 解析 cron 的一个字段，例如 "1-5"、"*/10"、"0,30"
+func parseCronField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("步长 %q 无效", stepPart)
+			}
+			step = n
+		}
+		lo, hi := min, max
+		if rangePart != "*" {
+			from, to, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if lo, err = strconv.Atoi(from); err != nil {
+				return 0, fmt.Errorf("%q 不是数字", from)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(to); err != nil {
+					return 0, fmt.Errorf("%q 不是数字", to)
+				}
+			} else if hasStep {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("%q 超出范围 %d-%d", part, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// Next 逐级跳过不匹配的月、日、小时和分钟，最多向后查找 5 年
+func (c *cronSchedule) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case c.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !c.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case c.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case c.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (c *cronSchedule) dayMatches(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domAny || c.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
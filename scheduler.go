@@ -0,0 +1,246 @@
+package gittag
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Task 是 Scheduler 运行的维护任务，ctx 在 Scheduler 停止时取消
+type Task func(ctx context.Context) error
+
+// TaskStats 是单个任务的运行统计
+type TaskStats struct {
+	Name         string        `json:"name"`
+	Runs         int           `json:"runs"`
+	Failures     int           `json:"failures"`
+	LastRun      time.Time     `json:"lastRun,omitempty"`
+	LastDuration time.Duration `json:"lastDuration"`
+	LastError    string        `json:"lastError,omitempty"`
+	NextRun      time.Time     `json:"nextRun,omitempty"`
+}
+
+// TaskOption 用于配置 Scheduler 中的任务
+type TaskOption func(*scheduledTask)
+
+// WithJitter 在每次运行前随机延迟 [0, max)，避免多个实例同时访问远程仓库
+func WithJitter(max time.Duration) TaskOption {
+	return func(t *scheduledTask) {
+		t.jitter = max
+	}
+}
+
+// WithTaskTimeout 限制任务单次运行的时长，超时后取消任务的 ctx
+func WithTaskTimeout(timeout time.Duration) TaskOption {
+	return func(t *scheduledTask) {
+		t.timeout = timeout
+	}
+}
+
+type scheduledTask struct {
+	name     string
+	schedule Schedule
+	run      Task
+	jitter   time.Duration
+	timeout  time.Duration
+
+	mu    sync.Mutex
+	stats TaskStats
+}
+
+// Scheduler runs maintenance tasks on a schedule inside a long-running service. FetchTagsTask,
+// PruneEOLTask, DeleteOrphansTask and SyncStatusTask build the common ones from a Repository; any other
+// func(ctx) error can be added as well. Each task runs in its own goroutine and never overlaps
+// with itself; per-task statistics are available from Stats.
+//
+// Example:
+//
+//	repo, _ := gittag.Open("/srv/mirror")
+//	nightly, _ := gittag.ParseCron("0 3 * * *")
+//
+//	s := gittag.NewScheduler()
+//	s.Add("fetch-tags", gittag.Every(5*time.Minute), repo.FetchTagsTask(), gittag.WithJitter(30*time.Second))
+//	s.Add("prune-eol", nightly, repo.PruneEOLTask("v*"))
+//	s.Add("delete-orphans", nightly, repo.DeleteOrphansTask())
+//	s.Add("sync-alert", gittag.Every(time.Hour), repo.SyncStatusTask("v*", func(ctx context.Context, status *gittag.TagSyncStatus) error {
+//		return pager.Notify(ctx, "release tags out of sync: "+status.String())
+//	}))
+//	go s.Run(ctx)
+type Scheduler struct {
+	mu      sync.Mutex
+	tasks   []*scheduledTask
+	running bool
+}
+
+// NewScheduler 创建一个空的 Scheduler
+func NewScheduler() *Scheduler {
+	return &Scheduler{}
+}
+
+// Add 添加一个任务，必须在 Run 之前调用，任务名称不能重复
+func (s *Scheduler) Add(name string, schedule Schedule, task Task, opts ...TaskOption) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running {
+		return fmt.Errorf("调度器已在运行，无法添加任务 %s", name)
+	}
+	for _, t := range s.tasks {
+		if t.name == name {
+			return fmt.Errorf("任务 %s 已存在", name)
+		}
+	}
+	t := &scheduledTask{name: name, schedule: schedule, run: task, stats: TaskStats{Name: name}}
+	for _, opt := range opts {
+		opt(t)
+	}
+	s.tasks = append(s.tasks, t)
+	return nil
+}
+
+// Run 运行所有任务，直到 ctx 结束，并等待正在运行的任务返回
+func (s *Scheduler) Run(ctx context.Context) error {
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return fmt.Errorf("调度器已在运行")
+	}
+	s.running = true
+	tasks := append([]*scheduledTask(nil), s.tasks...)
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, t := range tasks {
+		wg.Add(1)
+		go func(t *scheduledTask) {
+			defer wg.Done()
+			t.loop(ctx)
+		}(t)
+	}
+	wg.Wait()
+
+	s.mu.Lock()
+	s.running = false
+	s.mu.Unlock()
+	return ctx.Err()
+}
+
+// Stats 返回所有任务的运行统计，按添加顺序排列
+func (s *Scheduler) Stats() []TaskStats {
+	s.mu.Lock()
+	tasks := append([]*scheduledTask(nil), s.tasks...)
+	s.mu.Unlock()
+
+	stats := make([]TaskStats, 0, len(tasks))
+	for _, t := range tasks {
+		t.mu.Lock()
+		stats = append(stats, t.stats)
+		t.mu.Unlock()
+	}
+	return stats
+}
+
+// FetchTagsTask 返回运行 FetchTags 的任务，用于让镜像或发布服务的本地标签与远程保持同步
+func (r *Repository) FetchTagsTask() Task {
+	return func(ctx context.Context) error {
+		return r.WithContext(ctx).FetchTags()
+	}
+}
+
+// PruneEOLTask 返回删除匹配 pattern 且所属系列已过 EOL 的本地标签（见 PastEOL）的任务，
+// 仍受支持或没有配置 EOL 的系列不受影响，远程标签也不会被删除
+func (r *Repository) PruneEOLTask(pattern string) Task {
+	return func(ctx context.Context) error {
+		repo := r.WithContext(ctx)
+		return repo.withLock("PruneEOL", func() error {
+			expired, err := repo.PastEOL(pattern)
+			if err != nil {
+				return err
+			}
+			for _, tag := range expired {
+				if err := repo.deleteLocal(tag); err != nil {
+					return fmt.Errorf("删除标签 %s 失败: %w", tag, err)
+				}
+			}
+			return nil
+		})
+	}
+}
+
+// DeleteOrphansTask 返回运行 DeleteOrphans 的任务，清理指向已被删除分支上提交的本地标签
+func (r *Repository) DeleteOrphansTask() Task {
+	return func(ctx context.Context) error {
+		_, err := r.WithContext(ctx).DeleteOrphans()
+		return err
+	}
+}
+
+// SyncStatusTask 返回检查匹配 pattern 的本地标签与远程标签是否一致（见 SyncStatus）的任务，
+// 不一致时调用 alert，alert 返回的错误记录为任务失败
+func (r *Repository) SyncStatusTask(pattern string, alert func(ctx context.Context, status *TagSyncStatus) error) Task {
+	return func(ctx context.Context) error {
+		status, err := r.WithContext(ctx).SyncStatus(pattern)
+		if err != nil {
+			return err
+		}
+		if status.InSync() {
+			return nil
+		}
+		return alert(ctx, status)
+	}
+}
+
+// loop 按调度依次运行任务，直到 ctx 结束或调度不再有下一次
+func (t *scheduledTask) loop(ctx context.Context) {
+	for {
+		next := t.schedule.Next(time.Now())
+		if next.IsZero() {
+			return
+		}
+		if t.jitter > 0 {
+			next = next.Add(time.Duration(rand.Int63n(int64(t.jitter))))
+		}
+		t.mu.Lock()
+		t.stats.NextRun = next
+		t.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		t.runOnce(ctx)
+	}
+}
+
+// runOnce 运行一次任务并更新统计，任务中的 panic 会被记录为失败
+func (t *scheduledTask) runOnce(ctx context.Context) {
+	if t.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.timeout)
+		defer cancel()
+	}
+	start := time.Now()
+	err := func() (err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = fmt.Errorf("任务 panic: %v", p)
+			}
+		}()
+		return t.run(ctx)
+	}()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stats.Runs++
+	t.stats.LastRun = start
+	t.stats.LastDuration = time.Since(start)
+	t.stats.LastError = ""
+	if err != nil {
+		t.stats.Failures++
+		t.stats.LastError = Redact(err.Error())
+	}
+}
@@ -0,0 +1,159 @@
+package gittag
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Scheme describes how a repository names its releases, so version-aware operations such as
+// FindOne with HighestVersion work on repositories that do not use semantic versions.
+type Scheme interface {
+	// Match 判断标签是否按该方案命名
+	Match(tag string) bool
+	// Compare 比较两个按该方案命名的标签，a 较旧时返回负数，相同时返回 0，a 较新时返回正数
+	Compare(a, b string) int
+}
+
+// WithVersionScheme 指定仓库的版本方案，作为 FindOne、FindMany 和 FindLatest 的默认方案（WithScheme 仍可覆盖），
+// 并让 PastEOL、Stats 和 PruneEOLTask 只把按该方案命名的标签视为发布；BumpPatch 等按语义化版本递增，只支持 SemVer
+func WithVersionScheme(scheme Scheme) Option {
+	return func(r *Repository) {
+		r.scheme = scheme
+	}
+}
+
+// versionScheme 返回 WithVersionScheme 指定的版本方案，默认为 SemVer
+func (r *Repository) versionScheme() Scheme {
+	if r.scheme == nil {
+		return SemVer
+	}
+	return r.scheme
+}
+
+// SemVer 是语义化版本方案，例如 "v1.2.3"、"1.2.3-rc.1"，这是默认方案
+var SemVer Scheme = semverScheme{}
+
+type semverScheme struct{}
+
+func (semverScheme) Match(tag string) bool {
+	_, ok := parseSemver(tag)
+	return ok
+}
+
+func (semverScheme) Compare(a, b string) int {
+	va, _ := parseLooseSemver(a)
+	vb, _ := parseLooseSemver(b)
+	return compareSemver(va, vb)
+}
+
+// CalVer 返回按日期命名的方案，layout 是 time.Parse 的格式，例如 "RELEASE_2006_01_02" 匹配 "RELEASE_2024_06_01"
+func CalVer(layout string) Scheme {
+	return calverScheme{layout: layout}
+}
+
+type calverScheme struct {
+	layout string
+}
+
+func (s calverScheme) Match(tag string) bool {
+	_, err := time.Parse(s.layout, tag)
+	return err == nil
+}
+
+func (s calverScheme) Compare(a, b string) int {
+	ta, _ := time.Parse(s.layout, a)
+	tb, _ := time.Parse(s.layout, b)
+	return ta.Compare(tb)
+}
+
+// DateBuild 返回 "<日期><sep><构建号>" 形式的方案，例如 DateBuild("2006.01.02", ".") 匹配 "2024.06.01.3"
+// 先按日期比较，同一天再按构建号的数值比较
+func DateBuild(layout, sep string) Scheme {
+	return dateBuildScheme{layout: layout, sep: sep}
+}
+
+type dateBuildScheme struct {
+	layout, sep string
+}
+
+// parse 拆分出日期和构建号
+func (s dateBuildScheme) parse(tag string) (time.Time, int, bool) {
+	i := strings.LastIndex(tag, s.sep)
+	if i < 0 || s.sep == "" {
+		return time.Time{}, 0, false
+	}
+	date, err := time.Parse(s.layout, tag[:i])
+	if err != nil {
+		return time.Time{}, 0, false
+	}
+	build, err := strconv.Atoi(tag[i+len(s.sep):])
+	if err != nil || build < 0 {
+		return time.Time{}, 0, false
+	}
+	return date, build, true
+}
+
+func (s dateBuildScheme) Match(tag string) bool {
+	_, _, ok := s.parse(tag)
+	return ok
+}
+
+func (s dateBuildScheme) Compare(a, b string) int {
+	da, ba, _ := s.parse(a)
+	db, bb, _ := s.parse(b)
+	if c := da.Compare(db); c != 0 {
+		return c
+	}
+	return sign(ba - bb)
+}
+
+// RegexScheme 返回自定义方案，re 必须匹配整个标签，compare 比较两个标签的子匹配（下标 0 为整个标签）
+//
+//	// "build-42" 按构建号排序
+//	scheme := gittag.RegexScheme(regexp.MustCompile(`^build-(\d+)$`), func(a, b []string) int {
+//		x, _ := strconv.Atoi(a[1])
+//		y, _ := strconv.Atoi(b[1])
+//		return x - y
+//	})
+func RegexScheme(re *regexp.Regexp, compare func(a, b []string) int) Scheme {
+	return regexScheme{re: re, compare: compare}
+}
+
+type regexScheme struct {
+	re      *regexp.Regexp
+	compare func(a, b []string) int
+}
+
+func (s regexScheme) submatch(tag string) []string {
+	m := s.re.FindStringSubmatch(tag)
+	if m == nil || m[0] != tag {
+		return nil
+	}
+	return m
+}
+
+func (s regexScheme) Match(tag string) bool {
+	return s.submatch(tag) != nil
+}
+
+func (s regexScheme) Compare(a, b string) int {
+	return s.compare(s.submatch(a), s.submatch(b))
+}
+
+// SortVersions 返回 tags 中按 scheme 命名的标签，从旧到新排序，不匹配的标签被忽略
+// 适合在清理旧标签时保留最新的若干个版本
+func SortVersions(tags []string, scheme Scheme) []string {
+	var matched []string
+	for _, tag := range tags {
+		if scheme.Match(tag) {
+			matched = append(matched, tag)
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		return scheme.Compare(matched[i], matched[j]) < 0
+	})
+	return matched
+}
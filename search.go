@@ -0,0 +1,156 @@
+package gittag
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// 匹配类型的基础分数，分数越高排名越靠前
+const (
+	scoreExact     = 4000
+	scorePrefix    = 3000
+	scoreSubstring = 2000
+	scoreFuzzy     = 1000
+)
+
+// Search finds tags whose names match q case-insensitively, best matches first.
+// Exact matches rank above prefix matches, then substring matches, then fuzzy matches where the
+// characters of q appear in order (e.g. "v12rc" matches "v1.2.0-rc.1").
+// @param q - 查询字符串，为空时返回所有标签
+// @return ([]string, error) - 返回按匹配程度排序的标签，没有匹配时返回空列表
+//
+// Example:
+//
+//	// Complete a tag argument in a CLI
+//	tags, err := gittag.Search("rc")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	for _, tag := range tags {
+//		fmt.Println(tag)
+//	}
+func Search(q string) ([]string, error) {
+	return Default().Search(q)
+}
+
+// Search finds tags in the repository matching q, see the package-level Search.
+func (r *Repository) Search(q string) ([]string, error) {
+	tags, err := r.FindMany("*")
+	var notFound *NotFoundError
+	if errors.As(err, &notFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	type match struct {
+		tag   string
+		score int
+	}
+	var matches []match
+	query := strings.ToLower(q)
+	for _, tag := range tags {
+		if score, ok := searchScore(strings.ToLower(tag), query); ok {
+			matches = append(matches, match{tag, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return matches[i].tag < matches[j].tag
+	})
+
+	result := make([]string, len(matches))
+	for i, m := range matches {
+		result[i] = m.tag
+	}
+	return result, nil
+}
+
+// searchScore 计算 name 与 query 的匹配分数，两者都应为小写
+func searchScore(name, query string) (int, bool) {
+	switch {
+	case query == "" || name == query:
+		return scoreExact, true
+	case strings.HasPrefix(name, query):
+		return scorePrefix - len(name), true
+	}
+	if i := strings.Index(name, query); i >= 0 {
+		return scoreSubstring - i*10 - len(name), true
+	}
+
+	// 模糊匹配：query 的字符按顺序出现在 name 中，连续命中加分，跳过的字符扣分
+	score, qi, run := scoreFuzzy, 0, 0
+	for ni := 0; ni < len(name) && qi < len(query); ni++ {
+		if name[ni] == query[qi] {
+			qi++
+			run++
+			score += run * 5
+		} else {
+			run = 0
+			score--
+		}
+	}
+	if qi < len(query) {
+		return 0, false
+	}
+	return score, true
+}
+
+// FindByMessage finds annotated tags whose message contains substr, ignoring case, so the release
+// that "mentioned the payments migration" can be located without checking anything out.
+// Lightweight tags have no message and never match.
+// @param substr - 要查找的文本，不区分大小写
+// @return ([]TagInfo, error) - 返回按名称排序的匹配标签，没有匹配时返回空列表
+//
+// Example:
+//
+//	infos, err := gittag.FindByMessage("payments migration")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	for _, info := range infos {
+//		fmt.Println(info.Name, info.Date.Format("2006-01-02"))
+//	}
+func FindByMessage(substr string) ([]TagInfo, error) {
+	return Default().FindByMessage(substr)
+}
+
+// FindByMessage finds tags in the repository by message, see the package-level FindByMessage.
+func (r *Repository) FindByMessage(substr string) ([]TagInfo, error) {
+	query := strings.ToLower(substr)
+	return r.filterInfo(func(info TagInfo) bool {
+		return strings.Contains(strings.ToLower(info.Message), query)
+	})
+}
+
+// FindByMessageRegexp 查找信息匹配正则表达式 re 的附注标签，参见 FindByMessage
+func FindByMessageRegexp(re *regexp.Regexp) ([]TagInfo, error) {
+	return Default().FindByMessageRegexp(re)
+}
+
+// FindByMessageRegexp finds tags in the repository by message, see the package-level FindByMessageRegexp.
+func (r *Repository) FindByMessageRegexp(re *regexp.Regexp) ([]TagInfo, error) {
+	return r.filterInfo(func(info TagInfo) bool {
+		return re.MatchString(info.Message)
+	})
+}
+
+// filterInfo 返回满足 keep 的附注标签
+func (r *Repository) filterInfo(keep func(TagInfo) bool) ([]TagInfo, error) {
+	infos, err := r.ListInfo("*")
+	if err != nil {
+		return nil, err
+	}
+	var matches []TagInfo
+	for _, info := range infos {
+		if info.Annotated && keep(info) {
+			matches = append(matches, info)
+		}
+	}
+	return matches, nil
+}
@@ -0,0 +1,110 @@
+package gittag
+
+import (
+	"strconv"
+	"strings"
+)
+
+// semver 是解析后的语义化版本，前缀 "v" 是可选的
+type semver struct {
+	major, minor, patch int
+	prerelease          []string
+	build               string
+}
+
+// parseSemver 解析形如 "v1.2.3"、"1.2.3-rc.1+build.5" 的标签，不是语义化版本时返回 false
+func parseSemver(tag string) (semver, bool) {
+	s := strings.TrimPrefix(tag, "v")
+	var v semver
+	if i := strings.IndexByte(s, '+'); i >= 0 {
+		v.build = s[i+1:]
+		s = s[:i]
+		if v.build == "" {
+			return semver{}, false
+		}
+	}
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		pre := s[i+1:]
+		s = s[:i]
+		if pre == "" {
+			return semver{}, false
+		}
+		v.prerelease = strings.Split(pre, ".")
+		for _, id := range v.prerelease {
+			if id == "" {
+				return semver{}, false
+			}
+		}
+	}
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 {
+		return semver{}, false
+	}
+	nums := make([]int, 3)
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 || part[0] == '+' || (len(part) > 1 && part[0] == '0') {
+			return semver{}, false
+		}
+		nums[i] = n
+	}
+	v.major, v.minor, v.patch = nums[0], nums[1], nums[2]
+	return v, true
+}
+
+// parseLooseSemver 先用 Normalize 规范化 tag 再解析，"v1.2"、"v01.2.3" 这类旧写法也能参与比较
+func parseLooseSemver(tag string) (semver, bool) {
+	canonical, err := Normalize(tag, WithCanonicalPrefix(""))
+	if err != nil {
+		return semver{}, false
+	}
+	return parseSemver(canonical)
+}
+
+// compareSemver 按语义化版本规则比较 a 和 b，返回 -1、0 或 1，构建元数据不参与比较
+func compareSemver(a, b semver) int {
+	for _, d := range []int{a.major - b.major, a.minor - b.minor, a.patch - b.patch} {
+		if d != 0 {
+			return sign(d)
+		}
+	}
+	switch {
+	case len(a.prerelease) == 0 && len(b.prerelease) == 0:
+		return 0
+	case len(a.prerelease) == 0:
+		return 1
+	case len(b.prerelease) == 0:
+		return -1
+	}
+	for i := 0; i < len(a.prerelease) && i < len(b.prerelease); i++ {
+		if c := comparePrereleaseID(a.prerelease[i], b.prerelease[i]); c != 0 {
+			return c
+		}
+	}
+	return sign(len(a.prerelease) - len(b.prerelease))
+}
+
+// comparePrereleaseID 比较预发布版本的单个标识符，数字标识符低于字母标识符
+func comparePrereleaseID(a, b string) int {
+	an, aErr := strconv.Atoi(a)
+	bn, bErr := strconv.Atoi(b)
+	switch {
+	case aErr == nil && bErr == nil:
+		return sign(an - bn)
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,73 @@
+package gittag
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// SeriesOf returns the minor release series a tag belongs to: the name up to and including the
+// minor version, so both "v1.4.0" and "v1.4.2" belong to "v1.4" and "api/v2.0.1" to "api/v2.0".
+// @param tag - 标签名称，例如："v1.4.2"
+// @return string - 返回系列名称，标签不以语义化版本结尾时返回空字符串
+//
+// Example:
+//
+//	fmt.Println(gittag.SeriesOf("v1.4.2")) // v1.4
+func SeriesOf(tag string) string {
+	prefix, v, ok := splitVersionTag(tag)
+	if !ok {
+		return ""
+	}
+	return prefix + strconv.Itoa(v.major) + "." + strconv.Itoa(v.minor)
+}
+
+// IsLatest reports whether tag is the highest release of its series, see SeriesOf, so deployment
+// tooling can show "v1.4.2 (latest in v1.4)" or warn before deploying a superseded patch.
+// Prereleases of other channels are ignored: a stable tag is compared with stable releases only,
+// and "v1.4.3-rc.2" with stable releases and other "rc" prereleases.
+// @param tag - 已存在的标签名称，例如："v1.4.2"
+// @return (bool, error) - 返回是否为系列中的最新版本，标签不存在时返回 *NotFoundError
+//
+// Example:
+//
+//	latest, err := gittag.IsLatest("v1.4.2")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	if !latest {
+//		log.Printf("warning: v1.4.2 is superseded in %s", gittag.SeriesOf("v1.4.2"))
+//	}
+func IsLatest(tag string) (bool, error) {
+	return Default().IsLatest(tag)
+}
+
+// IsLatest reports whether tag is the newest release of its series in the repository, see the package-level IsLatest.
+func (r *Repository) IsLatest(tag string) (bool, error) {
+	series := SeriesOf(tag)
+	if series == "" {
+		return false, fmt.Errorf("标签 %q 不是语义化版本", tag)
+	}
+	if _, err := r.revParse(r.baseContext(), tagRef(r.tagName(tag))); err != nil {
+		return false, &NotFoundError{Pattern: tag}
+	}
+	prefix, current, _ := splitVersionTag(tag)
+	cfg := &previousConfig{}
+	if len(current.prerelease) > 0 {
+		cfg.channel = current.prerelease[0]
+	}
+
+	candidates, err := r.FindMany(series + ".*")
+	if err != nil {
+		return false, err
+	}
+	for _, candidate := range candidates {
+		p, v, ok := splitVersionTag(candidate)
+		if !ok || p != prefix || v.major != current.major || v.minor != current.minor || !cfg.accepts(v) {
+			continue
+		}
+		if compareSemver(v, current) > 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
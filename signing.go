@@ -0,0 +1,229 @@
+package gittag
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SigningPreflight checks that signed tags can be created with the configured key before a
+// release is attempted, turning git's opaque "gpg failed to sign the data" into actionable errors.
+// It honours gpg.format (openpgp, ssh or x509), user.signingkey and the gpg.*.program settings,
+// and checks that the key exists, has not expired and that the signing agent is reachable.
+// @return error - 如果无法签名，返回描述第一个问题及修复方法的错误，否则返回 nil
+//
+// Example:
+//
+//	if err := gittag.SigningPreflight(); err != nil {
+//		log.Fatalf("cannot sign release tags: %v", err)
+//	}
+func SigningPreflight() error {
+	return Default().SigningPreflight()
+}
+
+// SigningPreflight checks the signing setup of the repository, see the package-level SigningPreflight.
+func (r *Repository) SigningPreflight() error {
+	ctx := r.baseContext()
+	key := r.configValue(ctx, "user.signingkey")
+	switch format := r.configValue(ctx, "gpg.format"); format {
+	case "", "openpgp":
+		return r.openpgpPreflight(ctx, key)
+	case "ssh":
+		return r.sshPreflight(ctx, key)
+	case "x509":
+		return r.x509Preflight(ctx, key)
+	default:
+		return fmt.Errorf("不支持的 gpg.format %q，可选值为 openpgp、ssh、x509", format)
+	}
+}
+
+// minSSHSigningGitVersion 是支持 gpg.format=ssh 的最低 git 版本
+const minSSHSigningGitVersion = "2.34.0"
+
+// gitVersion 返回 git 的版本号，例如 "2.39.5"，无法识别时返回空字符串
+func (r *Repository) gitVersion(ctx context.Context) string {
+	output, err := r.output(ctx, "version")
+	if err != nil {
+		return ""
+	}
+	return gitVersionPattern.FindString(string(output))
+}
+
+// expandHome 将 "~/" 开头的路径展开为用户主目录下的路径
+func expandHome(path string) string {
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[2:])
+		}
+	}
+	return path
+}
+
+// configValue 读取 git 配置，不存在时返回空字符串
+func (r *Repository) configValue(ctx context.Context, key string) string {
+	output, err := r.output(ctx, "config", "--get", key)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// signingProgram 返回签名程序，依次读取 gpg.<format>.program、gpg.program（仅 openpgp）和默认值
+func (r *Repository) signingProgram(ctx context.Context, format, fallback string) (string, error) {
+	program := r.configValue(ctx, "gpg."+format+".program")
+	if program == "" && format == "openpgp" {
+		program = r.configValue(ctx, "gpg.program")
+	}
+	if program == "" {
+		program = fallback
+	}
+	path, err := exec.LookPath(program)
+	if err != nil {
+		return "", fmt.Errorf("找不到签名程序 %s，请安装或设置 gpg.%s.program: %w", program, format, err)
+	}
+	return path, nil
+}
+
+// tool 在仓库目录中执行外部程序，使用与 git 命令相同的环境变量
+func (r *Repository) tool(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return r.toolInput(ctx, nil, name, args...)
+}
+
+// toolInput 与 tool 相同，但从 stdin 读取标准输入
+func (r *Repository) toolInput(ctx context.Context, stdin io.Reader, name string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.WaitDelay = commandWaitDelay
+	cmd.Dir = r.dir
+	cmd.Stdin = stdin
+	cmd.Env = append(append(os.Environ(), defaultEnv...), r.env...)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	flush := r.stream(cmd)
+	start := time.Now()
+	err := cmd.Run()
+	flush()
+	r.record(cmd, start, err)
+	return output.Bytes(), redactError(err)
+}
+
+func (r *Repository) openpgpPreflight(ctx context.Context, key string) error {
+	program, err := r.signingProgram(ctx, "openpgp", "gpg")
+	if err != nil {
+		return err
+	}
+	if key == "" {
+		ident, err := r.output(ctx, "var", "GIT_COMMITTER_IDENT")
+		start, end := strings.IndexByte(string(ident), '<'), strings.IndexByte(string(ident), '>')
+		if err != nil || start < 0 || end < start {
+			return errors.New("未设置 user.signingkey，且无法确定提交者邮箱，请运行 git config user.signingkey <key-id>")
+		}
+		key = string(ident[start+1 : end])
+	}
+
+	output, err := r.tool(ctx, program, "--batch", "--with-colons", "--list-secret-keys", "--", key)
+	if err != nil {
+		return fmt.Errorf("找不到签名密钥 %s 的私钥，请用 gpg --list-secret-keys 检查或设置 user.signingkey: %w", key, err)
+	}
+	if !hasUsableSigningKey(string(output), time.Now()) {
+		return fmt.Errorf("签名密钥 %s 已过期、被吊销或没有可用于签名的子密钥", key)
+	}
+
+	if agent, err := exec.LookPath(filepath.Join(filepath.Dir(program), "gpg-connect-agent")); err == nil {
+		if _, err := r.tool(ctx, agent, "/bye"); err != nil {
+			return fmt.Errorf("无法连接 gpg-agent，请检查 GNUPGHOME 和 gpg-agent 是否在运行: %w", err)
+		}
+	}
+	return nil
+}
+
+// hasUsableSigningKey 解析 gpg --with-colons 的输出，判断是否存在未过期且可签名的主密钥或子密钥
+func hasUsableSigningKey(colons string, now time.Time) bool {
+	for _, line := range strings.Split(colons, "\n") {
+		fields := strings.Split(line, ":")
+		if len(fields) < 12 || (fields[0] != "sec" && fields[0] != "ssb") {
+			continue
+		}
+		if strings.ContainsAny(fields[1], "erdi") || !strings.ContainsAny(fields[11], "sS") {
+			continue
+		}
+		if fields[6] != "" {
+			if expires, err := strconv.ParseInt(fields[6], 10, 64); err == nil && time.Unix(expires, 0).Before(now) {
+				continue
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func (r *Repository) sshPreflight(ctx context.Context, key string) error {
+	if version := r.gitVersion(ctx); version != "" && SemVer.Compare("v"+version, "v"+minSSHSigningGitVersion) < 0 {
+		return fmt.Errorf("git %s 不支持 SSH 签名，请升级到 %s 或更高版本", version, minSSHSigningGitVersion)
+	}
+	if _, err := r.signingProgram(ctx, "ssh", "ssh-keygen"); err != nil {
+		return err
+	}
+	if key == "" {
+		if r.configValue(ctx, "gpg.ssh.defaultKeyCommand") != "" {
+			return nil
+		}
+		return errors.New("gpg.format=ssh 但未设置 user.signingkey，请设置为公钥或私钥文件路径")
+	}
+
+	publicKey := ""
+	switch {
+	case strings.HasPrefix(key, "key::"):
+		publicKey = strings.TrimPrefix(key, "key::")
+	case strings.HasPrefix(key, "ssh-") || strings.HasPrefix(key, "ecdsa-") || strings.HasPrefix(key, "sk-"):
+		publicKey = key
+	default:
+		path := expandHome(key)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("无法读取 user.signingkey 指定的 SSH 密钥 %s: %w", path, err)
+		}
+		if !strings.HasSuffix(path, ".pub") {
+			return nil
+		}
+		if _, err := os.Stat(strings.TrimSuffix(path, ".pub")); err == nil {
+			return nil
+		}
+		publicKey = strings.TrimSpace(string(data))
+	}
+
+	// 只有公钥时，私钥必须在 ssh-agent 中
+	agentKeys, err := r.tool(ctx, "ssh-add", "-L")
+	if err != nil {
+		return fmt.Errorf("无法从 ssh-agent 读取密钥，请检查 SSH_AUTH_SOCK 并用 ssh-add 加载私钥: %w", err)
+	}
+	fields := strings.Fields(publicKey)
+	if len(fields) < 2 || !strings.Contains(string(agentKeys), fields[1]) {
+		return errors.New("ssh-agent 中没有 user.signingkey 对应的私钥，请用 ssh-add 加载")
+	}
+	return nil
+}
+
+func (r *Repository) x509Preflight(ctx context.Context, key string) error {
+	program, err := r.signingProgram(ctx, "x509", "gpgsm")
+	if err != nil {
+		return err
+	}
+	args := []string{"--batch", "--with-colons", "--list-secret-keys"}
+	if key != "" {
+		args = append(args, "--", key)
+	}
+	output, err := r.tool(ctx, program, args...)
+	if err != nil || !strings.Contains(string(output), "crs:") {
+		return fmt.Errorf("找不到可用的 X.509 签名证书，请用 gpgsm --list-secret-keys 检查: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+package gittag
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TagSnapshot 是某一时刻仓库中所有标签指向的对象，可以序列化为 JSON 保存，供之后用 DiffSnapshots 比较
+type TagSnapshot struct {
+	// Taken 是拍摄快照的时间
+	Taken time.Time `json:"taken"`
+	// Tags 是标签名到其指向对象 SHA 的映射，附注标签为标签对象的 SHA
+	Tags map[string]string `json:"tags"`
+}
+
+// Snapshot captures the name and object SHA of every local tag, so monitoring jobs can store the
+// tag set periodically and compare it later with DiffSnapshots. Annotated tags are recorded by
+// their tag object SHA, so rewriting a tag's message or tagger shows up as a move too.
+// @return (*TagSnapshot, error) - 返回当前的标签快照
+//
+// Example:
+//
+//	previous := loadSnapshot() // e.g. from the last run, stored as JSON
+//	current, err := gittag.Snapshot()
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	for _, e := range gittag.DiffSnapshots(previous, current) {
+//		if e.Type != gittag.TagCreated {
+//			alert("release tag %s was %s", e.Name, e.Type)
+//		}
+//	}
+func Snapshot() (*TagSnapshot, error) {
+	return Default().Snapshot()
+}
+
+// Snapshot captures the repository's tag set, see the package-level Snapshot.
+func (r *Repository) Snapshot() (*TagSnapshot, error) {
+	taken := time.Now()
+	tags, err := r.tagRefs(r.baseContext())
+	if err != nil {
+		return nil, err
+	}
+	return &TagSnapshot{Taken: taken, Tags: tags}, nil
+}
+
+// DiffSnapshots 按标签名顺序返回从快照 a 到快照 b 的变化：新建、删除和移动（指向了其他对象）
+// a 或 b 为 nil 时视为空快照
+func DiffSnapshots(a, b *TagSnapshot) []TagEvent {
+	var before, after map[string]string
+	if a != nil {
+		before = a.Tags
+	}
+	if b != nil {
+		after = b.Tags
+	}
+	return diffTagRefs(before, after)
+}
+
+// snapshotNamespace 是快照签名的 ssh-keygen 命名空间，避免签名被当作其他用途的签名重放
+const snapshotNamespace = "gittag-snapshot"
+
+// SignedSnapshot 是带 SSH 签名的标签快照，可以序列化为 JSON 保存在仓库之外
+type SignedSnapshot struct {
+	TagSnapshot
+	// Signature 是 ssh-keygen -Y sign 对快照内容的签名
+	Signature string `json:"signature"`
+}
+
+// SnapshotError 描述 VerifySnapshot 发现的在快照之后被删除或移动的标签
+type SnapshotError struct {
+	Taken   time.Time
+	Changes []TagEvent
+}
+
+func (e *SnapshotError) Error() string {
+	lines := make([]string, len(e.Changes))
+	for i, c := range e.Changes {
+		switch c.Type {
+		case TagDeleted:
+			lines[i] = fmt.Sprintf("%s: 标签已被删除（原为 %s）", c.Name, c.OldObject)
+		default:
+			lines[i] = fmt.Sprintf("%s: 标签从 %s 变为 %s", c.Name, c.OldObject, c.Object)
+		}
+	}
+	return fmt.Sprintf("%s 的标签快照之后有 %d 个标签被改写:\n  %s",
+		e.Taken.Format(time.RFC3339), len(e.Changes), strings.Join(lines, "\n  "))
+}
+
+// SignSnapshot takes a Snapshot and signs the complete tag→SHA mapping with an SSH key, producing
+// a tamper-evident record that can be stored outside the repository. VerifySnapshot later proves
+// the record is authentic and that no tag it contains was deleted or moved since — a lightweight
+// transparency log for security-sensitive release refs.
+// @param key - SSH 私钥文件路径，"~/" 开头时展开为用户主目录，例如："~/.ssh/release_ed25519"
+// @return (*SignedSnapshot, error) - 返回签名的快照
+//
+// Example:
+//
+//	signed, err := gittag.SignSnapshot("/secrets/attest_ed25519")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	data, _ := json.Marshal(signed)
+//	os.WriteFile("attestations/"+signed.Taken.Format("20060102")+".json", data, 0o644)
+func SignSnapshot(key string) (*SignedSnapshot, error) {
+	return Default().SignSnapshot(key)
+}
+
+// SignSnapshot signs a snapshot of the repository's tags, see the package-level SignSnapshot.
+func (r *Repository) SignSnapshot(key string) (*SignedSnapshot, error) {
+	ctx := r.baseContext()
+	program, err := r.signingProgram(ctx, "ssh", "ssh-keygen")
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := r.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "gittag-snapshot-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	payload := filepath.Join(dir, "snapshot")
+	if err := os.WriteFile(payload, snapshot.payload(), 0o600); err != nil {
+		return nil, err
+	}
+	if output, err := r.tool(ctx, program, "-Y", "sign", "-n", snapshotNamespace, "-f", expandHome(key), payload); err != nil {
+		return nil, fmt.Errorf("签名快照失败: %s: %w", strings.TrimSpace(string(output)), err)
+	}
+	signature, err := os.ReadFile(payload + ".sig")
+	if err != nil {
+		return nil, fmt.Errorf("签名快照失败: %w", err)
+	}
+	return &SignedSnapshot{TagSnapshot: *snapshot, Signature: string(signature)}, nil
+}
+
+// VerifySnapshot checks that signed was signed by a key trusted by policy and that every tag it
+// records still points at the same object. Tags created after the snapshot are not reported.
+// @param signed - SignSnapshot 生成的快照
+// @param policy - 信任策略，Fingerprints 中的 SSH 指纹或 AllowedSignersFile 中的密钥可信
+// @return error - 签名无效或不可信时返回错误，标签被删除或移动时返回 *SnapshotError
+//
+// Example:
+//
+//	err := gittag.VerifySnapshot(signed, gittag.TrustPolicy{AllowedSignersFile: "allowed_signers"})
+//	var rewritten *gittag.SnapshotError
+//	if errors.As(err, &rewritten) {
+//		alert("release tags rewritten: %v", rewritten.Changes)
+//	}
+func VerifySnapshot(signed *SignedSnapshot, policy TrustPolicy) error {
+	return Default().VerifySnapshot(signed, policy)
+}
+
+// VerifySnapshot verifies a signed snapshot against the repository, see the package-level VerifySnapshot.
+func (r *Repository) VerifySnapshot(signed *SignedSnapshot, policy TrustPolicy) error {
+	if err := r.verifySnapshotSignature(signed, policy); err != nil {
+		return err
+	}
+	current, err := r.Snapshot()
+	if err != nil {
+		return err
+	}
+	var changes []TagEvent
+	for _, event := range DiffSnapshots(&signed.TagSnapshot, current) {
+		if event.Type != TagCreated {
+			changes = append(changes, event)
+		}
+	}
+	if len(changes) > 0 {
+		return &SnapshotError{Taken: signed.Taken, Changes: changes}
+	}
+	return nil
+}
+
+// snapshotGoodSignature 匹配 ssh-keygen 校验快照签名成功时的输出
+var snapshotGoodSignature = regexp.MustCompile(`Good "` + snapshotNamespace + `" signature(?: for \S+)? with \S+ key (\S+)`)
+
+// verifySnapshotSignature 校验快照签名有效且签名密钥满足 policy
+func (r *Repository) verifySnapshotSignature(signed *SignedSnapshot, policy TrustPolicy) error {
+	if signed == nil || signed.Signature == "" {
+		return errors.New("快照没有签名")
+	}
+	ctx := r.baseContext()
+	program, err := r.signingProgram(ctx, "ssh", "ssh-keygen")
+	if err != nil {
+		return err
+	}
+	dir, err := os.MkdirTemp("", "gittag-snapshot-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	sigFile := filepath.Join(dir, "snapshot.sig")
+	if err := os.WriteFile(sigFile, []byte(signed.Signature), 0o600); err != nil {
+		return err
+	}
+	payload := signed.payload()
+
+	output, err := r.toolInput(ctx, bytes.NewReader(payload), program,
+		"-Y", "check-novalidate", "-n", snapshotNamespace, "-s", sigFile)
+	m := snapshotGoodSignature.FindStringSubmatch(string(output))
+	if err != nil || m == nil {
+		return fmt.Errorf("快照签名无效，内容可能已被篡改: %s", strings.TrimSpace(string(output)))
+	}
+	sig := &Signature{Signed: true, Valid: true, Format: "ssh", Fingerprint: m[1], PrimaryFingerprint: m[1]}
+	if policy.AllowedSignersFile != "" {
+		principals, err := r.tool(ctx, program, "-Y", "find-principals", "-f", policy.AllowedSignersFile, "-s", sigFile)
+		if principal, _, _ := strings.Cut(strings.TrimSpace(string(principals)), "\n"); err == nil && principal != "" {
+			if _, err := r.toolInput(ctx, bytes.NewReader(payload), program,
+				"-Y", "verify", "-f", policy.AllowedSignersFile, "-I", principal, "-n", snapshotNamespace, "-s", sigFile); err == nil {
+				sig.Signer = principal
+			}
+		}
+	}
+	if !policy.Trusts(sig) {
+		return fmt.Errorf("快照的签名密钥 %s 不可信", sig.Fingerprint)
+	}
+	return nil
+}
+
+// payload 返回被签名的快照内容：时间和按名称排序的 "<SHA> <标签名>" 行
+func (s *TagSnapshot) payload() []byte {
+	names := make([]string, 0, len(s.Tags))
+	for name := range s.Tags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%s v1\ntaken %s\n", snapshotNamespace, s.Taken.UTC().Format(time.RFC3339Nano))
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s %s\n", s.Tags[name], name)
+	}
+	return b.Bytes()
+}
@@ -0,0 +1,191 @@
+package gittag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Store keeps the package's persistent state — the idempotency journal, the TagInfo cache and
+// the progress of resumable releases — as opaque blobs under slash-separated keys such as
+// "releases/run-1.json". By default the state lives in the repository's .git/gittag directory;
+// server deployments can use WithStore to keep it elsewhere, e.g. in memory for tests or in
+// Redis or SQL by implementing this interface.
+type Store interface {
+	// Get 返回 key 对应的数据，key 不存在时返回包装了 fs.ErrNotExist 的错误
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Put 保存 key 对应的数据，读取方不能看到写了一半的数据
+	Put(ctx context.Context, key string, data []byte) error
+	// Delete 删除 key，key 不存在时不视为错误
+	Delete(ctx context.Context, key string) error
+}
+
+// WithStore 指定保存幂等记录、TagInfo 缓存和发布进度的 Store，默认使用 .git/gittag 目录
+// 多个仓库共享同一个 Store 时使用 PrefixStore 为每个仓库分配独立的前缀
+// 仓库锁仍是本机的文件锁，多台机器共享 Store 时需要由调用方保证同一仓库不会被并发修改
+func WithStore(s Store) Option {
+	return func(r *Repository) {
+		r.store = s
+	}
+}
+
+// stateStore 返回仓库使用的 Store，未指定时为 .git/gittag 目录
+func (r *Repository) stateStore() (Store, error) {
+	if r.store != nil {
+		return r.store, nil
+	}
+	gitDir, err := r.gitCommonDir()
+	if err != nil {
+		return nil, err
+	}
+	return NewFileStore(filepath.Join(gitDir, "gittag")), nil
+}
+
+// FileStore 将每个键保存为 dir 下的一个文件，键中的 "/" 对应子目录
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore 创建保存在 dir 目录下的 Store，目录不存在时在第一次写入时创建
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{dir: dir}
+}
+
+// Get 读取 key 对应的文件
+func (s *FileStore) Get(ctx context.Context, key string) ([]byte, error) {
+	file, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(file)
+}
+
+// Put 原子地写入 key 对应的文件
+func (s *FileStore) Put(ctx context.Context, key string, data []byte) error {
+	file, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(file, data)
+}
+
+// Delete 删除 key 对应的文件
+func (s *FileStore) Delete(ctx context.Context, key string) error {
+	file, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(file); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// path 返回 key 对应的文件路径，拒绝会逃出 dir 的键
+func (s *FileStore) path(key string) (string, error) {
+	if err := checkStoreKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
+
+// MemoryStore 是保存在内存中的 Store，适用于测试和不需要在进程间保留状态的服务，可以被多个 goroutine 同时使用
+type MemoryStore struct {
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+// NewMemoryStore 创建一个空的 MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{data: make(map[string][]byte)}
+}
+
+// Get 返回 key 对应数据的副本
+func (s *MemoryStore) Get(ctx context.Context, key string) ([]byte, error) {
+	if err := checkStoreKey(key); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.data[key]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", key, fs.ErrNotExist)
+	}
+	return append([]byte(nil), data...), nil
+}
+
+// Put 保存 data 的副本
+func (s *MemoryStore) Put(ctx context.Context, key string, data []byte) error {
+	if err := checkStoreKey(key); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[key] = append([]byte(nil), data...)
+	return nil
+}
+
+// Delete 删除 key
+func (s *MemoryStore) Delete(ctx context.Context, key string) error {
+	if err := checkStoreKey(key); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.data, key)
+	return nil
+}
+
+// Keys 返回所有键，按名称排序
+func (s *MemoryStore) Keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]string, 0, len(s.data))
+	for key := range s.data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// PrefixStore 返回在 s 的每个键前加上 prefix + "/" 的 Store，用于多个仓库共享同一个后端
+//
+// Example:
+//
+//	shared := NewRedisStore(client) // 实现了 gittag.Store
+//	repo, err := gittag.Open(dir, gittag.WithStore(gittag.PrefixStore(shared, "repos/app")))
+func PrefixStore(s Store, prefix string) Store {
+	return prefixStore{store: s, prefix: strings.Trim(prefix, "/")}
+}
+
+type prefixStore struct {
+	store  Store
+	prefix string
+}
+
+func (s prefixStore) Get(ctx context.Context, key string) ([]byte, error) {
+	return s.store.Get(ctx, path.Join(s.prefix, key))
+}
+
+func (s prefixStore) Put(ctx context.Context, key string, data []byte) error {
+	return s.store.Put(ctx, path.Join(s.prefix, key), data)
+}
+
+func (s prefixStore) Delete(ctx context.Context, key string) error {
+	return s.store.Delete(ctx, path.Join(s.prefix, key))
+}
+
+// checkStoreKey 要求键是非空的、规范的相对路径
+func checkStoreKey(key string) error {
+	if key == "" || path.IsAbs(key) || path.Clean(key) != key || key == ".." || strings.HasPrefix(key, "../") {
+		return fmt.Errorf("无效的存储键 %q", key)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package gittag
+
+// Strategy 决定 FindOne 在多个标签匹配时返回哪一个
+type Strategy int
+
+const (
+	// FirstMatch 返回 git 按名称排序后的第一个标签，这是默认行为
+	FirstMatch Strategy = iota
+	// HighestVersion 返回版本最高的标签，忽略不符合版本方案的标签，方案默认为 SemVer，见 WithScheme
+	HighestVersion
+	// MostRecent 返回创建时间最晚的标签
+	MostRecent
+	// ExactOnly 只在存在与模式完全相同的标签时返回它
+	ExactOnly
+)
+
+// SortOrder 决定 FindMany 返回的标签顺序
+type SortOrder int
+
+const (
+	// SortByName 按名称排序，这是默认行为
+	SortByName SortOrder = iota
+	// SortByVersion 按版本从低到高排序，方案默认为 SemVer，见 WithScheme；不符合方案的标签按名称排在最后
+	SortByVersion
+	// SortByVersionDesc 按版本从高到低排序，不符合方案的标签按名称排在最后
+	SortByVersionDesc
+	// SortByCreated 按创建时间从早到晚排序
+	SortByCreated
+	// SortByCreatedDesc 按创建时间从晚到早排序
+	SortByCreatedDesc
+)
+
+type findConfig struct {
+	strategy Strategy
+	scheme   Scheme
+	sort     SortOrder
+
+	// limit 和 after 用于分页，见 WithLimit 和 WithAfter
+	limit int
+	after string
+}
+
+// FindOption 用于配置 FindOne 和 FindMany 的行为
+type FindOption func(*findConfig)
+
+// WithStrategy 指定 FindOne 的选择策略
+func WithStrategy(strategy Strategy) FindOption {
+	return func(c *findConfig) {
+		c.strategy = strategy
+	}
+}
+
+// WithSort 指定 FindMany 返回的标签顺序，FindOne 的默认策略 FirstMatch 返回排序后的第一个标签
+func WithSort(order SortOrder) FindOption {
+	return func(c *findConfig) {
+		c.sort = order
+	}
+}
+
+// WithScheme 指定 HighestVersion、SortByVersion 和 SortByVersionDesc 使用的版本方案，例如 CalVer("RELEASE_2006_01_02")，
+// 默认为 WithVersionScheme 指定的仓库方案
+func WithScheme(scheme Scheme) FindOption {
+	return func(c *findConfig) {
+		c.scheme = scheme
+	}
+}
+
+// WithLimit 限制 FindMany 最多返回 n 个标签，n 小于等于 0 时不限制，与 WithAfter 一起用于分页
+func WithLimit(n int) FindOption {
+	return func(c *findConfig) {
+		c.limit = n
+	}
+}
+
+// WithAfter 让 FindMany 只返回排在 tag 之后的标签，tag 通常是上一页的最后一个标签
+// 按名称排序时游标是稳定的：分页期间创建或删除标签（包括 tag 本身）不会导致重复或遗漏已存在的标签；
+// 按其他顺序排序时 tag 必须仍然存在，否则返回错误
+//
+// Example:
+//
+//	after := ""
+//	for {
+//		page, err := repo.FindMany("*", gittag.WithLimit(500), gittag.WithAfter(after))
+//		if err != nil || len(page) == 0 {
+//			break
+//		}
+//		process(page)
+//		after = page[len(page)-1]
+//	}
+func WithAfter(tag string) FindOption {
+	return func(c *findConfig) {
+		c.after = tag
+	}
+}
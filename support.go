@@ -0,0 +1,229 @@
+package gittag
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// eolDateLayout 是 git 配置中 EOL 日期的格式
+const eolDateLayout = "2006-01-02"
+
+// SupportWindow 描述一个发布系列的支持期限
+type SupportWindow struct {
+	// Series 是系列的匹配模式，例如："v1.*"
+	Series string `json:"series"`
+	// EOL 是停止支持的日期，当天结束前仍视为受支持
+	EOL time.Time `json:"eol"`
+}
+
+// Supported 报告该系列在 t 时是否仍受支持
+func (w SupportWindow) Supported(t time.Time) bool {
+	return t.Before(w.EOL.AddDate(0, 0, 1))
+}
+
+// SetEOL records the end-of-life date of a release series in the repository's local git config,
+// e.g. [gittag "v1.*"] eol = 2025-06-30, so the support matrix travels with the repository.
+// @param series - 系列的匹配模式，语法同 path.Match，例如："v1.*"
+// @param eol - 停止支持的日期，只保留年月日
+// @return error - 如果模式无效或写入配置失败，返回相应的错误信息
+//
+// Example:
+//
+//	eol := time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)
+//	if err := gittag.SetEOL("v1.*", eol); err != nil {
+//		log.Fatal(err)
+//	}
+func SetEOL(series string, eol time.Time) error {
+	return Default().SetEOL(series, eol)
+}
+
+// SetEOL records a series' end-of-life date in the repository, see the package-level SetEOL.
+func (r *Repository) SetEOL(series string, eol time.Time) error {
+	if _, err := path.Match(series, ""); err != nil {
+		return fmt.Errorf("无效的系列模式 %q: %w", series, err)
+	}
+	key := "gittag." + series + ".eol"
+	if err := r.run(r.baseContext(), "config", "--local", key, eol.Format(eolDateLayout)); err != nil {
+		return fmt.Errorf("设置系列 %s 的 EOL 失败: %w", series, err)
+	}
+	return nil
+}
+
+// SupportMatrix 返回所有配置了 EOL 的系列，按 EOL 日期排序
+func SupportMatrix() ([]SupportWindow, error) {
+	return Default().SupportMatrix()
+}
+
+// SupportMatrix reads the repository's support matrix, see the package-level SupportMatrix.
+func (r *Repository) SupportMatrix() ([]SupportWindow, error) {
+	output, err := r.output(r.baseContext(), "config", "--get-regexp", `^gittag\..*\.eol$`)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return nil, nil // 没有配置任何 EOL
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取支持矩阵失败: %w", err)
+	}
+
+	var windows []SupportWindow
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		key, value, _ := strings.Cut(line, " ")
+		series := strings.TrimSuffix(strings.TrimPrefix(key, "gittag."), ".eol")
+		eol, err := time.ParseInLocation(eolDateLayout, value, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("系列 %s 的 EOL 日期 %q 无效，应为 YYYY-MM-DD", series, value)
+		}
+		windows = append(windows, SupportWindow{Series: series, EOL: eol})
+	}
+	sort.SliceStable(windows, func(i, j int) bool {
+		return windows[i].EOL.Before(windows[j].EOL)
+	})
+	return windows, nil
+}
+
+// SupportedSeries returns the series whose end-of-life date has not passed yet. Tooling that
+// prunes tags should never delete tags of these series; the pattern-based deletes
+// (DeleteLocalAll, DeleteRemoteAll, DeleteRemoteByPattern, DeleteAllTags) skip matching tags that
+// belong to one and delete the rest. Single tags can still be deleted by name.
+// @return ([]string, error) - 返回仍受支持的系列模式
+//
+// Example:
+//
+//	series, err := gittag.SupportedSeries()
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	fmt.Println(series) // [v2.* v3.*]
+func SupportedSeries() ([]string, error) {
+	return Default().SupportedSeries()
+}
+
+// SupportedSeries lists the repository's supported series, see the package-level SupportedSeries.
+func (r *Repository) SupportedSeries() ([]string, error) {
+	windows, err := r.SupportMatrix()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	var series []string
+	for _, w := range windows {
+		if w.Supported(now) {
+			series = append(series, w.Series)
+		}
+	}
+	return series, nil
+}
+
+// PastEOL 返回匹配 pattern 且所属系列已过 EOL 的标签，没有配置 EOL 的标签视为受支持，
+// 仓库指定了 WithVersionScheme 时只返回按该方案命名的标签
+func PastEOL(pattern string) ([]string, error) {
+	return Default().PastEOL(pattern)
+}
+
+// PastEOL lists the repository's tags whose series reached end of life, see the package-level PastEOL.
+func (r *Repository) PastEOL(pattern string) ([]string, error) {
+	stats, err := r.Stats(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return stats.PastEOL, nil
+}
+
+// TagStats 按支持状态统计匹配模式的标签
+type TagStats struct {
+	// Total 是匹配的标签数
+	Total int `json:"total"`
+	// Supported 是属于仍受支持系列的标签数
+	Supported int `json:"supported"`
+	// Unmanaged 是不属于任何配置了 EOL 的系列的标签数
+	Unmanaged int `json:"unmanaged"`
+	// PastEOL 是所属系列已过 EOL 的标签，按名称排序
+	PastEOL []string `json:"pastEOL,omitempty"`
+}
+
+// Stats counts the tags matching pattern by support status and flags the releases whose series
+// reached end of life (see SetEOL), so dashboards and audits can spot releases still in use
+// after their EOL. With WithVersionScheme only tags named by that scheme count as releases.
+// @param pattern - 标签匹配模式，例如："v*"
+// @return (*TagStats, error) - 返回统计结果，没有匹配的标签时返回零值统计
+//
+// Example:
+//
+//	stats, err := gittag.Stats("v*")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	for _, tag := range stats.PastEOL {
+//		log.Printf("%s is past end of life", tag)
+//	}
+func Stats(pattern string) (*TagStats, error) {
+	return Default().Stats(pattern)
+}
+
+// Stats counts the repository's tags by support status, see the package-level Stats.
+func (r *Repository) Stats(pattern string) (*TagStats, error) {
+	windows, err := r.SupportMatrix()
+	if err != nil {
+		return nil, err
+	}
+	tags, err := r.FindMany(pattern)
+	var notFound *NotFoundError
+	if errors.As(err, &notFound) {
+		return &TagStats{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// 指定了版本方案时只统计按该方案命名的发布标签，例如不会把 nightly 当作发布
+	if r.scheme != nil {
+		tags = slices.DeleteFunc(tags, func(tag string) bool { return !r.scheme.Match(tag) })
+	}
+	now := time.Now()
+	stats := &TagStats{Total: len(tags)}
+	for _, tag := range tags {
+		w, ok := seriesWindow(windows, tag)
+		switch {
+		case !ok:
+			stats.Unmanaged++
+		case w.Supported(now):
+			stats.Supported++
+		default:
+			stats.PastEOL = append(stats.PastEOL, tag)
+		}
+	}
+	return stats, nil
+}
+
+// skipSupported 返回 tags 中不属于仍受支持系列的标签，按模式批量删除前调用，受支持系列的标签会被跳过
+func (r *Repository) skipSupported(tags []string) ([]string, error) {
+	windows, err := r.SupportMatrix()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	var rest []string
+	for _, tag := range tags {
+		if w, ok := seriesWindow(windows, tag); ok && w.Supported(now) {
+			continue
+		}
+		rest = append(rest, tag)
+	}
+	return rest, nil
+}
+
+// seriesWindow 返回 tag 所属系列的支持期限，tag 不属于任何配置了 EOL 的系列时 ok 为 false
+func seriesWindow(windows []SupportWindow, tag string) (w SupportWindow, ok bool) {
+	for _, w := range windows {
+		if matched, _ := path.Match(w.Series, tag); matched {
+			return w, true
+		}
+	}
+	return SupportWindow{}, false
+}
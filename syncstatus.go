@@ -0,0 +1,107 @@
+package gittag
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TagSyncStatus 描述匹配同一模式的本地标签与远程标签的差异，名称不含沙箱前缀
+type TagSyncStatus struct {
+	// LocalOnly 是只存在于本地、尚未推送的标签
+	LocalOnly []string `json:"localOnly,omitempty"`
+	// RemoteOnly 是只存在于远程、尚未获取的标签
+	RemoteOnly []string `json:"remoteOnly,omitempty"`
+	// Diverged 是本地和远程同名但指向不同对象的标签，通常是其中一边被移动或重建
+	Diverged []string `json:"diverged,omitempty"`
+}
+
+// InSync 报告本地标签与远程标签是否完全一致
+func (s *TagSyncStatus) InSync() bool {
+	return len(s.LocalOnly) == 0 && len(s.RemoteOnly) == 0 && len(s.Diverged) == 0
+}
+
+// String 返回适合日志和告警的摘要
+func (s *TagSyncStatus) String() string {
+	if s.InSync() {
+		return "本地标签与远程一致"
+	}
+	var parts []string
+	for _, group := range []struct {
+		label string
+		tags  []string
+	}{
+		{"仅本地", s.LocalOnly},
+		{"仅远程", s.RemoteOnly},
+		{"不一致", s.Diverged},
+	} {
+		if len(group.tags) > 0 {
+			parts = append(parts, fmt.Sprintf("%s %d 个: %s", group.label, len(group.tags), strings.Join(group.tags, ", ")))
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+// SyncStatus compares the local tags matching pattern with the tags on the remote, reporting
+// tags that were never pushed, never fetched, or point at different objects on the two sides.
+// @param pattern - 标签匹配模式，语法同 FindMany，例如："v*"
+// @return (*TagSyncStatus, error) - 返回差异，每组按名称排序；读取本地或远程标签失败时返回错误
+//
+// Example:
+//
+//	status, err := gittag.SyncStatus("v*")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	if !status.InSync() {
+//		log.Printf("release tags out of sync: %s", status)
+//	}
+func SyncStatus(pattern string) (*TagSyncStatus, error) {
+	return Default().SyncStatus(pattern)
+}
+
+// SyncStatus compares local and remote tags, see the package-level SyncStatus.
+func (r *Repository) SyncStatus(pattern string) (*TagSyncStatus, error) {
+	normalized, err := normalizePattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+	matcher, err := globRegexp(r.tagName(normalized))
+	if err != nil {
+		return nil, fmt.Errorf("%w %q: %w", ErrInvalidPattern, pattern, err)
+	}
+	ctx := r.baseContext()
+	output, err := r.output(ctx, "tag", "-l", "--format=%(objectname) %(refname:strip=2)", "--", r.tagName(normalized))
+	if err != nil {
+		return nil, fmt.Errorf("读取本地标签失败: %w", err)
+	}
+	remote, err := r.remoteTags(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &TagSyncStatus{}
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		object, name, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		remoteObject, pushed := remote[name]
+		switch {
+		case !pushed:
+			status.LocalOnly = append(status.LocalOnly, r.displayName(name))
+		case remoteObject != object:
+			status.Diverged = append(status.Diverged, r.displayName(name))
+		}
+		delete(remote, name)
+	}
+	for name := range remote {
+		if matcher.MatchString(name) {
+			status.RemoteOnly = append(status.RemoteOnly, r.displayName(name))
+		}
+	}
+	sort.Strings(status.LocalOnly)
+	sort.Strings(status.RemoteOnly)
+	sort.Strings(status.Diverged)
+	return status, nil
+}
@@ -0,0 +1,204 @@
+package gittag
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TagFS returns a read-only fs.FS of the repository content at tag, for tools that inspect release
+// contents in-process without a checkout. The file list is read once with ls-tree; file contents
+// are read from the object database on Open. Symbolic links are exposed as regular files containing
+// the link target with fs.ModeSymlink set, and submodules are omitted. Every entry's ModTime is the
+// tag's commit date.
+// @param tag - 标签名称，例如："v1.2.0"
+// @return (fs.FS, error) - 返回标签内容的只读文件系统，标签不存在时返回 *NotFoundError
+//
+// Example:
+//
+//	fsys, err := gittag.TagFS("v1.2.0")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	data, err := fs.ReadFile(fsys, "deploy/values.yaml")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	matches, _ := fs.Glob(fsys, "migrations/*.sql")
+func TagFS(tag string) (fs.FS, error) {
+	return Default().TagFS(tag)
+}
+
+// TagFS returns the repository's content at tag as an fs.FS, see the package-level TagFS.
+func (r *Repository) TagFS(tag string) (fs.FS, error) {
+	ctx := r.baseContext()
+	commit, err := r.revParse(ctx, tagRef(r.tagName(tag))+"^{commit}")
+	if err != nil {
+		return nil, &NotFoundError{Pattern: tag}
+	}
+	dates, err := r.committerDates([]string{commit})
+	if err != nil {
+		return nil, err
+	}
+	output, err := r.output(ctx, "ls-tree", "-r", "-t", "-z", "--long", "--full-tree", commit)
+	if err != nil {
+		return nil, fmt.Errorf("读取标签 %s 的文件列表失败: %w", tag, err)
+	}
+
+	fsys := &tagFS{repo: r, entries: map[string]*tagEntry{}}
+	root := &tagEntry{name: ".", mode: fs.ModeDir | 0o555, modTime: dates[commit]}
+	fsys.entries["."] = root
+	for _, record := range strings.Split(string(output), "\x00") {
+		// <mode> SP <type> SP <object> SP+ <size> TAB <path>
+		meta, name, ok := strings.Cut(record, "\t")
+		fields := strings.Fields(meta)
+		if !ok || len(fields) != 4 {
+			continue
+		}
+		entry := &tagEntry{name: path.Base(name), object: fields[2], modTime: dates[commit]}
+		switch fields[1] {
+		case "tree":
+			entry.mode = fs.ModeDir | 0o555
+		case "blob":
+			entry.size, _ = strconv.ParseInt(fields[3], 10, 64)
+			entry.mode = 0o444
+			switch fields[0] {
+			case "100755":
+				entry.mode = 0o555
+			case "120000":
+				entry.mode = fs.ModeSymlink | 0o777
+			}
+		default:
+			continue // 子模块
+		}
+		fsys.entries[name] = entry
+		parent := fsys.entries[path.Dir(name)]
+		if parent == nil {
+			continue
+		}
+		parent.children = append(parent.children, entry)
+	}
+	for _, entry := range fsys.entries {
+		sort.Slice(entry.children, func(i, j int) bool {
+			return entry.children[i].name < entry.children[j].name
+		})
+	}
+	return fsys, nil
+}
+
+// tagFS 是 TagFS 返回的文件系统，文件内容按需读取
+type tagFS struct {
+	repo    *Repository
+	entries map[string]*tagEntry
+}
+
+// tagEntry 是文件系统中的一个文件或目录，同时实现 fs.FileInfo 和 fs.DirEntry
+type tagEntry struct {
+	name     string
+	mode     fs.FileMode
+	object   string
+	size     int64
+	modTime  time.Time
+	children []*tagEntry
+}
+
+func (e *tagEntry) Name() string               { return e.name }
+func (e *tagEntry) Size() int64                { return e.size }
+func (e *tagEntry) Mode() fs.FileMode          { return e.mode }
+func (e *tagEntry) ModTime() time.Time         { return e.modTime }
+func (e *tagEntry) IsDir() bool                { return e.mode.IsDir() }
+func (e *tagEntry) Sys() any                   { return nil }
+func (e *tagEntry) Type() fs.FileMode          { return e.mode.Type() }
+func (e *tagEntry) Info() (fs.FileInfo, error) { return e, nil }
+
+func (f *tagFS) Open(name string) (fs.File, error) {
+	entry, err := f.lookup("open", name)
+	if err != nil {
+		return nil, err
+	}
+	if entry.IsDir() {
+		return &tagDir{entry: entry}, nil
+	}
+	data, err := f.repo.output(f.repo.baseContext(), "cat-file", "blob", entry.object)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	return &tagFile{entry: entry, Reader: bytes.NewReader(data)}, nil
+}
+
+// lookup 查找 name 对应的条目，路径无效或不存在时返回 *fs.PathError
+func (f *tagFS) lookup(op, name string) (*tagEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	entry, ok := f.entries[name]
+	if !ok {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	return entry, nil
+}
+
+// Stat 实现 fs.StatFS，不读取文件内容
+func (f *tagFS) Stat(name string) (fs.FileInfo, error) {
+	return f.lookup("stat", name)
+}
+
+// ReadDir 实现 fs.ReadDirFS
+func (f *tagFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	entry, err := f.lookup("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	if !entry.IsDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fmt.Errorf("不是目录")}
+	}
+	list := make([]fs.DirEntry, len(entry.children))
+	for i, child := range entry.children {
+		list[i] = child
+	}
+	return list, nil
+}
+
+// tagFile 是打开的文件，内容已全部读入内存
+type tagFile struct {
+	entry *tagEntry
+	*bytes.Reader
+}
+
+func (f *tagFile) Stat() (fs.FileInfo, error) { return f.entry, nil }
+func (f *tagFile) Close() error               { return nil }
+
+// tagDir 是打开的目录
+type tagDir struct {
+	entry  *tagEntry
+	offset int
+}
+
+func (d *tagDir) Stat() (fs.FileInfo, error) { return d.entry, nil }
+func (d *tagDir) Close() error               { return nil }
+
+func (d *tagDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.entry.name, Err: fmt.Errorf("是目录")}
+}
+
+func (d *tagDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	rest := d.entry.children[d.offset:]
+	if n > 0 && len(rest) == 0 {
+		return nil, io.EOF
+	}
+	if n > 0 && n < len(rest) {
+		rest = rest[:n]
+	}
+	d.offset += len(rest)
+	list := make([]fs.DirEntry, len(rest))
+	for i, child := range rest {
+		list[i] = child
+	}
+	return list, nil
+}
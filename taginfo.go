@@ -0,0 +1,242 @@
+package gittag
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TagInfo 描述一个标签的详细信息
+type TagInfo struct {
+	// Name 是标签名称，例如："v1.0.0"
+	Name string `json:"name"`
+	// Object 是标签引用直接指向的对象 SHA，附注标签为标签对象，轻量标签为提交
+	Object string `json:"object"`
+	// Commit 是标签最终指向的提交 SHA
+	Commit string `json:"commit"`
+	// Annotated 表示是否为附注标签
+	Annotated bool `json:"annotated"`
+	// Message 是附注标签的完整信息（不含签名块），轻量标签为空
+	Message string `json:"message,omitempty"`
+	// TaggerName 和 TaggerEmail 是打标签的人，轻量标签为空
+	TaggerName  string `json:"taggerName,omitempty"`
+	TaggerEmail string `json:"taggerEmail,omitempty"`
+	// Date 是标签的创建时间，轻量标签为提交时间
+	Date time.Time `json:"date"`
+	// TaggerDate 是附注标签的打标签时间，轻量标签为零值
+	TaggerDate time.Time `json:"taggerDate"`
+	// CommitterDate 是标签最终指向的提交的提交时间
+	CommitterDate time.Time `json:"committerDate"`
+	// TargetTag 是嵌套标签所指向的标签名称，例如 "verified/v1.2.3" 指向 "v1.2.3"，普通标签为空
+	TargetTag string `json:"targetTag,omitempty"`
+	// SignatureFormat 是标签签名的格式（"openpgp"、"ssh"、"x509"），未签名时为空，不代表签名有效
+	SignatureFormat string `json:"signatureFormat,omitempty"`
+	// Signature 是签名校验结果，只由 ListVerified 等校验签名的函数填充
+	Signature *Signature `json:"signature,omitempty"`
+}
+
+// tagInfoFields 是解析 TagInfo 所需的字段，以 0x1f 分隔字段、0x1e 分隔记录
+var tagInfoFields = []string{
+	"%(refname:strip=2)",
+	"%(objectname)",
+	"%(objecttype)",
+	"%(*objectname)",
+	"%(contents)",
+	"%(taggername)",
+	"%(taggeremail)",
+	"%(creatordate:iso-strict)",
+	"%(*objecttype)",
+	"%(*tag)",
+	"%(contents:signature)",
+	"%(taggerdate:iso-strict)",
+	"%(committerdate:iso-strict)",
+	"%(*committerdate:iso-strict)",
+}
+
+var tagInfoFormat = strings.Join(tagInfoFields, "%1f") + "%1e"
+
+// ListInfo returns detailed information for all tags matching pattern, sorted by name.
+// Unlike FindMany, an empty result is not an error.
+// @param pattern - The pattern to match tags against, e.g., "v1.*"
+// @return ([]TagInfo, error) - Returns the matching tags and any error that occurred
+//
+// Example:
+//
+//	infos, err := gittag.ListInfo("v*")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	for _, info := range infos {
+//		fmt.Printf("%s -> %s (%s)\n", info.Name, info.Commit[:7], info.Date.Format(time.RFC3339))
+//	}
+func ListInfo(pattern string) ([]TagInfo, error) {
+	return Default().ListInfo(pattern)
+}
+
+// ListInfo returns detailed information for tags in the repository, see the package-level ListInfo.
+// When the repository was opened WithCache, unchanged tags are served from the on-disk cache.
+func (r *Repository) ListInfo(pattern string) ([]TagInfo, error) {
+	normalized, err := normalizePattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+	var infos []TagInfo
+	if r.cache {
+		infos, err = r.cachedListInfo(r.tagName(normalized))
+	} else {
+		infos, err = r.queryTagInfo(r.tagName(normalized))
+	}
+	for i := range infos {
+		infos[i].Name = r.displayName(infos[i].Name)
+	}
+	return infos, err
+}
+
+// queryTagInfo 通过 git tag -l 读取匹配模式的标签信息，可以传入多个模式或精确的标签名
+func (r *Repository) queryTagInfo(patterns ...string) ([]TagInfo, error) {
+	args := append([]string{"tag", "-l", "--format=" + tagInfoFormat, "--"}, patterns...)
+	output, err := r.output(r.baseContext(), args...)
+	if err != nil {
+		return nil, fmt.Errorf("读取标签信息失败: %w", err)
+	}
+	return r.parseTagInfo(string(output))
+}
+
+// parseTagInfo 解析 tagInfoFormat 格式的输出，并将嵌套标签剥离到最终的提交
+func (r *Repository) parseTagInfo(output string) ([]TagInfo, error) {
+	infos, err := parseTagInfoRecords(output)
+	if err != nil {
+		return nil, err
+	}
+	var nested []int
+	var revs []string
+	for i, info := range infos {
+		if info.TargetTag != "" {
+			nested = append(nested, i)
+			revs = append(revs, info.Object+"^{}")
+		}
+	}
+	if len(nested) == 0 {
+		return infos, nil
+	}
+	peeled, err := r.output(r.baseContext(), append([]string{"rev-parse"}, revs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("解析嵌套标签失败: %w", err)
+	}
+	lines := strings.Fields(string(peeled))
+	if len(lines) != len(nested) {
+		return nil, fmt.Errorf("解析嵌套标签失败: 期望 %d 个对象，得到 %d 个", len(nested), len(lines))
+	}
+	for j, i := range nested {
+		infos[i].Commit = lines[j]
+	}
+	dates, err := r.committerDates(lines)
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range nested {
+		infos[i].CommitterDate = dates[infos[i].Commit]
+	}
+	return infos, nil
+}
+
+// committerDates 返回提交的提交时间，键为提交 SHA
+func (r *Repository) committerDates(commits []string) (map[string]time.Time, error) {
+	args := append([]string{"log", "--no-walk", "--format=%H%x1f%cI", "--end-of-options"}, commits...)
+	output, err := r.output(r.baseContext(), args...)
+	if err != nil {
+		return nil, fmt.Errorf("读取提交时间失败: %w", err)
+	}
+	dates := make(map[string]time.Time, len(commits))
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		sha, value, _ := strings.Cut(line, "\x1f")
+		date, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, fmt.Errorf("无法解析提交 %s 的时间: %w", sha, err)
+		}
+		dates[sha] = date
+	}
+	return dates, nil
+}
+
+// parseTagInfoRecords 逐条解析 tagInfoFormat 格式的输出
+func parseTagInfoRecords(output string) ([]TagInfo, error) {
+	var infos []TagInfo
+	for _, record := range strings.Split(output, "\x1e") {
+		record = strings.TrimLeft(record, "\n")
+		if record == "" {
+			continue
+		}
+		fields := strings.Split(record, "\x1f")
+		if len(fields) != len(tagInfoFields) {
+			return nil, fmt.Errorf("无法解析标签信息: %q", record)
+		}
+		info := TagInfo{
+			Name:        fields[0],
+			Object:      fields[1],
+			Commit:      fields[1],
+			Annotated:   fields[2] == "tag",
+			TaggerName:  fields[5],
+			TaggerEmail: strings.Trim(fields[6], "<>"),
+		}
+		if info.Annotated {
+			info.Commit = fields[3]
+			info.Message = strings.TrimSuffix(fields[4], fields[10])
+			info.SignatureFormat = signatureFormat([]byte(fields[10]))
+			if fields[8] == "tag" {
+				info.TargetTag = fields[9]
+			}
+		}
+		committerDate := fields[12]
+		if info.Annotated {
+			committerDate = fields[13]
+		}
+		for _, d := range []struct {
+			dst   *time.Time
+			value string
+		}{{&info.Date, fields[7]}, {&info.TaggerDate, fields[11]}, {&info.CommitterDate, committerDate}} {
+			if d.value == "" {
+				continue
+			}
+			date, err := time.Parse(time.RFC3339, d.value)
+			if err != nil {
+				return nil, fmt.Errorf("无法解析标签 %s 的时间: %w", info.Name, err)
+			}
+			*d.dst = date
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// Lag returns how long after its commit a tag was created — the tagger date minus the committer
+// date — a release-process health metric: a growing lag means releases wait long after the code
+// is ready. Only annotated tags record when they were created.
+// @param tag - 附注标签名称，例如："v1.2.0"
+// @return (time.Duration, error) - 返回提交与打标签之间的间隔，标签不存在或为轻量标签时返回错误
+//
+// Example:
+//
+//	lag, err := gittag.Lag("v1.2.0")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	fmt.Printf("v1.2.0 was tagged %s after its commit\n", lag.Round(time.Minute))
+func Lag(tag string) (time.Duration, error) {
+	return Default().Lag(tag)
+}
+
+// Lag returns the delay between a tag's commit and its creation in the repository, see the package-level Lag.
+func (r *Repository) Lag(tag string) (time.Duration, error) {
+	infos, err := r.queryTagInfo(r.tagName(tag))
+	if err != nil {
+		return 0, err
+	}
+	if len(infos) != 1 || infos[0].Name != r.tagName(tag) {
+		return 0, &NotFoundError{Pattern: tag}
+	}
+	if !infos[0].Annotated {
+		return 0, fmt.Errorf("标签 %s 是轻量标签，没有记录打标签的时间", tag)
+	}
+	return infos[0].TaggerDate.Sub(infos[0].CommitterDate), nil
+}
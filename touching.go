@@ -0,0 +1,77 @@
+package gittag
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// TagsTouching returns the releases whose changes modified path, answering "which release changed
+// this config file" during incident response. The changes of a release are the commits between the
+// next lower version with the same prefix (prereleases included) and the release itself; tags that
+// are not semantic versions are ignored.
+// @param path - 相对仓库根目录的文件或目录，例如："config/prod.yaml"
+// @return ([]TagInfo, error) - 返回按名称排序的标签，没有发布修改过该路径时返回空列表
+//
+// Example:
+//
+//	infos, err := gittag.TagsTouching("config/prod.yaml")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	for _, info := range infos {
+//		fmt.Printf("%s (%s)\n", info.Name, info.Date.Format("2006-01-02"))
+//	}
+func TagsTouching(path string) ([]TagInfo, error) {
+	return Default().TagsTouching(path)
+}
+
+// TagsTouching finds the repository's releases that modified path, see the package-level TagsTouching.
+func (r *Repository) TagsTouching(path string) ([]TagInfo, error) {
+	path = strings.Trim(filepath.ToSlash(path), "/")
+	if path == "" {
+		return nil, fmt.Errorf("路径不能为空")
+	}
+	infos, err := r.ListInfo("*")
+	if err != nil {
+		return nil, err
+	}
+
+	// 按前缀分组并按版本排序，每个版本的变更范围从同一前缀的上一个版本开始
+	type release struct {
+		info    TagInfo
+		version semver
+	}
+	series := map[string][]release{}
+	for _, info := range infos {
+		if prefix, v, ok := splitVersionTag(info.Name); ok {
+			series[prefix] = append(series[prefix], release{info, v})
+		}
+	}
+
+	ctx := r.baseContext()
+	var touching []TagInfo
+	for _, releases := range series {
+		sort.Slice(releases, func(i, j int) bool {
+			return compareSemver(releases[i].version, releases[j].version) < 0
+		})
+		for i, rel := range releases {
+			rev := rel.info.Commit
+			if i > 0 {
+				rev = releases[i-1].info.Commit + ".." + rev
+			}
+			output, err := r.output(ctx, "rev-list", "-n", "1", "--end-of-options", rev, "--", ":(top,literal)"+path)
+			if err != nil {
+				return nil, fmt.Errorf("读取标签 %s 的提交失败: %w", rel.info.Name, err)
+			}
+			if len(strings.TrimSpace(string(output))) > 0 {
+				touching = append(touching, rel.info)
+			}
+		}
+	}
+	sort.Slice(touching, func(i, j int) bool {
+		return touching[i].Name < touching[j].Name
+	})
+	return touching, nil
+}
@@ -0,0 +1,139 @@
+package gittag
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TraceCommand 记录一次外部命令的执行情况，参数已脱敏
+type TraceCommand struct {
+	// Name 是可执行文件名，通常为 "git"
+	Name string `json:"name"`
+	// Args 是传给命令的参数，包含 -c 配置
+	Args     []string      `json:"args"`
+	Dir      string        `json:"dir,omitempty"`
+	Start    time.Time     `json:"start"`
+	Duration time.Duration `json:"duration"`
+	// ExitCode 为 -1 表示命令未能启动或被取消
+	ExitCode int    `json:"exitCode"`
+	Error    string `json:"error,omitempty"`
+	// Transport 是推送使用的传输方式，WithHTTPSFallback 改用 HTTPS 重新推送时为 "https"
+	Transport string `json:"transport,omitempty"`
+}
+
+// String 返回可以直接粘贴到终端复现的命令行
+func (c TraceCommand) String() string {
+	parts := []string{c.Name}
+	for _, arg := range c.Args {
+		parts = append(parts, shellQuote(arg))
+	}
+	line := strings.Join(parts, " ")
+	if c.Dir != "" {
+		line = "cd " + shellQuote(c.Dir) + " && " + line
+	}
+	return line
+}
+
+// Trace 收集仓库执行的所有命令，可以安全地被多个 goroutine 共享
+// 重试的命令会以多条记录出现
+type Trace struct {
+	mu       sync.Mutex
+	commands []TraceCommand
+}
+
+// WithTrace 将该仓库执行的每个命令（参数、耗时、退出码）记录到 t 中
+// 用于分析缓慢的发布流程，或在问题反馈中附上准确的复现步骤
+//
+// Example:
+//
+//	var trace gittag.Trace
+//	repo, _ := gittag.Open(".", gittag.WithTrace(&trace))
+//	err := repo.CreateTag("v1.0.0")
+//	fmt.Printf("took %s\n%s", trace.Duration(), trace.String())
+func WithTrace(t *Trace) Option {
+	return func(r *Repository) {
+		r.trace = t
+	}
+}
+
+// Commands 返回到目前为止记录的命令副本
+func (t *Trace) Commands() []TraceCommand {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]TraceCommand(nil), t.commands...)
+}
+
+// Duration 返回所有命令的累计耗时
+func (t *Trace) Duration() time.Duration {
+	var total time.Duration
+	for _, c := range t.Commands() {
+		total += c.Duration
+	}
+	return total
+}
+
+// Reset 清空已记录的命令，便于按操作分段统计
+func (t *Trace) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.commands = nil
+}
+
+// String 按执行顺序逐行输出命令、耗时和退出码
+func (t *Trace) String() string {
+	var b strings.Builder
+	for _, c := range t.Commands() {
+		if c.Transport != "" {
+			fmt.Fprintf(&b, "%s  # %s, exit %d, via %s\n", c, c.Duration.Round(time.Millisecond), c.ExitCode, c.Transport)
+			continue
+		}
+		fmt.Fprintf(&b, "%s  # %s, exit %d\n", c, c.Duration.Round(time.Millisecond), c.ExitCode)
+	}
+	return b.String()
+}
+
+func (t *Trace) add(c TraceCommand) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.commands = append(t.commands, c)
+}
+
+// record 将已执行的命令写入仓库的 Trace，未启用 WithTrace 时什么也不做
+func (r *Repository) record(cmd *exec.Cmd, start time.Time, err error) {
+	if r.trace == nil {
+		return
+	}
+	c := TraceCommand{
+		Name:      cmd.Args[0],
+		Dir:       cmd.Dir,
+		Start:     start,
+		Duration:  time.Since(start),
+		Transport: r.transport,
+	}
+	for _, arg := range cmd.Args[1:] {
+		c.Args = append(c.Args, Redact(arg))
+	}
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+	case errors.As(err, &exitErr):
+		c.ExitCode = exitErr.ExitCode()
+		c.Error = Redact(err.Error())
+	default:
+		c.ExitCode = -1
+		c.Error = Redact(err.Error())
+	}
+	r.trace.add(c)
+}
+
+// shellQuote 在需要时为参数加上单引号
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_=./:@^~*,+") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,114 @@
+package gittag
+
+import (
+	"bytes"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// WithVerbose streams every command the repository runs to w as it happens: the command line first,
+// then its standard output and standard error line by line while the command is still running, so
+// a stuck push or fetch shows exactly where it is waiting. Command lines and output are redacted,
+// and lines from concurrent commands are never split. Unlike WithTrace, nothing is kept in memory.
+// @param w - 接收命令行和输出的 writer，例如 os.Stderr
+// @return Option - 返回仓库配置项
+//
+// Example:
+//
+//	var opts []gittag.Option
+//	if *verbose {
+//		opts = append(opts, gittag.WithVerbose(os.Stderr))
+//	}
+//	repo, err := gittag.Open(".", opts...)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	err = repo.CreateTag("v1.2.0")
+//	// $ git -c core.quotePath=false ... push --porcelain origin refs/tags/v1.2.0
+//	// To github.com:owner/repo.git
+//	// ...
+func WithVerbose(w io.Writer) Option {
+	return func(r *Repository) {
+		if w == nil {
+			r.verbose = nil
+			return
+		}
+		r.verbose = &verboseWriter{w: w}
+	}
+}
+
+// verboseWriter 串行化多个命令写入同一个 writer 的输出
+type verboseWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (v *verboseWriter) writeLine(line string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	io.WriteString(v.w, Redact(line)+"\n")
+}
+
+// lineWriter 将输出按行转发给 verboseWriter，不完整的行等到换行或 flush 时再输出
+type lineWriter struct {
+	out *verboseWriter
+	buf []byte
+}
+
+func (l *lineWriter) Write(p []byte) (int, error) {
+	l.buf = append(l.buf, p...)
+	for {
+		i := bytes.IndexByte(l.buf, '\n')
+		if i < 0 {
+			break
+		}
+		l.out.writeLine(strings.TrimSuffix(string(l.buf[:i]), "\r"))
+		l.buf = l.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+func (l *lineWriter) flush() {
+	if len(l.buf) > 0 {
+		l.out.writeLine(string(l.buf))
+		l.buf = nil
+	}
+}
+
+// stream 在启用 WithVerbose 时输出命令行，并把 cmd 的标准输出和标准错误同时转发到 verbose writer
+// 必须在设置 cmd.Stdout 和 cmd.Stderr 之后调用，命令结束后调用返回的函数输出最后不完整的一行
+func (r *Repository) stream(cmd *exec.Cmd) func() {
+	if r.verbose == nil {
+		return func() {}
+	}
+	parts := []string{"$"}
+	for _, arg := range cmd.Args {
+		parts = append(parts, shellQuote(arg))
+	}
+	r.verbose.writeLine(strings.Join(parts, " "))
+
+	// 标准输出和标准错误分别按行切分，避免两者的半行拼在一起；共用同一个 writer 时 exec 只使用一个管道
+	stdout := &lineWriter{out: r.verbose}
+	if cmd.Stdout == cmd.Stderr {
+		cmd.Stdout = teeWriter(cmd.Stdout, stdout)
+		cmd.Stderr = cmd.Stdout
+		return stdout.flush
+	}
+	stderr := &lineWriter{out: r.verbose}
+	cmd.Stdout = teeWriter(cmd.Stdout, stdout)
+	cmd.Stderr = teeWriter(cmd.Stderr, stderr)
+	return func() {
+		stdout.flush()
+		stderr.flush()
+	}
+}
+
+// teeWriter 返回同时写入 w 和 lines 的 writer，w 为 nil 时只写入 lines
+func teeWriter(w io.Writer, lines *lineWriter) io.Writer {
+	if w == nil {
+		return lines
+	}
+	return io.MultiWriter(w, lines)
+}
@@ -0,0 +1,229 @@
+package gittag
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Signature 描述标签签名的校验结果
+type Signature struct {
+	// Signed 表示标签是否带有签名
+	Signed bool `json:"signed"`
+	// Valid 表示签名在密码学上是否有效，不代表签名者可信
+	Valid bool `json:"valid"`
+	// Format 是签名格式："openpgp"、"ssh" 或 "x509"
+	Format string `json:"format,omitempty"`
+	// Signer 是签名者，GPG 为用户 ID，SSH 为 allowed_signers 中匹配的 principal
+	Signer string `json:"signer,omitempty"`
+	// Fingerprint 是签名所用密钥的指纹，GPG 为子密钥指纹，SSH 形如 "SHA256:..."
+	Fingerprint string `json:"fingerprint,omitempty"`
+	// PrimaryFingerprint 是 GPG 主密钥指纹，其他格式与 Fingerprint 相同
+	PrimaryFingerprint string `json:"primaryFingerprint,omitempty"`
+}
+
+// TrustPolicy 描述哪些签名者是可信的，满足任意一条即可
+type TrustPolicy struct {
+	// Fingerprints 是可信的密钥指纹，GPG 主密钥或子密钥指纹均可，忽略大小写和空格
+	Fingerprints []string
+	// AllowedSignersFile 是 SSH allowed_signers 文件，签名密钥出现在该文件中即为可信
+	AllowedSignersFile string
+}
+
+// Trusts 判断签名是否有效且满足策略
+func (p TrustPolicy) Trusts(sig *Signature) bool {
+	if sig == nil || !sig.Valid {
+		return false
+	}
+	for _, fingerprint := range p.Fingerprints {
+		want := normalizeFingerprint(fingerprint)
+		if want != "" && (want == normalizeFingerprint(sig.Fingerprint) || want == normalizeFingerprint(sig.PrimaryFingerprint)) {
+			return true
+		}
+	}
+	return p.AllowedSignersFile != "" && sig.Format == "ssh" && sig.Signer != ""
+}
+
+// normalizeFingerprint 去掉空格并统一大小写，SSH 指纹是区分大小写的 base64，因此保持原样
+func normalizeFingerprint(fingerprint string) string {
+	fingerprint = strings.ReplaceAll(fingerprint, " ", "")
+	if strings.HasPrefix(fingerprint, "SHA256:") {
+		return fingerprint
+	}
+	return strings.ToUpper(fingerprint)
+}
+
+// sshGoodSignature 匹配 ssh-keygen 校验成功时的输出
+var sshGoodSignature = regexp.MustCompile(`Good "git" signature(?: for (\S+))? with \S+ key (\S+)`)
+
+// Verify checks the signature of a tag using git verify-tag.
+// An unsigned tag is not an error: the returned Signature has Signed set to false.
+// SSH signatures need gpg.ssh.allowedSignersFile in git config, or use VerifyWithPolicy.
+// @param tagName - 标签名称，例如："v1.0.0"
+// @return (*Signature, error) - 返回签名信息，读取标签失败时返回错误
+//
+// Example:
+//
+//	sig, err := gittag.Verify("v1.0.0")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	if !sig.Valid {
+//		log.Fatalf("v1.0.0 is not validly signed")
+//	}
+func Verify(tagName string) (*Signature, error) {
+	return Default().Verify(tagName)
+}
+
+// Verify checks the signature of a tag in the repository, see the package-level Verify.
+func (r *Repository) Verify(tagName string) (*Signature, error) {
+	return r.verify(tagName, TrustPolicy{})
+}
+
+// VerifyWithPolicy checks the signature of a tag and reports whether it satisfies policy.
+// @param tagName - 标签名称，例如："v1.0.0"
+// @param policy - 信任策略，AllowedSignersFile 同时用于 SSH 签名校验
+// @return (*Signature, bool, error) - 返回签名信息、是否可信以及错误信息
+func VerifyWithPolicy(tagName string, policy TrustPolicy) (*Signature, bool, error) {
+	return Default().VerifyWithPolicy(tagName, policy)
+}
+
+// VerifyWithPolicy checks a tag in the repository against policy, see the package-level VerifyWithPolicy.
+func (r *Repository) VerifyWithPolicy(tagName string, policy TrustPolicy) (*Signature, bool, error) {
+	sig, err := r.verify(tagName, policy)
+	if err != nil {
+		return nil, false, err
+	}
+	return sig, policy.Trusts(sig), nil
+}
+
+func (r *Repository) verify(tagName string, policy TrustPolicy) (*Signature, error) {
+	ctx := r.baseContext()
+	ref := tagRef(r.tagName(tagName))
+	if _, err := r.Resolve(tagName); err != nil {
+		return nil, err
+	}
+	object, err := r.RawTagObject(tagName)
+	if err != nil {
+		// 轻量标签没有标签对象，自然也没有签名
+		return &Signature{}, nil
+	}
+	sig := &Signature{Format: signatureFormat(object)}
+	if sig.Format == "" {
+		return sig, nil
+	}
+	sig.Signed = true
+
+	verifier := r
+	if policy.AllowedSignersFile != "" {
+		copied := *r
+		copied.config = append(slices.Clip(r.config), "gpg.ssh.allowedSignersFile="+policy.AllowedSignersFile)
+		verifier = &copied
+	}
+	stdout, stderr, err := verifier.exec(ctx, "verify-tag", "--raw", ref)
+	// 只有 git 校验失败才表示签名无效，只读、权限或上下文等错误说明校验本身没有进行
+	var gitErr *GitError
+	if err != nil && !errors.As(err, &gitErr) {
+		return nil, fmt.Errorf("校验标签 %s 的签名失败: %w", tagName, err)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("校验标签 %s 的签名失败: %w", tagName, err)
+	}
+	output := string(stdout) + string(stderr)
+	switch sig.Format {
+	case "ssh":
+		if m := sshGoodSignature.FindStringSubmatch(output); m != nil {
+			sig.Valid = err == nil
+			sig.Signer = m[1]
+			sig.Fingerprint = m[2]
+			sig.PrimaryFingerprint = m[2]
+		}
+	default:
+		parseGnupgStatus(output, sig)
+		sig.Valid = sig.Valid && err == nil
+	}
+	return sig, nil
+}
+
+// signatureFormat 根据标签对象中的签名块判断签名格式
+func signatureFormat(object []byte) string {
+	switch {
+	case bytes.Contains(object, []byte("-----BEGIN PGP SIGNATURE-----")):
+		return "openpgp"
+	case bytes.Contains(object, []byte("-----BEGIN SSH SIGNATURE-----")):
+		return "ssh"
+	case bytes.Contains(object, []byte("-----BEGIN SIGNED MESSAGE-----")):
+		return "x509"
+	}
+	return ""
+}
+
+// parseGnupgStatus 解析 gpg/gpgsm 的 --status-fd 输出
+func parseGnupgStatus(output string, sig *Signature) {
+	goodSig := false
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(strings.TrimPrefix(line, "[GNUPG:] "))
+		if !strings.HasPrefix(line, "[GNUPG:] ") || len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "GOODSIG":
+			goodSig = true
+			if len(fields) > 2 {
+				sig.Signer = strings.Join(fields[2:], " ")
+			}
+		case "VALIDSIG":
+			if len(fields) > 1 {
+				sig.Fingerprint = fields[1]
+				sig.PrimaryFingerprint = fields[len(fields)-1]
+			}
+		}
+	}
+	sig.Valid = goodSig && sig.Fingerprint != ""
+}
+
+// ListVerified returns the tags matching pattern that carry a valid signature from a signer
+// trusted by policy — what deployment gates need, rather than raw signature validity.
+// @param pattern - 标签匹配模式，例如："v*"
+// @param policy - 信任策略
+// @return ([]TagInfo, error) - 返回签名可信的标签
+//
+// Example:
+//
+//	policy := gittag.TrustPolicy{AllowedSignersFile: ".github/allowed_signers"}
+//	infos, err := gittag.ListVerified("v*", policy)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+func ListVerified(pattern string, policy TrustPolicy) ([]TagInfo, error) {
+	return Default().ListVerified(pattern, policy)
+}
+
+// ListVerified returns trusted tags in the repository, see the package-level ListVerified.
+func (r *Repository) ListVerified(pattern string, policy TrustPolicy) ([]TagInfo, error) {
+	if len(policy.Fingerprints) == 0 && policy.AllowedSignersFile == "" {
+		return nil, fmt.Errorf("信任策略为空，请设置 Fingerprints 或 AllowedSignersFile")
+	}
+	infos, err := r.ListInfo(pattern)
+	if err != nil {
+		return nil, err
+	}
+	var trusted []TagInfo
+	for _, info := range infos {
+		if !info.Annotated {
+			continue
+		}
+		sig, ok, err := r.VerifyWithPolicy(info.Name, policy)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			info.Signature = sig
+			trusted = append(trusted, info)
+		}
+	}
+	return trusted, nil
+}
@@ -0,0 +1,140 @@
+package gittag
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// VersionFile 描述仓库中一个需要与标签保持一致的版本声明
+type VersionFile struct {
+	// Path 是相对于仓库目录的文件路径
+	Path string
+	// pattern 的第 1 个分组是版本号，nil 表示整个文件内容就是版本号
+	pattern *regexp.Regexp
+}
+
+// PlainVersionFile 表示内容只有版本号的文件，例如 VERSION
+func PlainVersionFile(path string) VersionFile {
+	return VersionFile{Path: path}
+}
+
+// PackageJSONVersion 表示 package.json 中的 "version" 字段，其余格式保持不变
+func PackageJSONVersion(path string) VersionFile {
+	return VersionFile{Path: path, pattern: regexp.MustCompile(`"version"\s*:\s*"([^"]*)"`)}
+}
+
+// GoVersionConst 表示 Go 源文件中的字符串常量或变量，例如 const Version = "1.2.3"
+func GoVersionConst(path, name string) VersionFile {
+	return VersionFile{Path: path, pattern: regexp.MustCompile(`\b` + regexp.QuoteMeta(name) + `\s*(?:string\s*)?=\s*"([^"]*)"`)}
+}
+
+// RegexVersionFile 表示由 pattern 的第 1 个分组定位版本号的任意文件，只替换第一个匹配
+func RegexVersionFile(path string, pattern *regexp.Regexp) VersionFile {
+	return VersionFile{Path: path, pattern: pattern}
+}
+
+// update 返回将版本号替换为 version 后的内容，原版本号以 "v" 开头时保留 "v"
+func (f VersionFile) update(content []byte, version string) ([]byte, error) {
+	withPrefix := func(old string) string {
+		if strings.HasPrefix(old, "v") {
+			return "v" + version
+		}
+		return version
+	}
+	if f.pattern == nil {
+		old := strings.TrimSpace(string(content))
+		return []byte(withPrefix(old) + "\n"), nil
+	}
+	loc := f.pattern.FindSubmatchIndex(content)
+	if loc == nil || len(loc) < 4 || loc[2] < 0 {
+		return nil, fmt.Errorf("在 %s 中找不到版本号", f.Path)
+	}
+	old := string(content[loc[2]:loc[3]])
+	updated := append([]byte{}, content[:loc[2]]...)
+	updated = append(updated, withPrefix(old)...)
+	return append(updated, content[loc[3]:]...), nil
+}
+
+// SyncVersionFiles updates the version declared in each file to match tagName (without its
+// prefix, e.g. "1.2.3" for "v1.2.3") and commits the changed files on the current branch, so the
+// tag can then be created on a commit whose in-repo version agrees with it.
+// @param tagName - 即将创建的标签名称，例如："v1.2.3"
+// @param files - 需要同步的版本文件，例如 PlainVersionFile("VERSION")、PackageJSONVersion("package.json")
+// @return (string, error) - 返回同步后 HEAD 的提交 SHA，文件已是最新时不会产生新提交
+//
+// Example:
+//
+//	commit, err := gittag.SyncVersionFiles("v1.2.3",
+//		gittag.PlainVersionFile("VERSION"),
+//		gittag.PackageJSONVersion("package.json"),
+//		gittag.GoVersionConst("version.go", "Version"),
+//	)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	err = gittag.Create("v1.2.3", gittag.WithTarget(commit), gittag.WithPush())
+func SyncVersionFiles(tagName string, files ...VersionFile) (string, error) {
+	return Default().SyncVersionFiles(tagName, files...)
+}
+
+// SyncVersionFiles updates and commits version files in the repository, see the package-level SyncVersionFiles.
+func (r *Repository) SyncVersionFiles(tagName string, files ...VersionFile) (string, error) {
+	if err := r.checkWritable("修改版本文件"); err != nil {
+		return "", err
+	}
+	ctx := r.baseContext()
+	if err := r.requireAttachedHead(); err != nil {
+		return "", err
+	}
+	version := newMessageData(tagName).Version
+
+	var changed []string
+	for _, f := range files {
+		path := filepath.Join(r.dir, f.Path)
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("读取版本文件失败: %w", err)
+		}
+		updated, err := f.update(content, version)
+		if err != nil {
+			return "", err
+		}
+		if string(updated) == string(content) {
+			continue
+		}
+		if r.skipDryRunStep("write", shellQuote(f.Path)) {
+			changed = append(changed, f.Path)
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return "", fmt.Errorf("读取版本文件失败: %w", err)
+		}
+		if err := os.WriteFile(path, updated, info.Mode().Perm()); err != nil {
+			return "", fmt.Errorf("写入版本文件失败: %w", err)
+		}
+		changed = append(changed, f.Path)
+	}
+
+	if len(changed) > 0 {
+		message, err := r.defaultMessage(tagName)
+		if err != nil {
+			return "", err
+		}
+		if err := r.run(ctx, append([]string{"add", "--"}, changed...)...); err != nil {
+			return "", fmt.Errorf("暂存版本文件失败: %w", err)
+		}
+		args := append([]string{"commit", "--quiet", "--no-verify", "-m", message, "--"}, changed...)
+		if err := r.run(ctx, args...); err != nil {
+			return "", fmt.Errorf("提交版本文件失败: %w", err)
+		}
+	}
+	commit, err := r.revParse(ctx, "HEAD^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("读取 HEAD 失败: %w", err)
+	}
+	return commit, nil
+}
@@ -0,0 +1,198 @@
+package gittag
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// TagEventType 表示标签变化的类型
+type TagEventType string
+
+const (
+	// TagCreated 表示新建了标签
+	TagCreated TagEventType = "created"
+	// TagDeleted 表示删除了标签
+	TagDeleted TagEventType = "deleted"
+	// TagMoved 表示标签被重新指向了其他对象
+	TagMoved TagEventType = "moved"
+)
+
+// TagEvent 描述一次标签变化
+type TagEvent struct {
+	Type TagEventType `json:"type"`
+	Name string       `json:"name"`
+	// Object 是标签当前指向的对象 SHA，删除时为空
+	Object string `json:"object,omitempty"`
+	// OldObject 是标签之前指向的对象 SHA，新建时为空
+	OldObject string `json:"oldObject,omitempty"`
+	// Actor 是创建或移动标签的操作者，只有 WatchLocal 能从 TagActor 的记录中读到时才设置
+	Actor *Actor `json:"actor,omitempty"`
+}
+
+// watchDebounce 合并短时间内的多个文件系统事件，例如 git 写入锁文件再重命名
+const watchDebounce = 50 * time.Millisecond
+
+// WatchLocal watches the local repository for tag changes made by any process.
+// Events are delivered until ctx is cancelled, after which the channel is closed.
+// @param ctx - 用于停止监听的上下文
+// @return (<-chan TagEvent, error) - 返回标签变化事件的通道以及错误信息
+//
+// Example:
+//
+//	events, err := gittag.WatchLocal(ctx)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	for event := range events {
+//		fmt.Printf("%s %s\n", event.Type, event.Name)
+//	}
+func WatchLocal(ctx context.Context) (<-chan TagEvent, error) {
+	return Default().WatchLocal(ctx)
+}
+
+// WatchLocal watches the repository for tag changes, see the package-level WatchLocal.
+func (r *Repository) WatchLocal(ctx context.Context) (<-chan TagEvent, error) {
+	gitDir, err := r.gitCommonDir()
+	if err != nil {
+		return nil, err
+	}
+	previous, err := r.tagRefs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("监听标签失败: %w", err)
+	}
+	tagsDir := filepath.Join(gitDir, "refs", "tags")
+	if err := os.MkdirAll(tagsDir, 0o755); err != nil {
+		watcher.Close()
+		return nil, fmt.Errorf("监听标签失败: %w", err)
+	}
+	// packed-refs 会被整体替换，因此监听 .git 目录本身而不是文件
+	if err := watcher.Add(gitDir); err != nil {
+		watcher.Close()
+		return nil, fmt.Errorf("监听标签失败: %w", err)
+	}
+	if err := watchTree(watcher, tagsDir); err != nil {
+		watcher.Close()
+		return nil, fmt.Errorf("监听标签失败: %w", err)
+	}
+
+	events := make(chan TagEvent)
+	go func() {
+		defer close(events)
+		defer watcher.Close()
+
+		timer := time.NewTimer(watchDebounce)
+		timer.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if !isTagRefEvent(gitDir, tagsDir, event.Name) {
+					continue
+				}
+				if event.Has(fsnotify.Create) {
+					if fi, err := os.Stat(event.Name); err == nil && fi.IsDir() {
+						watchTree(watcher, event.Name)
+					}
+				}
+				timer.Reset(watchDebounce)
+			case _, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+			case <-timer.C:
+				current, err := r.tagRefs(ctx)
+				if err != nil {
+					continue
+				}
+				for _, event := range diffTagRefs(previous, current) {
+					if event.Object != "" {
+						event.Actor, _ = r.objectActor(ctx, event.Object)
+					}
+					select {
+					case events <- event:
+					case <-ctx.Done():
+						return
+					}
+				}
+				previous = current
+			}
+		}
+	}()
+	return events, nil
+}
+
+// watchTree 监听 dir 及其所有子目录，用于 refs/tags/release/v1 这样带层级的标签
+func watchTree(watcher *fsnotify.Watcher, dir string) error {
+	return filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return err
+		}
+		return watcher.Add(path)
+	})
+}
+
+// isTagRefEvent 判断文件系统事件是否可能改变标签
+func isTagRefEvent(gitDir, tagsDir, path string) bool {
+	if path == filepath.Join(gitDir, "packed-refs") {
+		return true
+	}
+	return strings.HasPrefix(path, tagsDir+string(filepath.Separator)) && !strings.HasSuffix(path, ".lock")
+}
+
+// diffTagRefs 比较两次标签快照并按名称顺序返回变化
+func diffTagRefs(previous, current map[string]string) []TagEvent {
+	var events []TagEvent
+	for name, object := range current {
+		switch old, ok := previous[name]; {
+		case !ok:
+			events = append(events, TagEvent{Type: TagCreated, Name: name, Object: object})
+		case old != object:
+			events = append(events, TagEvent{Type: TagMoved, Name: name, Object: object, OldObject: old})
+		}
+	}
+	for name, old := range previous {
+		if _, ok := current[name]; !ok {
+			events = append(events, TagEvent{Type: TagDeleted, Name: name, OldObject: old})
+		}
+	}
+	sortTagEvents(events)
+	return events
+}
+
+// tagRefs 返回所有标签名到其指向对象 SHA 的映射
+func (r *Repository) tagRefs(ctx context.Context) (map[string]string, error) {
+	output, err := r.output(ctx, "for-each-ref", "--format=%(objectname) %(refname:strip=2)", "refs/tags")
+	if err != nil {
+		return nil, fmt.Errorf("读取标签失败: %w", err)
+	}
+	refs := map[string]string{}
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if object, name, ok := strings.Cut(line, " "); ok {
+			refs[name] = object
+		}
+	}
+	return refs, nil
+}
+
+// sortTagEvents 按标签名排序事件，使同一批变化的顺序稳定
+func sortTagEvents(events []TagEvent) {
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Name < events[j].Name
+	})
+}
@@ -0,0 +1,56 @@
+package gittag
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// CheckoutTagSparse adds a detached worktree at dest with only the given directories of tag
+// materialized, for build systems that need a single subdirectory as of a release. Files at the
+// repository root are always checked out, as in git's cone mode. Remove the worktree with
+// `git worktree remove` when done.
+// @param tag - 要检出的标签名称
+// @param paths - 需要检出的目录，例如："cmd/server"
+// @param dest - 工作树目录，不能已存在或必须为空目录
+// @return error - 如果标签不存在或检出失败，返回相应的错误信息
+//
+// Example:
+//
+//	err := gittag.CheckoutTagSparse("v1.2.0", []string{"deploy/helm"}, "/tmp/helm-v1.2.0")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer exec.Command("git", "worktree", "remove", "--force", "/tmp/helm-v1.2.0").Run()
+func CheckoutTagSparse(tag string, paths []string, dest string) error {
+	return Default().CheckoutTagSparse(tag, paths, dest)
+}
+
+// CheckoutTagSparse 在 dest 添加只包含部分目录的标签工作树，参数同包级函数 CheckoutTagSparse
+func (r *Repository) CheckoutTagSparse(tag string, paths []string, dest string) error {
+	if err := r.checkWritable("添加工作树"); err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		return fmt.Errorf("稀疏检出至少需要一个目录")
+	}
+	ctx := r.baseContext()
+	commit, err := r.revParse(ctx, tagRef(r.tagName(tag))+"^{commit}")
+	if err != nil {
+		return &NotFoundError{Pattern: tag}
+	}
+	dest, err = filepath.Abs(dest)
+	if err != nil {
+		return fmt.Errorf("添加工作树失败: %w", err)
+	}
+	if err := r.run(ctx, "worktree", "add", "--quiet", "--no-checkout", "--detach", "--", dest, commit); err != nil {
+		return fmt.Errorf("添加工作树失败: %w", err)
+	}
+
+	worktree := *r
+	worktree.dir = dest
+	if err := worktree.sparseCheckout(ctx, paths, commit); err != nil {
+		r.run(ctx, "worktree", "remove", "--force", "--", dest)
+		return fmt.Errorf("检出标签 %s 失败: %w", tag, err)
+	}
+	return nil
+}